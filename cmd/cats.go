@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+
+	catDomain "go-test-assesment/internal/cat/domain"
+	catRepo "go-test-assesment/internal/cat/repository"
+	catUsecase "go-test-assesment/internal/cat/usecase"
+	"go-test-assesment/pkg/money"
+)
+
+func runCats(args []string) int {
+	return subcommand("cats", args, map[string]func([]string) int{
+		"list":   runCatsList,
+		"create": runCatsCreate,
+		"delete": runCatsDelete,
+	})
+}
+
+// withCats runs fn with a cat usecase backed by the database.
+func withCats(fn func(ctx context.Context, uc *catUsecase.CatUsecase) error) int {
+	limits, err := loadLimits()
+	if err != nil {
+		return fail(fmt.Errorf("invalid limits: %w", err))
+	}
+	pool, err := openDatabase()
+	if err != nil {
+		return fail(err)
+	}
+	defer pool.Close()
+
+	breedValidator := newBreedValidator(limits)
+	uc := catUsecase.NewCatUsecase(catRepo.NewPostgresCatRepository(pool), breedValidator)
+	if err := fn(context.Background(), uc); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+func printCats(p *printer, cats []*catDomain.Cat) error {
+	rows := make([][]string, len(cats))
+	for i, c := range cats {
+		rows[i] = []string{
+			strconv.FormatInt(c.ID, 10), c.Name, strconv.Itoa(c.YearsOfExperience), c.Breed, c.Salary.String(),
+		}
+	}
+	return p.print(cats, []string{"ID", "NAME", "EXPERIENCE", "BREED", "SALARY"}, rows)
+}
+
+func runCatsList(args []string) int {
+	fs := flag.NewFlagSet("cats list", flag.ContinueOnError)
+	output := outputFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	p, err := output()
+	if err != nil {
+		return fail(err)
+	}
+
+	return withCats(func(ctx context.Context, uc *catUsecase.CatUsecase) error {
+		cats, err := uc.List(ctx)
+		if err != nil {
+			return err
+		}
+		return printCats(p, cats)
+	})
+}
+
+func runCatsCreate(args []string) int {
+	fs := flag.NewFlagSet("cats create", flag.ContinueOnError)
+	output := outputFlag(fs)
+	name := fs.String("name", "", "name of the cat")
+	years := fs.Int("years", 0, "years of experience")
+	breed := fs.String("breed", "", "breed, checked against TheCatAPI")
+	salary := fs.String("salary", "", "salary, e.g. 1200.50")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	p, err := output()
+	if err != nil {
+		return fail(err)
+	}
+	amount, err := money.Parse(*salary)
+	if err != nil {
+		return fail(fmt.Errorf("invalid salary %q: %w", *salary, err))
+	}
+
+	return withCats(func(ctx context.Context, uc *catUsecase.CatUsecase) error {
+		c := &catDomain.Cat{Name: *name, YearsOfExperience: *years, Breed: *breed, Salary: amount}
+		if err := uc.Create(ctx, c); err != nil {
+			return err
+		}
+		return printCats(p, []*catDomain.Cat{c})
+	})
+}
+
+func runCatsDelete(args []string) int {
+	fs := flag.NewFlagSet("cats delete", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	ids, err := parseIDs(fs.Args())
+	if err != nil || len(ids) == 0 {
+		return fail(errors.New("usage: cats delete ID..."))
+	}
+
+	return withCats(func(ctx context.Context, uc *catUsecase.CatUsecase) error {
+		for _, id := range ids {
+			if err := uc.Delete(ctx, id); err != nil {
+				return fmt.Errorf("cat %d: %w", id, err)
+			}
+			fmt.Printf("cat %d deleted\n", id)
+		}
+		return nil
+	})
+}
+
+func parseIDs(args []string) ([]int64, error) {
+	ids := make([]int64, len(args))
+	for i, a := range args {
+		id, err := strconv.ParseInt(a, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id %q", a)
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// openDatabase connects to DATABASE_URL for a subcommand.
+func openDatabase() (*pgxpool.Pool, error) {
+	return waitForDatabase(os.Getenv("DATABASE_URL"), 30*time.Second)
+}
+
+// printer writes command results to standard output, either as an aligned
+// table or as indented JSON.
+type printer struct {
+	json bool
+	w    io.Writer
+}
+
+// outputFlag registers the -o flag on fs. The returned function reads it
+// once fs has been parsed.
+func outputFlag(fs *flag.FlagSet) func() (*printer, error) {
+	format := fs.String("o", "table", "output format: table or json")
+	return func() (*printer, error) {
+		switch *format {
+		case "table":
+			return &printer{w: os.Stdout}, nil
+		case "json":
+			return &printer{json: true, w: os.Stdout}, nil
+		default:
+			return nil, fmt.Errorf("unknown output format %q", *format)
+		}
+	}
+}
+
+// print writes v as JSON, or header and rows as a table.
+func (p *printer) print(v any, header []string, rows [][]string) error {
+	if p.json {
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// fail reports err on standard error and returns the exit status for it.
+func fail(err error) int {
+	fmt.Fprintln(os.Stderr, err)
+	return 1
+}
+
+// subcommand runs the entry of subs named by the first argument, for
+// commands such as "cats list".
+func subcommand(name string, args []string, subs map[string]func([]string) int) int {
+	if len(args) > 0 {
+		if run, ok := subs[args[0]]; ok {
+			return run(args[1:])
+		}
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", name+" "+args[0])
+	}
+	names := make([]string, 0, len(subs))
+	for n := range subs {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(os.Stderr, "usage: %s %s [flags]\n", name, strings.Join(names, "|"))
+	return 2
+}
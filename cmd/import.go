@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	importerDomain "go-test-assesment/internal/importer/domain"
+	importerRepo "go-test-assesment/internal/importer/repository"
+	importerUsecase "go-test-assesment/internal/importer/usecase"
+)
+
+// runImport implements "import [flags] FILE", FILE being "-" for standard
+// input. It prints the report as JSON and returns the exit status: 1 when
+// any record was not imported, 2 on bad usage.
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: import -kind cats|missions [flags] FILE")
+		fs.PrintDefaults()
+	}
+	kind := fs.String("kind", "", "what the file holds: cats or missions")
+	format := fs.String("format", "", "csv or jsonl; guessed from the file extension when empty")
+	dryRun := fs.Bool("dry-run", false, "only validate the file")
+	batchSize := fs.Int("batch-size", 100, "records per transaction")
+	concurrency := fs.Int("concurrency", 4, "breed lookups in flight")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	name := fs.Arg(0)
+	var in io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer f.Close()
+		in = f
+	}
+	opts := importerDomain.Options{
+		Kind:        importerDomain.Kind(*kind),
+		Format:      importerDomain.Format(*format),
+		DryRun:      *dryRun,
+		BatchSize:   *batchSize,
+		Concurrency: *concurrency,
+	}
+	if opts.Format == "" {
+		opts.Format = importerDomain.FormatOf(name)
+	}
+
+	limits, err := loadLimits()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid limits: %v\n", err)
+		return 1
+	}
+	keys, err := loadNotesKeys()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "NOTES_KEYFILE: %v\n", err)
+		return 1
+	}
+	pool, err := openDatabase()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "database connection error: %v\n", err)
+		return 1
+	}
+	defer pool.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	uc := importerUsecase.NewImportUsecase(
+		importerRepo.NewImportPostgresWithKeys(pool, keys),
+		newBreedValidator(limits),
+	)
+	report, err := uc.Import(ctx, in, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(report.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -3,27 +3,20 @@ package main
 import (
 	"context"
 	"fmt"
-	_ "go-test-assesment/docs"
-	"go-test-assesment/internal/cat"
-	httpCat "go-test-assesment/internal/cat/delivery/http"
-	catRepo "go-test-assesment/internal/cat/repository"
-	catUsecase "go-test-assesment/internal/cat/usecase"
-	httpMission "go-test-assesment/internal/mission/delivery/http"
-	missionRepo "go-test-assesment/internal/mission/repository"
-	missionUsecase "go-test-assesment/internal/mission/usecase"
-
-	"go-test-assesment/pkg/logger"
 	"log"
-	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/gin-gonic/gin"
+	"go-test-assesment/internal/cat"
+	catDomain "go-test-assesment/internal/cat/domain"
+	"go-test-assesment/pkg/blob"
+	"go-test-assesment/pkg/envelope"
+	"go-test-assesment/pkg/ratelimit"
+
 	"github.com/jackc/pgx/v5/pgxpool"
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
 func waitForDatabase(dsn string, maxWait time.Duration) (*pgxpool.Pool, error) {
@@ -50,51 +43,165 @@ func waitForDatabase(dsn string, maxWait time.Duration) (*pgxpool.Pool, error) {
 	}
 }
 
-func main() {
-	dsn := os.Getenv("DATABASE_URL")
-	fmt.Println(dsn)
+func durationEnv(name string, fallback time.Duration) (time.Duration, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return fallback, nil
+	}
+	return time.ParseDuration(v)
+}
+
+type limitConfig struct {
+	rate          ratelimit.Limit
+	catAPI        ratelimit.Limit
+	maxBody       int64
+	maxAttachment int64
+	apiKeys       map[string]bool
+}
 
-	pool, err := waitForDatabase(dsn, 30*time.Second)
-	if err != nil {
-		log.Fatalf("Database connection error: %v", err)
+// loadLimits reads RATE_LIMIT_RPS and RATE_LIMIT_BURST (per client and
+// route), RATE_LIMIT_API_KEYS (comma-separated keys that identify clients),
+// CATAPI_RPS and CATAPI_BURST (shared by all clients), MAX_BODY_BYTES and
+// ATTACHMENT_MAX_BYTES.
+func loadLimits() (limitConfig, error) {
+	l := limitConfig{
+		rate:          ratelimit.Limit{Rate: 10, Burst: 20},
+		catAPI:        ratelimit.Limit{Rate: 2, Burst: 5},
+		maxBody:       1 << 20,
+		maxAttachment: 10 << 20,
 	}
-	defer pool.Close()
+	for _, f := range []struct {
+		name string
+		set  func(string) error
+	}{
+		{"RATE_LIMIT_RPS", floatSetter(&l.rate.Rate)},
+		{"RATE_LIMIT_BURST", intSetter(&l.rate.Burst)},
+		{"RATE_LIMIT_API_KEYS", func(v string) error {
+			l.apiKeys = make(map[string]bool)
+			for _, key := range strings.Split(v, ",") {
+				if key = strings.TrimSpace(key); key != "" {
+					l.apiKeys[key] = true
+				}
+			}
+			return nil
+		}},
+		{"CATAPI_RPS", floatSetter(&l.catAPI.Rate)},
+		{"CATAPI_BURST", intSetter(&l.catAPI.Burst)},
+		{"MAX_BODY_BYTES", func(v string) (err error) {
+			l.maxBody, err = strconv.ParseInt(v, 10, 64)
+			return err
+		}},
+		{"ATTACHMENT_MAX_BYTES", func(v string) (err error) {
+			l.maxAttachment, err = strconv.ParseInt(v, 10, 64)
+			return err
+		}},
+	} {
+		if v := os.Getenv(f.name); v != "" {
+			if err := f.set(v); err != nil {
+				return l, fmt.Errorf("%s: %w", f.name, err)
+			}
+		}
+	}
+	return l, nil
+}
 
-	r := gin.Default()
-	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-	r.Use(logger.Logger())
+// newBreedValidator checks breeds against TheCatAPI, or against the
+// embedded breed list when BREED_VALIDATOR is "offline".
+func newBreedValidator(limits limitConfig) catDomain.BreedValidator {
+	if os.Getenv("BREED_VALIDATOR") == "offline" {
+		return cat.NewStaticValidator()
+	}
+	return cat.NewRateLimitedValidator(cat.NewCatAPIValidator(), limits.catAPI)
+}
 
-	catRepository := catRepo.NewPostgresCatRepository(pool)
-	breedValidator := cat.NewCatAPIValidator()
-	catUC := catUsecase.NewCatUsecase(catRepository, breedValidator)
-	httpCat.NewCatHandler(r, catUC)
+// newBlobStore keeps attachments below ATTACHMENT_DIR (data/attachments by
+// default), or in the S3_BUCKET bucket at S3_ENDPOINT when ATTACHMENT_STORE
+// is "s3".
+func newBlobStore() (blob.Store, error) {
+	if os.Getenv("ATTACHMENT_STORE") == "s3" {
+		region := os.Getenv("S3_REGION")
+		if region == "" {
+			region = "us-east-1"
+		}
+		return blob.NewS3Store(blob.S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          region,
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		}, nil)
+	}
+	dir := os.Getenv("ATTACHMENT_DIR")
+	if dir == "" {
+		dir = "data/attachments"
+	}
+	return blob.NewDiskStore(dir)
+}
 
-	missionRepository := missionRepo.NewMissionPostgres(pool)
-	missionUC := missionUsecase.NewMissionUsecase(missionRepository)
-	missionHandler := httpMission.NewHandler(missionUC)
-	missionHandler.RegisterRoutes(r)
+// loadNotesKeys reads the keyring that encrypts target notes from the file
+// named by NOTES_KEYFILE. Without it notes are stored in plaintext.
+func loadNotesKeys() (*envelope.Keyring, error) {
+	path := os.Getenv("NOTES_KEYFILE")
+	if path == "" {
+		return nil, nil
+	}
+	return envelope.LoadKeyfile(path)
+}
 
-	srv := &http.Server{
-		Addr:    ":8080",
-		Handler: r,
+func floatSetter(dst *float64) func(string) error {
+	return func(v string) (err error) {
+		*dst, err = strconv.ParseFloat(v, 64)
+		return err
 	}
+}
 
-	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("listen: %s\n", err)
-		}
-	}()
+func intSetter(dst *int) func(string) error {
+	return func(v string) (err error) {
+		*dst, err = strconv.Atoi(v)
+		return err
+	}
+}
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	log.Println("Shutting down server...")
+// commands are the subcommands of the binary. Each gets the arguments that
+// follow its name and returns the exit status.
+var commands = map[string]func(args []string) int{
+	"serve":    runServe,
+	"migrate":  runMigrate,
+	"cats":     runCats,
+	"missions": runMissions,
+	"seed":     runSeed,
+	"purge":    runPurge,
+	"import":   runImport,
+	"notes":    runNotes,
+}
 
-	ctxShutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	if err := srv.Shutdown(ctxShutdown); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	fmt.Fprintf(os.Stderr, "usage: %s [command] [flags]\n\ncommands:\n", os.Args[0])
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s\n", name)
+	}
+	fmt.Fprintln(os.Stderr, "\nWithout a command the server is started.")
+}
 
-	log.Println("Server exiting")
+func main() {
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		usage()
+		return
+	}
+	run, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		usage()
+		os.Exit(2)
+	}
+	os.Exit(run(args))
 }
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"go-test-assesment/db"
+)
+
+// runMigrate applies the embedded schema. It is safe on a database that is
+// already up to date, so it can run on every deploy.
+func runMigrate(args []string) int {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	pool, err := openDatabase()
+	if err != nil {
+		return fail(err)
+	}
+	defer pool.Close()
+
+	// Without arguments the whole script goes out as one simple query, which
+	// Postgres runs in a single implicit transaction.
+	if _, err := pool.Exec(context.Background(), db.Schema); err != nil {
+		return fail(fmt.Errorf("applying schema: %w", err))
+	}
+	fmt.Println("schema is up to date")
+	return 0
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+
+	missionDomain "go-test-assesment/internal/mission/domain"
+	missionRepo "go-test-assesment/internal/mission/repository"
+	missionUsecase "go-test-assesment/internal/mission/usecase"
+)
+
+func runMissions(args []string) int {
+	return subcommand("missions", args, map[string]func([]string) int{
+		"assign":      runMissionsAssign,
+		"auto-assign": runMissionsAutoAssign,
+		"start":       runMissionsTransition(missionDomain.TransitionStart),
+		"complete":    runMissionsTransition(missionDomain.TransitionComplete),
+		"fail":        runMissionsTransition(missionDomain.TransitionFail),
+		"abort":       runMissionsTransition(missionDomain.TransitionAbort),
+	})
+}
+
+// withMissions runs fn with a mission usecase backed by the database. Events
+// are recorded by database triggers, as with the server's default feed.
+func withMissions(fn func(ctx context.Context, uc *missionUsecase.MissionUsecase) error) int {
+	keys, err := loadNotesKeys()
+	if err != nil {
+		return fail(fmt.Errorf("NOTES_KEYFILE: %w", err))
+	}
+	pool, err := openDatabase()
+	if err != nil {
+		return fail(err)
+	}
+	defer pool.Close()
+
+	uc := missionUsecase.NewMissionUsecase(missionRepo.NewMissionPostgresWithKeys(pool, keys), nil)
+	if err := fn(context.Background(), uc); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+func printMission(p *printer, m *missionDomain.Mission) error {
+	catID := "-"
+	if m.CatID != nil {
+		catID = strconv.FormatInt(*m.CatID, 10)
+	}
+	done := 0
+	for _, t := range m.Targets {
+		if t.Completed {
+			done++
+		}
+	}
+	row := []string{
+		strconv.FormatInt(m.ID, 10), catID, string(m.CurrentState()), strconv.FormatBool(m.Completed),
+		fmt.Sprintf("%d/%d", done, len(m.Targets)),
+	}
+	return p.print(m, []string{"ID", "CAT", "STATE", "COMPLETED", "TARGETS DONE"}, [][]string{row})
+}
+
+func runMissionsAssign(args []string) int {
+	fs := flag.NewFlagSet("missions assign", flag.ContinueOnError)
+	output := outputFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	p, err := output()
+	if err != nil {
+		return fail(err)
+	}
+	ids, err := parseIDs(fs.Args())
+	if err != nil || len(ids) != 2 {
+		return fail(errors.New("usage: missions assign MISSION_ID CAT_ID"))
+	}
+
+	return withMissions(func(ctx context.Context, uc *missionUsecase.MissionUsecase) error {
+		if err := uc.AssignCatToMission(ctx, ids[0], ids[1]); err != nil {
+			return err
+		}
+		m, err := uc.GetMissionByID(ctx, ids[0])
+		if err != nil {
+			return err
+		}
+		return printMission(p, m)
+	})
+}
+
+func runMissionsAutoAssign(args []string) int {
+	fs := flag.NewFlagSet("missions auto-assign", flag.ContinueOnError)
+	output := outputFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	p, err := output()
+	if err != nil {
+		return fail(err)
+	}
+	ids, err := parseIDs(fs.Args())
+	if err != nil {
+		return fail(errors.New("usage: missions auto-assign [MISSION_ID...]"))
+	}
+
+	return withMissions(func(ctx context.Context, uc *missionUsecase.MissionUsecase) error {
+		report, err := uc.AutoAssignAll(ctx, ids)
+		if err != nil {
+			return err
+		}
+		rows := make([][]string, 0, len(report.Results))
+		for _, a := range report.Results {
+			cat, reason := "-", a.Error
+			if a.Cat != nil {
+				cat, reason = strconv.FormatInt(a.Cat.ID, 10), a.Explanation
+			}
+			rows = append(rows, []string{strconv.FormatInt(a.MissionID, 10), cat, reason})
+		}
+		return p.print(report, []string{"MISSION", "CAT", "REASON"}, rows)
+	})
+}
+
+// runMissionsTransition returns the subcommand that performs t, with the
+// same checks as the corresponding POST /missions/{id}/... endpoint.
+func runMissionsTransition(t missionDomain.Transition) func([]string) int {
+	return func(args []string) int {
+		fs := flag.NewFlagSet("missions "+string(t), flag.ContinueOnError)
+		output := outputFlag(fs)
+		if err := fs.Parse(args); err != nil {
+			return 2
+		}
+		p, err := output()
+		if err != nil {
+			return fail(err)
+		}
+		ids, err := parseIDs(fs.Args())
+		if err != nil || len(ids) != 1 {
+			return fail(fmt.Errorf("usage: missions %s MISSION_ID", t))
+		}
+
+		return withMissions(func(ctx context.Context, uc *missionUsecase.MissionUsecase) error {
+			m, err := uc.TransitionMission(ctx, ids[0], t)
+			if err != nil {
+				return err
+			}
+			return printMission(p, m)
+		})
+	}
+}
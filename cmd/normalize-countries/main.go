@@ -0,0 +1,70 @@
+// Command normalize-countries is a one-off migration that rewrites free-text
+// target countries such as "USA" or "united states" to ISO 3166-1 alpha-2
+// codes. Values it cannot recognize are listed and left unchanged; once
+// there are none, the targets_country_iso constraint is added. The schema
+// leaves it out until then, so run this after migrating a database with
+// free-text countries.
+//
+// Usage:
+//
+//	DATABASE_URL=postgres://... go run ./cmd/normalize-countries [-dry-run]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	missionRepo "go-test-assesment/internal/mission/repository"
+	"go-test-assesment/pkg/country"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report what would change without writing")
+	flag.Parse()
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, os.Getenv("DATABASE_URL"))
+	if err != nil {
+		log.Fatalf("Database connection error: %v", err)
+	}
+	defer pool.Close()
+	repo := missionRepo.NewMissionPostgres(pool)
+
+	normalize := country.Normalize
+	if *dryRun {
+		// Report matches without changing anything.
+		normalize = func(s string) (string, bool) {
+			code, ok := country.Normalize(s)
+			if ok && code != s {
+				fmt.Printf("%q -> %s\n", s, code)
+			}
+			return s, ok
+		}
+	}
+
+	updated, unmatched, err := repo.NormalizeCountries(ctx, normalize)
+	if err != nil {
+		log.Fatalf("normalizing countries: %v", err)
+	}
+	fmt.Printf("%d targets updated\n", updated)
+	if len(unmatched) > 0 {
+		fmt.Println("unrecognized countries, fix these by hand and run again:")
+		for _, c := range unmatched {
+			fmt.Printf("  %q\n", c)
+		}
+		os.Exit(1)
+	}
+	if *dryRun {
+		return
+	}
+
+	if err := repo.EnforceCountryCodes(ctx); err != nil {
+		log.Fatalf("enforcing country codes: %v", err)
+	}
+	fmt.Println("all target countries are ISO 3166-1 codes")
+}
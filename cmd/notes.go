@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	missionRepo "go-test-assesment/internal/mission/repository"
+	"go-test-assesment/pkg/envelope"
+)
+
+func runNotes(args []string) int {
+	return subcommand("notes", args, map[string]func([]string) int{
+		"generate-key": runNotesGenerateKey,
+		"reencrypt":    runNotesReencrypt,
+	})
+}
+
+// runNotesGenerateKey appends a new key to the notes keyfile, creating it if
+// needed. The new key seals notes once the keyfile is reloaded; the older
+// ones stay to open what they sealed until notes are re-encrypted.
+func runNotesGenerateKey(args []string) int {
+	fs := flag.NewFlagSet("notes generate-key", flag.ContinueOnError)
+	path := fs.String("keyfile", os.Getenv("NOTES_KEYFILE"), "keyfile to append the key to")
+	id := fs.String("id", time.Now().UTC().Format("20060102T150405Z"), "ID of the new key")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *path == "" {
+		return fail(errors.New("usage: notes generate-key -keyfile PATH (or set NOTES_KEYFILE)"))
+	}
+
+	existing, err := os.ReadFile(*path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fail(err)
+	}
+	line, err := envelope.GenerateKey(*id)
+	if err != nil {
+		return fail(err)
+	}
+	if len(existing) > 0 && !bytes.HasSuffix(existing, []byte("\n")) {
+		line = "\n" + line
+	}
+	// Rejects a duplicate ID or a broken keyfile before anything is written.
+	if _, err := envelope.ParseKeyfile(io.MultiReader(bytes.NewReader(existing), strings.NewReader(line))); err != nil {
+		return fail(fmt.Errorf("%s: %w", *path, err))
+	}
+
+	f, err := os.OpenFile(*path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fail(err)
+	}
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		f.Close()
+		return fail(err)
+	}
+	if err := f.Close(); err != nil {
+		return fail(err)
+	}
+	fmt.Printf("added key %s to %s\n", *id, *path)
+	return 0
+}
+
+// runNotesReencrypt seals every stored note with the active key of
+// NOTES_KEYFILE, which completes a key rotation and encrypts notes stored in
+// plaintext.
+func runNotesReencrypt(args []string) int {
+	fs := flag.NewFlagSet("notes reencrypt", flag.ContinueOnError)
+	batchSize := fs.Int("batch-size", 500, "rows per transaction")
+	output := outputFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	p, err := output()
+	if err != nil {
+		return fail(err)
+	}
+	keys, err := loadNotesKeys()
+	if err != nil {
+		return fail(fmt.Errorf("NOTES_KEYFILE: %w", err))
+	}
+	if keys == nil {
+		return fail(errors.New("NOTES_KEYFILE must be set to re-encrypt notes"))
+	}
+
+	pool, err := openDatabase()
+	if err != nil {
+		return fail(err)
+	}
+	defer pool.Close()
+
+	counts, err := missionRepo.NewMissionPostgresWithKeys(pool, keys).ReencryptNotes(context.Background(), *batchSize)
+	if err != nil {
+		return fail(fmt.Errorf("re-encrypting notes: %w", err))
+	}
+	rows := [][]string{
+		{"targets", fmt.Sprint(counts["targets"]), keys.ActiveKey()},
+		{"target_notes", fmt.Sprint(counts["target_notes"]), keys.ActiveKey()},
+	}
+	if err := p.print(counts, []string{"TABLE", "RE-ENCRYPTED", "KEY"}, rows); err != nil {
+		return fail(err)
+	}
+	return 0
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// purgeTables hold the agency's data. Webhook subscriptions are
+// configuration rather than data and survive a purge. Attachment contents
+// are queued for deletion, which the server carries out.
+var purgeTables = []string{
+	"cats", "salary_changes", "missions", "targets", "target_notes", "target_attachments",
+	"events", "webhook_deliveries", "idempotency_keys",
+}
+
+// runPurge deletes all data and resets the ID sequences. Without -yes it
+// only reports how many rows each table holds.
+func runPurge(args []string) int {
+	fs := flag.NewFlagSet("purge", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "really delete everything")
+	output := outputFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	p, err := output()
+	if err != nil {
+		return fail(err)
+	}
+
+	pool, err := openDatabase()
+	if err != nil {
+		return fail(err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	counts := make(map[string]int64, len(purgeTables))
+	err = pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		// Lock first so that the counts are what gets deleted.
+		if *yes {
+			if _, err := tx.Exec(ctx, "LOCK TABLE "+strings.Join(purgeTables, ", ")); err != nil {
+				return err
+			}
+		}
+		for _, table := range purgeTables {
+			var n int64
+			if err := tx.QueryRow(ctx, "SELECT count(*) FROM "+table).Scan(&n); err != nil {
+				return err
+			}
+			counts[table] = n
+		}
+		if !*yes {
+			return nil
+		}
+		// TRUNCATE skips the trigger that queues the contents of deleted
+		// attachments.
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO attachment_blob_deletions (storage_key)
+			SELECT storage_key FROM target_attachments
+			ON CONFLICT DO NOTHING`); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, "TRUNCATE "+strings.Join(purgeTables, ", ")+" RESTART IDENTITY")
+		return err
+	})
+	if err != nil {
+		return fail(fmt.Errorf("purging: %w", err))
+	}
+
+	rows := make([][]string, len(purgeTables))
+	for i, table := range purgeTables {
+		rows[i] = []string{table, fmt.Sprint(counts[table])}
+	}
+	header := []string{"TABLE", "ROWS"}
+	if *yes {
+		header[1] = "DELETED"
+	}
+	if err := p.print(counts, header, rows); err != nil {
+		return fail(err)
+	}
+	if !*yes {
+		fmt.Fprintln(fs.Output(), "nothing deleted; run again with -yes to delete these rows")
+	}
+	return 0
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	importerRepo "go-test-assesment/internal/importer/repository"
+	"go-test-assesment/internal/seed"
+)
+
+type seedSummary struct {
+	Seed     uint64 `json:"seed"`
+	Cats     int    `json:"cats"`
+	Missions int    `json:"missions"`
+	Targets  int    `json:"targets"`
+}
+
+// runSeed adds generated sample data. Running it twice with the same flags
+// adds the same data twice; purge first for a clean set.
+func runSeed(args []string) int {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	output := outputFlag(fs)
+	var cfg seed.Config
+	fs.Uint64Var(&cfg.Seed, "seed", 1, "selects the generated data set")
+	fs.IntVar(&cfg.Cats, "cats", seed.DefaultCats, "number of cats")
+	fs.IntVar(&cfg.Missions, "missions", seed.DefaultMissions, "number of missions")
+	batchSize := fs.Int("batch-size", 100, "records per transaction")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	p, err := output()
+	if err != nil {
+		return fail(err)
+	}
+
+	keys, err := loadNotesKeys()
+	if err != nil {
+		return fail(fmt.Errorf("NOTES_KEYFILE: %w", err))
+	}
+	pool, err := openDatabase()
+	if err != nil {
+		return fail(err)
+	}
+	defer pool.Close()
+
+	ds := seed.Generate(cfg)
+	if err := seed.Load(context.Background(), importerRepo.NewImportPostgresWithKeys(pool, keys), ds, *batchSize); err != nil {
+		return fail(err)
+	}
+
+	summary := seedSummary{Seed: cfg.Seed, Cats: len(ds.Cats), Missions: len(ds.Missions)}
+	for _, m := range ds.Missions {
+		summary.Targets += len(m.Targets)
+	}
+	row := []string{fmt.Sprint(summary.Seed), fmt.Sprint(summary.Cats), fmt.Sprint(summary.Missions), fmt.Sprint(summary.Targets)}
+	if err := p.print(summary, []string{"SEED", "CATS", "MISSIONS", "TARGETS"}, [][]string{row}); err != nil {
+		return fail(err)
+	}
+	return 0
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	_ "go-test-assesment/docs"
+	httpCat "go-test-assesment/internal/cat/delivery/http"
+	catRepo "go-test-assesment/internal/cat/repository"
+	catUsecase "go-test-assesment/internal/cat/usecase"
+	eventBus "go-test-assesment/internal/event/bus"
+	httpEvent "go-test-assesment/internal/event/delivery/http"
+	eventDomain "go-test-assesment/internal/event/domain"
+	eventListener "go-test-assesment/internal/event/listener"
+	eventRepo "go-test-assesment/internal/event/repository"
+	httpImporter "go-test-assesment/internal/importer/delivery/http"
+	importerRepo "go-test-assesment/internal/importer/repository"
+	importerUsecase "go-test-assesment/internal/importer/usecase"
+	httpMission "go-test-assesment/internal/mission/delivery/http"
+	missionRepo "go-test-assesment/internal/mission/repository"
+	missionScanner "go-test-assesment/internal/mission/scanner"
+	missionUsecase "go-test-assesment/internal/mission/usecase"
+	httpStats "go-test-assesment/internal/stats/delivery/http"
+	statsRepo "go-test-assesment/internal/stats/repository"
+	statsUsecase "go-test-assesment/internal/stats/usecase"
+	httpWebhook "go-test-assesment/internal/webhook/delivery/http"
+	webhookDispatcher "go-test-assesment/internal/webhook/dispatcher"
+	webhookRepo "go-test-assesment/internal/webhook/repository"
+	webhookUsecase "go-test-assesment/internal/webhook/usecase"
+
+	"go-test-assesment/pkg/bodylimit"
+	"go-test-assesment/pkg/idempotency"
+	"go-test-assesment/pkg/logger"
+	"go-test-assesment/pkg/ratelimit"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+)
+
+// runServe starts the HTTP server and its background workers and blocks
+// until SIGINT or SIGTERM.
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	dsn := os.Getenv("DATABASE_URL")
+	fmt.Println(dsn)
+
+	pool, err := waitForDatabase(dsn, 30*time.Second)
+	if err != nil {
+		log.Fatalf("Database connection error: %v", err)
+	}
+	defer pool.Close()
+
+	r := gin.Default()
+	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	r.Use(logger.Logger())
+
+	limits, err := loadLimits()
+	if err != nil {
+		log.Fatalf("Invalid limits: %v", err)
+	}
+	r.Use(ratelimit.Middleware(ratelimit.Config{
+		Default: limits.rate,
+		Routes: map[string]ratelimit.Limit{
+			// Each new cat costs an outbound breed lookup.
+			"POST /cats": {Rate: 1, Burst: 5},
+		},
+		ValidKey: func(key string) bool { return limits.apiKeys[key] },
+	}))
+	r.Use(bodylimit.Middleware(limits.maxBody, map[string]int64{
+		"POST /missions/:id/targets/csv": 10 << 20,
+		"POST /import":                   10 << 20,
+		// Leaves room for the multipart framing around the file.
+		"POST /targets/:id/attachments": limits.maxAttachment + 64<<10,
+	}))
+
+	idempotencyTTL, err := durationEnv("IDEMPOTENCY_TTL", 24*time.Hour)
+	if err != nil {
+		log.Fatalf("IDEMPOTENCY_TTL: %v", err)
+	}
+	idempotencyStore := idempotency.NewPostgresStore(pool)
+	r.Use(idempotency.Middleware(idempotencyStore, idempotencyTTL))
+
+	catRepository := catRepo.NewPostgresCatRepository(pool)
+	breedValidator := newBreedValidator(limits)
+	catUC := catUsecase.NewCatUsecase(catRepository, breedValidator)
+	httpCat.NewCatHandler(r, catUC)
+
+	// Background workers and long-lived streams such as /events are tied to
+	// this context so that Shutdown does not wait for them until its deadline.
+	appCtx, cancelApp := context.WithCancel(context.Background())
+	defer cancelApp()
+
+	events := eventBus.NewBus(1000)
+	httpEvent.NewHandler(events).RegisterRoutes(r)
+
+	// By default mission events are recorded by database triggers and relayed
+	// through LISTEN/NOTIFY so that every replica sees them. EVENT_FEED=memory
+	// publishes them in-process instead, which only suits a single replica.
+	// The triggers fill the events table either way, so it is always pruned.
+	eventRepository := eventRepo.NewEventPostgres(pool)
+	go eventListener.Prune(appCtx, eventRepository)
+	var missionEvents eventDomain.Publisher
+	if os.Getenv("EVENT_FEED") == "memory" {
+		missionEvents = events
+	} else {
+		feed := eventListener.NewListener(
+			eventListener.PostgresDialer(dsn),
+			eventRepository,
+			events,
+		)
+		go func() {
+			if err := feed.Run(appCtx); err != nil {
+				log.Fatalf("event listener: %v", err)
+			}
+		}()
+	}
+
+	notesKeys, err := loadNotesKeys()
+	if err != nil {
+		log.Fatalf("NOTES_KEYFILE: %v", err)
+	}
+	if notesKeys != nil {
+		log.Printf("Target notes are encrypted with key %s; full-text search over notes is disabled", notesKeys.ActiveKey())
+	}
+	missionRepository := missionRepo.NewMissionPostgresWithKeys(pool, notesKeys)
+	missionUC := missionUsecase.NewMissionUsecase(missionRepository, missionEvents)
+	missionHandler := httpMission.NewHandler(missionUC)
+	if os.Getenv("REDACT_LIST_NOTES") == "true" {
+		missionHandler.RedactListNotes()
+	}
+	missionHandler.RegisterRoutes(r)
+
+	blobStore, err := newBlobStore()
+	if err != nil {
+		log.Fatalf("Attachment store: %v", err)
+	}
+	attachmentUC := missionUsecase.NewAttachmentUsecase(missionRepository, missionRepository, blobStore, limits.maxAttachment)
+	httpMission.NewAttachmentHandler(attachmentUC).RegisterRoutes(r)
+	go attachmentUC.SweepBlobs(appCtx, time.Minute)
+
+	overdueInterval, err := durationEnv("OVERDUE_SCAN_INTERVAL", time.Minute)
+	if err != nil {
+		log.Fatalf("OVERDUE_SCAN_INTERVAL: %v", err)
+	}
+	go missionScanner.NewScanner(missionRepository, missionEvents, overdueInterval).Run(appCtx)
+
+	importUC := importerUsecase.NewImportUsecase(importerRepo.NewImportPostgresWithKeys(pool, notesKeys), breedValidator)
+	httpImporter.NewHandler(importUC).RegisterRoutes(r)
+
+	httpStats.NewHandler(statsUsecase.NewStatsUsecase(statsRepo.NewReportingPostgres(pool))).RegisterRoutes(r)
+
+	// Webhook deliveries are queued by a trigger on the events table, so the
+	// dispatcher only has to drain the outbox.
+	webhookRepository := webhookRepo.NewWebhookPostgres(pool)
+	httpWebhook.NewHandler(webhookUsecase.NewWebhookUsecase(webhookRepository)).RegisterRoutes(r)
+	go webhookDispatcher.NewDispatcher(webhookRepository, nil).Run(appCtx)
+
+	go idempotency.Cleanup(appCtx, idempotencyStore, time.Hour)
+
+	srv := &http.Server{
+		Addr:        ":8080",
+		Handler:     r,
+		BaseContext: func(net.Listener) context.Context { return appCtx },
+	}
+	srv.RegisterOnShutdown(cancelApp)
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("listen: %s\n", err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Println("Shutting down server...")
+
+	ctxShutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctxShutdown); err != nil {
+		log.Fatalf("Server forced to shutdown: %v", err)
+	}
+
+	log.Println("Server exiting")
+	return 0
+}
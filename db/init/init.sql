@@ -24,4 +24,441 @@ CREATE TABLE IF NOT EXISTS targets (
     created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
     UNIQUE(mission_id, name)
-);
\ No newline at end of file
+);
+
+-- Change feed: mission and target changes are recorded in the same
+-- transaction as the mutation and announced to listeners via NOTIFY.
+CREATE TABLE IF NOT EXISTS events (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(64) NOT NULL,
+    mission_id BIGINT NOT NULL,
+    cat_id BIGINT NULL,
+    target_id BIGINT NULL,
+    data JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS events_created_at_idx ON events (created_at);
+
+CREATE OR REPLACE FUNCTION notify_event() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('mission_events', NEW.id::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER events_notify
+    AFTER INSERT ON events
+    FOR EACH ROW EXECUTE FUNCTION notify_event();
+
+CREATE OR REPLACE FUNCTION record_mission_event() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO events (type, mission_id, cat_id, data)
+        VALUES ('mission.created', NEW.id, NEW.cat_id, to_jsonb(NEW));
+        RETURN NEW;
+    END IF;
+
+    IF NEW.cat_id IS NOT NULL AND NEW.cat_id IS DISTINCT FROM OLD.cat_id THEN
+        INSERT INTO events (type, mission_id, cat_id, data)
+        VALUES ('mission.assigned', NEW.id, NEW.cat_id, to_jsonb(NEW));
+    END IF;
+    IF NEW.completed AND NOT OLD.completed THEN
+        INSERT INTO events (type, mission_id, cat_id, data)
+        VALUES ('mission.completed', NEW.id, NEW.cat_id, to_jsonb(NEW));
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER missions_record_event
+    AFTER INSERT OR UPDATE ON missions
+    FOR EACH ROW EXECUTE FUNCTION record_mission_event();
+
+CREATE OR REPLACE FUNCTION record_target_event() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO events (type, mission_id, cat_id, target_id, data)
+    SELECT 'target.updated', NEW.mission_id, m.cat_id, NEW.id, to_jsonb(NEW)
+    FROM missions m WHERE m.id = NEW.mission_id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER targets_record_event
+    AFTER UPDATE ON targets
+    FOR EACH ROW EXECUTE FUNCTION record_target_event();
+
+
+-- Webhooks: every recorded event is fanned out to matching subscriptions in
+-- the same transaction, forming an outbox drained by the dispatcher.
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id SERIAL PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    event_types TEXT[] NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id BIGINT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id BIGINT NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    delivered_at TIMESTAMP WITH TIME ZONE NULL
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx
+    ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_status_idx
+    ON webhook_deliveries (status, created_at);
+
+CREATE OR REPLACE FUNCTION enqueue_webhook_deliveries() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+    SELECT s.id, NEW.id, NEW.type, to_jsonb(NEW)
+    FROM webhook_subscriptions s
+    WHERE s.active AND NEW.type = ANY(s.event_types);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER events_enqueue_webhooks
+    AFTER INSERT ON events
+    FOR EACH ROW EXECUTE FUNCTION enqueue_webhook_deliveries();
+
+
+-- Idempotency keys for retried POST requests; see pkg/idempotency.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key VARCHAR(255) PRIMARY KEY,
+    fingerprint CHAR(64) NOT NULL,
+    status_code INT NULL,
+    content_type TEXT NULL,
+    body BYTEA NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_idx ON idempotency_keys (expires_at);
+
+
+-- Salary ledger. cats.salary always holds the latest entry's new_salary.
+CREATE TABLE IF NOT EXISTS salary_changes (
+    id BIGSERIAL PRIMARY KEY,
+    cat_id BIGINT NOT NULL REFERENCES cats(id) ON DELETE CASCADE,
+    old_salary NUMERIC(10, 2) NULL,
+    new_salary NUMERIC(10, 2) NOT NULL,
+    effective_from DATE NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS salary_changes_cat_idx ON salary_changes (cat_id, effective_from);
+
+-- Deleting a cat only marks it, so that its ledger stays as an audit trail;
+-- the foreign key refuses to delete a cat row that still has one.
+ALTER TABLE cats ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE NULL;
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_constraint
+               WHERE conname = 'salary_changes_cat_id_fkey' AND confdeltype <> 'r') THEN
+        ALTER TABLE salary_changes DROP CONSTRAINT salary_changes_cat_id_fkey,
+            ADD CONSTRAINT salary_changes_cat_id_fkey
+            FOREIGN KEY (cat_id) REFERENCES cats(id) ON DELETE RESTRICT;
+    END IF;
+END $$;
+
+-- Cats created before the ledger existed start it with their current salary.
+INSERT INTO salary_changes (cat_id, old_salary, new_salary, effective_from, reason)
+SELECT c.id, NULL, c.salary, CURRENT_DATE, 'initial salary'
+FROM cats c
+WHERE NOT EXISTS (SELECT 1 FROM salary_changes sc WHERE sc.cat_id = c.id);
+
+
+-- Cat search: words in name and breed for full-text matching, and trigrams
+-- for partial and misspelt input.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE cats ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', name), 'A') || setweight(to_tsvector('simple', breed), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS cats_search_vector_idx ON cats USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS cats_name_trgm_idx ON cats USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS cats_breed_trgm_idx ON cats USING GIN (breed gin_trgm_ops);
+
+
+-- Target search across missions. The notes are indexed for full-text
+-- search with notes encryption below.
+CREATE INDEX IF NOT EXISTS targets_country_code_idx ON targets (country);
+CREATE INDEX IF NOT EXISTS missions_cat_id_idx ON missions (cat_id);
+
+
+-- Target countries are ISO 3166-1 alpha-2 codes. The check is only added
+-- once every row passes it, since even a NOT VALID check rejects any update
+-- of an older free-text row. Databases with such rows must run the
+-- normalize-countries command, which rewrites them and then adds the check;
+-- until then a NOT VALID check left by an earlier version is dropped.
+DROP INDEX IF EXISTS targets_country_idx;
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM targets WHERE country !~ '^[A-Z]{2}$') THEN
+        RAISE NOTICE 'targets have free-text countries; run normalize-countries to add targets_country_iso';
+        ALTER TABLE targets DROP CONSTRAINT IF EXISTS targets_country_iso;
+    ELSIF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'targets_country_iso') THEN
+        ALTER TABLE targets ADD CONSTRAINT targets_country_iso CHECK (country ~ '^[A-Z]{2}$');
+    ELSE
+        ALTER TABLE targets VALIDATE CONSTRAINT targets_country_iso;
+    END IF;
+END $$;
+
+-- Country rewrites by that command are not target updates to report.
+CREATE OR REPLACE TRIGGER targets_record_event
+    AFTER UPDATE ON targets
+    FOR EACH ROW WHEN (OLD.country = NEW.country)
+    EXECUTE FUNCTION record_target_event();
+
+
+-- Deadlines. overdue_notified_at is set by the overdue scanner when it
+-- records the overdue event and cleared when the deadline changes.
+ALTER TABLE missions ADD COLUMN IF NOT EXISTS deadline TIMESTAMP WITH TIME ZONE NULL;
+ALTER TABLE missions ADD COLUMN IF NOT EXISTS overdue_notified_at TIMESTAMP WITH TIME ZONE NULL;
+ALTER TABLE targets ADD COLUMN IF NOT EXISTS deadline TIMESTAMP WITH TIME ZONE NULL;
+ALTER TABLE targets ADD COLUMN IF NOT EXISTS overdue_notified_at TIMESTAMP WITH TIME ZONE NULL;
+
+CREATE INDEX IF NOT EXISTS missions_open_deadline_idx ON missions (deadline) WHERE NOT completed;
+CREATE INDEX IF NOT EXISTS targets_open_deadline_idx ON targets (deadline) WHERE NOT completed;
+
+-- Only updates made through the API, which always set updated_at, are
+-- reported. Maintenance writes such as country rewrites and the scanner's
+-- bookkeeping leave it alone.
+CREATE OR REPLACE TRIGGER targets_record_event
+    AFTER UPDATE ON targets
+    FOR EACH ROW WHEN (OLD.updated_at IS DISTINCT FROM NEW.updated_at)
+    EXECUTE FUNCTION record_target_event();
+
+
+-- Auto-assignment requirements. Cats on an uncompleted mission are not
+-- available, which the partial index on cat_id looks up.
+ALTER TABLE missions ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE missions ADD COLUMN IF NOT EXISTS required_experience INT NOT NULL DEFAULT 0;
+ALTER TABLE missions ADD COLUMN IF NOT EXISTS preferred_breeds TEXT[] NOT NULL DEFAULT '{}';
+
+DO $$
+BEGIN
+    ALTER TABLE missions ADD CONSTRAINT missions_priority_check CHECK (priority BETWEEN 0 AND 10);
+EXCEPTION WHEN duplicate_object THEN
+    NULL;
+END $$;
+
+DO $$
+BEGIN
+    ALTER TABLE missions ADD CONSTRAINT missions_required_experience_check CHECK (required_experience >= 0);
+EXCEPTION WHEN duplicate_object THEN
+    NULL;
+END $$;
+
+CREATE INDEX IF NOT EXISTS missions_active_cat_idx ON missions (cat_id) WHERE NOT completed;
+
+
+-- Mission lifecycle. completed is kept in step with state for existing
+-- readers; missions from before states existed are backfilled from it.
+ALTER TABLE missions ADD COLUMN IF NOT EXISTS state VARCHAR(16) NOT NULL DEFAULT 'draft';
+ALTER TABLE missions ADD COLUMN IF NOT EXISTS assigned_at TIMESTAMP WITH TIME ZONE NULL;
+ALTER TABLE missions ADD COLUMN IF NOT EXISTS started_at TIMESTAMP WITH TIME ZONE NULL;
+ALTER TABLE missions ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP WITH TIME ZONE NULL;
+ALTER TABLE missions ADD COLUMN IF NOT EXISTS aborted_at TIMESTAMP WITH TIME ZONE NULL;
+ALTER TABLE missions ADD COLUMN IF NOT EXISTS failed_at TIMESTAMP WITH TIME ZONE NULL;
+
+UPDATE missions
+SET state = CASE WHEN completed THEN 'completed' ELSE 'assigned' END,
+    assigned_at = CASE WHEN cat_id IS NOT NULL THEN created_at END,
+    completed_at = CASE WHEN completed THEN updated_at END
+WHERE state = 'draft' AND (completed OR cat_id IS NOT NULL);
+
+DO $$
+BEGIN
+    ALTER TABLE missions ADD CONSTRAINT missions_state_check
+        CHECK (state IN ('draft', 'assigned', 'in_progress', 'completed', 'aborted', 'failed'));
+EXCEPTION WHEN duplicate_object THEN
+    NULL;
+END $$;
+
+DO $$
+BEGIN
+    ALTER TABLE missions ADD CONSTRAINT missions_state_completed_check
+        CHECK (completed = (state = 'completed'));
+EXCEPTION WHEN duplicate_object THEN
+    NULL;
+END $$;
+
+-- Aborted and failed missions free their cat and stop becoming overdue.
+DROP INDEX IF EXISTS missions_active_cat_idx;
+DROP INDEX IF EXISTS missions_open_deadline_idx;
+CREATE INDEX IF NOT EXISTS missions_open_cat_idx
+    ON missions (cat_id) WHERE state IN ('draft', 'assigned', 'in_progress');
+CREATE INDEX IF NOT EXISTS missions_open_deadline_state_idx
+    ON missions (deadline) WHERE state IN ('draft', 'assigned', 'in_progress');
+CREATE INDEX IF NOT EXISTS missions_state_idx ON missions (state);
+
+CREATE OR REPLACE FUNCTION record_mission_event() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO events (type, mission_id, cat_id, data)
+        VALUES ('mission.created', NEW.id, NEW.cat_id, to_jsonb(NEW));
+        RETURN NEW;
+    END IF;
+
+    IF NEW.cat_id IS NOT NULL AND NEW.cat_id IS DISTINCT FROM OLD.cat_id THEN
+        INSERT INTO events (type, mission_id, cat_id, data)
+        VALUES ('mission.assigned', NEW.id, NEW.cat_id, to_jsonb(NEW));
+    END IF;
+    IF NEW.completed AND NOT OLD.completed THEN
+        INSERT INTO events (type, mission_id, cat_id, data)
+        VALUES ('mission.completed', NEW.id, NEW.cat_id, to_jsonb(NEW));
+    END IF;
+    IF NEW.state IS DISTINCT FROM OLD.state AND NEW.state IN ('in_progress', 'aborted', 'failed') THEN
+        INSERT INTO events (type, mission_id, cat_id, data)
+        VALUES (CASE NEW.state
+                    WHEN 'in_progress' THEN 'mission.started'
+                    WHEN 'aborted' THEN 'mission.aborted'
+                    ELSE 'mission.failed'
+                END, NEW.id, NEW.cat_id, to_jsonb(NEW));
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+
+-- Target statuses. completed is kept in step with the terminal statuses
+-- (eliminated, escaped); targets completed before statuses existed were
+-- eliminated.
+ALTER TABLE targets ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'pending';
+ALTER TABLE targets ADD COLUMN IF NOT EXISTS outcome TEXT NOT NULL DEFAULT '';
+ALTER TABLE targets ADD COLUMN IF NOT EXISTS compromised_at TIMESTAMP WITH TIME ZONE NULL;
+ALTER TABLE targets ADD COLUMN IF NOT EXISTS eliminated_at TIMESTAMP WITH TIME ZONE NULL;
+ALTER TABLE targets ADD COLUMN IF NOT EXISTS escaped_at TIMESTAMP WITH TIME ZONE NULL;
+
+UPDATE targets SET status = 'eliminated', eliminated_at = updated_at
+WHERE completed AND status = 'pending';
+
+DO $$
+BEGIN
+    ALTER TABLE targets ADD CONSTRAINT targets_status_check
+        CHECK (status IN ('pending', 'compromised', 'eliminated', 'escaped'));
+EXCEPTION WHEN duplicate_object THEN
+    NULL;
+END $$;
+
+DO $$
+BEGIN
+    ALTER TABLE targets ADD CONSTRAINT targets_status_completed_check
+        CHECK (completed = (status IN ('eliminated', 'escaped')));
+EXCEPTION WHEN duplicate_object THEN
+    NULL;
+END $$;
+
+CREATE INDEX IF NOT EXISTS targets_mission_status_idx ON targets (mission_id, status);
+
+
+-- Target notes journal. Entries are only appended; targets.notes mirrors the
+-- latest one. Entries without an author were written through targets.notes,
+-- and notes predating the journal become its first entry.
+CREATE TABLE IF NOT EXISTS target_notes (
+    id BIGSERIAL PRIMARY KEY,
+    target_id BIGINT NOT NULL REFERENCES targets(id) ON DELETE CASCADE,
+    author VARCHAR(100) NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS target_notes_target_created_idx ON target_notes (target_id, created_at, id);
+
+INSERT INTO target_notes (target_id, author, body, created_at)
+SELECT t.id, '', t.notes, t.updated_at
+FROM targets t
+WHERE t.notes <> ''
+  AND NOT EXISTS (SELECT 1 FROM target_notes n WHERE n.target_id = t.id);
+
+
+-- Target attachments. The content lives in the attachment store under
+-- storage_key; sha256 is its hex checksum.
+CREATE TABLE IF NOT EXISTS target_attachments (
+    id BIGSERIAL PRIMARY KEY,
+    target_id BIGINT NOT NULL REFERENCES targets(id) ON DELETE CASCADE,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL CHECK (size > 0),
+    sha256 CHAR(64) NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS target_attachments_target_created_idx ON target_attachments (target_id, created_at, id);
+
+
+-- Notes encryption. Notes sealed by the application (enc:v1:...) are left
+-- out of target events, which are kept and delivered to webhooks in the
+-- clear.
+--
+-- notes_sealed and body_sealed record which values are sealed, since
+-- plaintext notes can look sealed too. Rows from before they existed are
+-- sealed if they have the shape of a sealed value; the columns are filled
+-- in only once, while they are still null.
+ALTER TABLE targets ADD COLUMN IF NOT EXISTS notes_sealed BOOLEAN NULL;
+ALTER TABLE target_notes ADD COLUMN IF NOT EXISTS body_sealed BOOLEAN NULL;
+
+UPDATE targets SET notes_sealed = notes ~ '^enc:v1:[A-Za-z0-9._-]+:[A-Za-z0-9_-]+:[A-Za-z0-9_-]+$'
+WHERE notes_sealed IS NULL;
+UPDATE target_notes SET body_sealed = body ~ '^enc:v1:[A-Za-z0-9._-]+:[A-Za-z0-9_-]+:[A-Za-z0-9_-]+$'
+WHERE body_sealed IS NULL;
+
+ALTER TABLE targets ALTER COLUMN notes_sealed SET DEFAULT false, ALTER COLUMN notes_sealed SET NOT NULL;
+ALTER TABLE target_notes ALTER COLUMN body_sealed SET DEFAULT false, ALTER COLUMN body_sealed SET NOT NULL;
+
+-- Full-text search only covers plaintext notes; indexing the ciphertext of
+-- sealed ones would be of no use.
+DROP INDEX IF EXISTS targets_notes_fts_idx;
+CREATE INDEX IF NOT EXISTS targets_plain_notes_fts_idx
+    ON targets USING GIN (to_tsvector('english', notes)) WHERE NOT notes_sealed;
+
+CREATE OR REPLACE FUNCTION record_target_event() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO events (type, mission_id, cat_id, target_id, data)
+    SELECT 'target.updated', NEW.mission_id, m.cat_id, NEW.id,
+        CASE WHEN NEW.notes_sealed THEN to_jsonb(NEW) - 'notes' ELSE to_jsonb(NEW) END
+    FROM missions m WHERE m.id = NEW.mission_id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+
+-- Contents of deleted attachments, which the server removes from the
+-- attachment store. Every deleted attachment is queued here, including those
+-- deleted along with their target or mission.
+CREATE TABLE IF NOT EXISTS attachment_blob_deletions (
+    storage_key TEXT PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE OR REPLACE FUNCTION queue_attachment_blob_deletion() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO attachment_blob_deletions (storage_key) VALUES (OLD.storage_key)
+    ON CONFLICT DO NOTHING;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER target_attachments_queue_blob_deletion
+    AFTER DELETE ON target_attachments
+    FOR EACH ROW EXECUTE FUNCTION queue_attachment_blob_deletion();
@@ -0,0 +1,9 @@
+// Package db embeds the database schema. The same file initializes the
+// docker-compose database and is applied by the migrate command; every
+// statement in it can be run again on an existing database.
+package db
+
+import _ "embed"
+
+//go:embed init/init.sql
+var Schema string
@@ -66,6 +66,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/handler.CatRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the stored response when a request is retried with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -83,6 +89,147 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded, see Retry-After",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/cats/payroll": {
+            "get": {
+                "description": "Total monthly salaries per period over the whole months between from and to. Each cat counts at the salary in effect on the last day of every month.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cats"
+                ],
+                "summary": "Payroll report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First month, as a date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last month, as a date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "month",
+                            "quarter",
+                            "year"
+                        ],
+                        "type": "string",
+                        "default": "month",
+                        "description": "Grouping period",
+                        "name": "period",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PayrollReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/cats/search": {
+            "get": {
+                "description": "Find cats by partial name or breed. Words match by prefix, and near misses match by trigram similarity. Results are ranked best first, with matched words in name and breed wrapped in \u003cmark\u003e tags.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cats"
+                ],
+                "summary": "Search cats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search text",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Maximum number of results, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handler.SearchResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
@@ -133,6 +280,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
+                "description": "The cat is marked deleted and its salary history kept. A cat on a mission that is not closed cannot be deleted.",
                 "tags": [
                     "cats"
                 ],
@@ -158,12 +306,31 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
         },
         "/cats/{id}/salary": {
             "put": {
+                "description": "Record a salary change in the cat's ledger. It takes effect today unless an earlier effective_from date is given; it cannot predate the latest recorded change.",
                 "consumes": [
                     "application/json"
                 ],
@@ -201,303 +368,579 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Effective date before the latest change",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/missions": {
+        "/cats/{id}/salary/history": {
             "get": {
-                "description": "Retrieve a list of all missions.",
+                "description": "List the salary ledger of a cat, oldest change first.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Missions"
+                    "cats"
+                ],
+                "summary": "Get a cat's salary history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Cat ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "List all missions",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.Mission"
+                                "$ref": "#/definitions/domain.SalaryChange"
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Create a new mission with the provided details.",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/events": {
+            "get": {
+                "description": "Server-Sent Events stream of mission created/assigned/completed and target updated events. Reconnecting clients may send the Last-Event-ID header (or last_event_id query parameter) to replay events they missed.",
                 "produces": [
-                    "application/json"
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "Missions"
+                    "Events"
                 ],
-                "summary": "Create a new mission",
+                "summary": "Stream mission and target events",
                 "parameters": [
                     {
-                        "description": "Mission details",
-                        "name": "mission",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.MissionDTO"
-                        }
+                        "type": "integer",
+                        "description": "Only events of this mission",
+                        "name": "mission_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only events of missions assigned to this cat",
+                        "name": "cat_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Resume after this event ID",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Resume after this event ID",
+                        "name": "last_event_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Mission"
+                            "$ref": "#/definitions/domain.Event"
                         }
                     },
                     "400": {
-                        "description": "Wrong request format",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal server error",
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/missions/{id}": {
+        "/export/cats": {
             "get": {
-                "description": "Retrieve a mission by its ID.",
+                "description": "Download every cat as CSV (with a header row), JSON Lines or a JSON array, streamed from the database in ID order.",
                 "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
                     "application/json"
                 ],
                 "tags": [
-                    "Missions"
+                    "cats"
                 ],
-                "summary": "Get a mission by ID",
+                "summary": "Export cats",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Mission ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "enum": [
+                            "csv",
+                            "jsonl",
+                            "json"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Output format",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Mission"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handler.CatResponse"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid mission ID",
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
-                    "404": {
-                        "description": "Mission not found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update an existing mission with the provided details.",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/export/missions": {
+            "get": {
+                "description": "Download missions flattened with their targets, one row per target and one with empty target fields for a mission without targets, as CSV (with a header row), JSON Lines or a JSON array. Rows are streamed from the database in mission and target ID order. status, completed and country filter the targets as in the target list; with any of them set, missions without matching targets are left out.",
                 "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
                     "application/json"
                 ],
                 "tags": [
                     "Missions"
                 ],
-                "summary": "Update a mission",
+                "summary": "Export missions",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Mission ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "enum": [
+                            "csv",
+                            "jsonl",
+                            "json"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Output format",
+                        "name": "format",
+                        "in": "query"
                     },
                     {
-                        "description": "Mission details",
-                        "name": "mission",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.MissionDTO"
-                        }
+                        "enum": [
+                            "pending",
+                            "compromised",
+                            "eliminated",
+                            "escaped"
+                        ],
+                        "type": "string",
+                        "description": "Filter targets by status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter targets by completion status",
+                        "name": "completed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter targets by country code, name or alias",
+                        "name": "country",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Mission"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.MissionExportRow"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid mission ID or request format",
+                        "description": "Invalid format or filter",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a mission by its ID.",
+            }
+        },
+        "/import": {
+            "post": {
+                "description": "Import cats or missions with their targets from an uploaded CSV or JSON Lines file. CSV files use the columns of the matching export; rows of a mission CSV sharing a mission_id make up one mission, which gets a new ID. Every record is validated first, breeds with a bounded number of parallel lookups; valid records are then committed in batches, each in its own transaction. Invalid records and the records of a failed batch are listed by line. With dry_run nothing is written.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Missions"
+                    "Import"
                 ],
-                "summary": "Delete a mission",
+                "summary": "Bulk import cats or missions",
                 "parameters": [
+                    {
+                        "enum": [
+                            "cats",
+                            "missions"
+                        ],
+                        "type": "string",
+                        "description": "What the file holds",
+                        "name": "kind",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "jsonl"
+                        ],
+                        "type": "string",
+                        "description": "File format; defaults to jsonl for .jsonl and .ndjson files, csv otherwise",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the file",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Mission ID",
-                        "name": "id",
-                        "in": "path",
+                        "default": 100,
+                        "description": "Records per transaction (1-1000)",
+                        "name": "batch_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "CSV or JSON Lines file",
+                        "name": "file",
+                        "in": "formData",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "Dry run",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Report"
+                        }
+                    },
+                    "201": {
+                        "description": "All records imported",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Report"
+                        }
+                    },
+                    "207": {
+                        "description": "Some records were not imported",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Report"
+                        }
                     },
                     "400": {
-                        "description": "Invalid mission ID",
+                        "description": "Invalid parameters or unreadable file",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/internal_importer_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "No record was imported",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Report"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/internal_importer_delivery_http.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/missions/{id}/cat/{catID}": {
+        "/missions": {
+            "get": {
+                "description": "Retrieve a list of all missions. A mission is overdue when it is not closed by its deadline or has a target that is not completed by the target's deadline. Notes are blanked, with notes_redacted set, when the server redacts list notes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Missions"
+                ],
+                "summary": "List all missions",
+                "parameters": [
+                    {
+                        "enum": [
+                            "draft",
+                            "assigned",
+                            "in_progress",
+                            "completed",
+                            "aborted",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Only missions in this state",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only missions that are (or are not) overdue",
+                        "name": "overdue",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only missions that are (or are not) completed",
+                        "name": "completed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only missions that have (or have no) cat",
+                        "name": "assigned",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language of country names, e.g. fr; defaults to Accept-Language, then English",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Mission"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Assign a cat to a mission by their IDs.",
+                "description": "Create a new mission with the provided details. An optional deadline must be in the future. Priority (0-10), required_experience and preferred_breeds are used by auto-assignment.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "Missions"
                 ],
-                "summary": "Assign Cat to Mission",
+                "summary": "Create a new mission",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Mission ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Mission details",
+                        "name": "mission",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.MissionDTO"
+                        }
                     },
                     {
-                        "type": "integer",
-                        "description": "Cat ID",
-                        "name": "catID",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Replays the stored response when a request is retried with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Mission"
+                        }
                     },
                     "400": {
                         "description": "Wrong request format",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/missions/{id}/targets": {
+        "/missions/auto-assign": {
             "post": {
-                "description": "Add multiple targets to a mission by its ID.",
+                "description": "Auto-assign the given missions, or every uncompleted unassigned mission without a body, choosing cats as POST /missions/{id}/auto-assign does. Missions with a higher priority pick first, then those with the earliest deadline. Missions that could not be assigned are reported with the reason.",
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "Missions"
                 ],
-                "summary": "Add Targets to Mission",
+                "summary": "Auto-assign cats to missions",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Mission ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Targets to add",
-                        "name": "targets",
+                        "description": "Missions to assign",
+                        "name": "missions",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/handler.TargetDTO"
-                            }
+                            "$ref": "#/definitions/handler.AutoAssignDTO"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AutoAssignReport"
+                        }
                     },
                     "400": {
                         "description": "Invalid request format",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/targets/{id}": {
+        "/missions/{id}": {
+            "get": {
+                "description": "Retrieve a mission by its ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Missions"
+                ],
+                "summary": "Get a mission by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language of country names, e.g. fr; defaults to Accept-Language, then English",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Mission"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid mission ID",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Mission not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            },
             "put": {
-                "description": "Update an existing target by its ID.",
+                "description": "Update an existing mission with the provided details. A changed deadline must be in the future and no earlier than the deadline of any of its targets. Changing the cat assigns the mission anew (or makes it a draft again without one), which is not allowed once it is in progress; completed set to true completes a mission in progress with the checks of POST /missions/{id}/complete. Closed missions cannot be updated, and an update that races with a transition is rejected with 409.",
                 "consumes": [
                     "application/json"
                 ],
@@ -505,24 +948,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Targets"
+                    "Missions"
                 ],
-                "summary": "Update Target",
+                "summary": "Update a mission",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Target ID",
+                        "description": "Mission ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Target details",
-                        "name": "target",
+                        "description": "Mission details",
+                        "name": "mission",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.Target"
+                            "$ref": "#/definitions/handler.MissionDTO"
                         }
                     }
                 ],
@@ -530,33 +973,45 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Target"
+                            "$ref": "#/definitions/domain.Mission"
                         }
                     },
                     "400": {
-                        "description": "Wrong request format or invalid target ID",
+                        "description": "Invalid mission ID or request format",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Mission not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Mission closed, cat unavailable or concurrent transition",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
                         }
                     }
                 }
             },
             "delete": {
-                "description": "Delete a target by its ID.",
+                "description": "Delete a mission by its ID, with its targets. A mission assigned to a cat cannot be deleted.",
                 "tags": [
-                    "Targets"
+                    "Missions"
                 ],
-                "summary": "Delete Target",
+                "summary": "Delete a mission",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Target ID",
+                        "description": "Mission ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -567,40 +1022,1890 @@ const docTemplate = `{
                         "description": "No Content"
                     },
                     "400": {
-                        "description": "Invalid target ID",
+                        "description": "Invalid mission ID",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Mission not found",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Mission assigned to a cat",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.Mission": {
-            "type": "object",
-            "properties": {
-                "cat_id": {
-                    "type": "integer"
-                },
-                "completed": {
-                    "type": "boolean"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "targets": {
-                    "type": "array",
-                    "items": {
+        },
+        "/missions/{id}/abort": {
+            "post": {
+                "description": "Move a draft, assigned or in progress mission to aborted, which frees its cat.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Missions"
+                ],
+                "summary": "Abort a mission",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Mission"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid mission ID",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Mission not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Transition not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/missions/{id}/auto-assign": {
+            "post": {
+                "description": "Assign the best available cat: cats on an uncompleted mission or with fewer years of experience than the mission requires are left out, then cats of a preferred breed are chosen first and, among them, the one with the lowest salary. The response explains the choice.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Missions"
+                ],
+                "summary": "Auto-assign a cat to a mission",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AutoAssignment"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid mission ID",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Mission not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "No cat is available, or the mission is assigned or completed",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AutoAssignment"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/missions/{id}/cat/{catID}": {
+            "post": {
+                "description": "Assign a cat to a draft mission by their IDs. A cat that is on another open mission is rejected with 409.",
+                "tags": [
+                    "Missions"
+                ],
+                "summary": "Assign Cat to Mission",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Cat ID",
+                        "name": "catID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Wrong request format",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Mission already assigned or cat unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/missions/{id}/complete": {
+            "post": {
+                "description": "Move a mission in progress to completed once all of its targets are completed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Missions"
+                ],
+                "summary": "Complete a mission",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Mission"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid mission ID",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Mission not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Transition not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/missions/{id}/fail": {
+            "post": {
+                "description": "Move a mission in progress to failed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Missions"
+                ],
+                "summary": "Fail a mission",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Mission"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid mission ID",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Mission not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Transition not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/missions/{id}/start": {
+            "post": {
+                "description": "Move an assigned mission to in_progress. The mission needs a cat and at least one target.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Missions"
+                ],
+                "summary": "Start a mission",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Mission"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid mission ID",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Mission not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Transition not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/missions/{id}/targets": {
+            "get": {
+                "description": "Retrieve the targets of a mission, optionally filtered by status, completion status and country. Notes are blanked, with notes_redacted set, when the server redacts list notes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Targets"
+                ],
+                "summary": "List Targets of Mission",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "compromised",
+                            "eliminated",
+                            "escaped"
+                        ],
+                        "type": "string",
+                        "description": "Filter by status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by completion status",
+                        "name": "completed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by country code, name or alias",
+                        "name": "country",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language of country names, e.g. fr; defaults to Accept-Language, then English",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Target"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid mission ID or filter",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Mission not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add multiple targets to a mission by its ID. In all_or_nothing mode (the default) any invalid or duplicate target rejects the whole batch; in best_effort mode valid targets are created and the rest are reported per item.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Missions"
+                ],
+                "summary": "Add Targets to Mission",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "all_or_nothing",
+                            "best_effort"
+                        ],
+                        "type": "string",
+                        "description": "Import mode",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Targets to add",
+                        "name": "targets",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handler.TargetDTO"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the stored response when a request is retried with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language of country names, e.g. fr; defaults to Accept-Language, then English",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "All targets created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TargetImportReport"
+                        }
+                    },
+                    "207": {
+                        "description": "Some targets were not created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TargetImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Mission not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Mission is completed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Import rejected",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TargetImportReport"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/missions/{id}/targets/csv": {
+            "post": {
+                "description": "Add targets to a mission from an uploaded CSV file with a header row of name,country and optional notes,status,outcome,completed,deadline columns (deadline in RFC 3339). Import modes behave as in the JSON variant; item indices refer to data rows starting at 0.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Missions"
+                ],
+                "summary": "Upload Targets CSV",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Mission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "all_or_nothing",
+                            "best_effort"
+                        ],
+                        "type": "string",
+                        "description": "Import mode",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "CSV file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the stored response when a request is retried with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language of country names, e.g. fr; defaults to Accept-Language, then English",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "All targets created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TargetImportReport"
+                        }
+                    },
+                    "207": {
+                        "description": "Some targets were not created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TargetImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format or CSV",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Mission not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Mission is completed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Import rejected",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TargetImportReport"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats": {
+            "get": {
+                "description": "Aggregated figures across the agency: missions by status (unassigned, in_progress, completed, aborted, failed), unassigned missions, completion rate, average time to complete (from creation to completion of completed missions), targets per country, the top cats by completed missions and salary totals. All figures come from one consistent snapshot.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stats"
+                ],
+                "summary": "Agency dashboard",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Number of top cats (1-50)",
+                        "name": "top_cats",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language of country names, e.g. fr; defaults to Accept-Language, then English",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Dashboard"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid top_cats",
+                        "schema": {
+                            "$ref": "#/definitions/internal_stats_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_stats_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/targets/search": {
+            "get": {
+                "description": "Search targets across all missions. q is full-text searched in the notes (web search syntax: quoted phrases, OR, -excluded); results are ranked best first with matching fragments of the notes in highlight. Without q, targets are listed by ID. q is rejected while notes are encrypted at rest. Notes are blanked, with notes_redacted set, when the server redacts list notes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Targets"
+                ],
+                "summary": "Search Targets",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Full-text query over notes",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by country code, name or alias",
+                        "name": "country",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by completion status",
+                        "name": "completed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by whether the mission is assigned to a cat",
+                        "name": "assigned",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by the cat assigned to the mission",
+                        "name": "cat_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by mission",
+                        "name": "mission_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of results to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language of country names, e.g. fr; defaults to Accept-Language, then English",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TargetSearchPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter or pagination, or q while notes are encrypted",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/targets/{id}": {
+            "get": {
+                "description": "Retrieve a single target by its ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Targets"
+                ],
+                "summary": "Get a target by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Target ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language of country names, e.g. fr; defaults to Accept-Language, then English",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Target"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid target ID",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Target not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update the notes, status and/or deadline of a target. status reports the outcome, with the reason in outcome: pending targets can become compromised, and pending or compromised targets eliminated or escaped, which is final. completed set to true eliminates a target that is not eliminated or escaped yet. Changed notes are appended to the target's notes journal (see POST /targets/{id}/notes) without an author. Notes are locked once the target is eliminated or escaped or its mission is closed, and a completed target cannot be marked as not completed. A new deadline must be in the future and no later than the mission's.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Targets"
+                ],
+                "summary": "Update Target",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Target ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "target",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateTargetDTO"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language of country names, e.g. fr; defaults to Accept-Language, then English",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Target"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong request format or invalid target ID",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Target not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Target is final or mission is closed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a target by its ID.",
+                "tags": [
+                    "Targets"
+                ],
+                "summary": "Delete Target",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Target ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid target ID",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/targets/{id}/attachments": {
+            "get": {
+                "description": "Retrieve the attachments of a target, oldest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Targets"
+                ],
+                "summary": "List Target Attachments",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Target ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Attachment"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid target ID",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Target not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Attach a photo (JPEG, PNG, GIF, WebP), PDF document or plain text file to a target. The type is sniffed from the content and the SHA-256 checksum of the content is returned; when the optional sha256 field is sent, the content must match it. Attachments cannot be added once the target is eliminated or escaped or its mission is closed.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Targets"
+                ],
+                "summary": "Upload Target Attachment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Target ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File to attach",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Hex SHA-256 checksum of the file",
+                        "name": "sha256",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Attachment"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing file, invalid target ID, filename or checksum, or empty file",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Target not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Attachments are locked",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "File too large",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported file type",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/targets/{id}/attachments/{attachmentID}": {
+            "get": {
+                "description": "Download the content of an attachment under its original filename. The ETag is its SHA-256 checksum.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Targets"
+                ],
+                "summary": "Download Target Attachment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Target ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Attachment ID",
+                        "name": "attachmentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid target or attachment ID",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Attachment not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete an attachment of a target, unless the target is eliminated or escaped or its mission is closed.",
+                "tags": [
+                    "Targets"
+                ],
+                "summary": "Delete Target Attachment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Target ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Attachment ID",
+                        "name": "attachmentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Attachment deleted"
+                    },
+                    "400": {
+                        "description": "Invalid target or attachment ID",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Attachment not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Attachments are locked",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/targets/{id}/complete": {
+            "post": {
+                "description": "Mark a target as completed, which eliminates it. Completing an eliminated or escaped target is a no-op.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Targets"
+                ],
+                "summary": "Complete Target",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Target ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language of country names, e.g. fr; defaults to Accept-Language, then English",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Target"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid target ID",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Target not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/targets/{id}/notes": {
+            "get": {
+                "description": "Retrieve the notes journal of a target, oldest entry first. Entries without an author were written through the notes field of the target.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Targets"
+                ],
+                "summary": "List Target Notes",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Target ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.TargetNote"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid target ID",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Target not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Append an entry to the notes journal of a target, which also becomes the target's notes. Entries cannot be edited or removed, and none can be added once the target is eliminated or escaped or its mission is closed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Targets"
+                ],
+                "summary": "Add Target Note",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Target ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Note",
+                        "name": "note",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.TargetNoteDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TargetNote"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong request format, invalid target ID, missing author or empty note",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Target not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Notes are locked",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_mission_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "List webhook subscriptions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Subscription"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_webhook_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Subscribe a URL to mission events. Deliveries are signed with HMAC-SHA256 in the X-Webhook-Signature header; the secret is generated when omitted and only returned in this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Create a webhook subscription",
+                "parameters": [
+                    {
+                        "description": "Subscription details",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.SubscriptionDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreatedSubscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid URL or event types",
+                        "schema": {
+                            "$ref": "#/definitions/internal_webhook_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_webhook_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/deliveries": {
+            "get": {
+                "description": "List the most recent deliveries in a status. Defaults to dead deliveries, i.e. the dead-letter queue.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "enum": [
+                            "pending",
+                            "delivered",
+                            "dead"
+                        ],
+                        "type": "string",
+                        "description": "Delivery status",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Delivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status",
+                        "schema": {
+                            "$ref": "#/definitions/internal_webhook_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_webhook_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/deliveries/{id}/retry": {
+            "post": {
+                "description": "Move a dead delivery back to the queue with a fresh set of attempts.",
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Retry a dead webhook delivery",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "400": {
+                        "description": "Invalid delivery ID",
+                        "schema": {
+                            "$ref": "#/definitions/internal_webhook_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Dead delivery not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_webhook_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get a webhook subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Subscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid subscription ID",
+                        "schema": {
+                            "$ref": "#/definitions/internal_webhook_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Subscription not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_webhook_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the URL, event types and active flag. An omitted secret keeps the current one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Update a webhook subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Subscription details",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.SubscriptionDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Subscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_webhook_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Subscription not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_webhook_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a subscription along with its pending and dead deliveries.",
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Delete a webhook subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid subscription ID",
+                        "schema": {
+                            "$ref": "#/definitions/internal_webhook_delivery_http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Subscription not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_webhook_delivery_http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.Attachment": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "filename": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "sha256": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "target_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.AutoAssignReport": {
+            "type": "object",
+            "properties": {
+                "assigned": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.AutoAssignment"
+                    }
+                },
+                "unassigned": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.AutoAssignment": {
+            "type": "object",
+            "properties": {
+                "busy": {
+                    "type": "integer"
+                },
+                "cat": {
+                    "$ref": "#/definitions/domain.Candidate"
+                },
+                "considered": {
+                    "type": "integer"
+                },
+                "eligible": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "explanation": {
+                    "type": "string"
+                },
+                "inexperienced": {
+                    "type": "integer"
+                },
+                "mission_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Candidate": {
+            "type": "object",
+            "properties": {
+                "breed": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "salary": {
+                    "type": "number"
+                },
+                "years_of_experience": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.CatRanking": {
+            "type": "object",
+            "properties": {
+                "cat_id": {
+                    "type": "integer"
+                },
+                "completed_missions": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CountryCount": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "integer"
+                },
+                "country": {
+                    "type": "string"
+                },
+                "country_name": {
+                    "type": "string"
+                },
+                "targets": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Dashboard": {
+            "type": "object",
+            "properties": {
+                "generated_at": {
+                    "type": "string"
+                },
+                "missions": {
+                    "$ref": "#/definitions/domain.MissionStats"
+                },
+                "salaries": {
+                    "$ref": "#/definitions/domain.SalaryStats"
+                },
+                "targets_by_country": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.CountryCount"
+                    }
+                },
+                "top_cats": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.CatRanking"
+                    }
+                }
+            }
+        },
+        "domain.Delivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "integer"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.DeliveryStatus"
+                },
+                "subscription_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.DeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "delivered",
+                "dead"
+            ],
+            "x-enum-varnames": [
+                "DeliveryPending",
+                "DeliveryDelivered",
+                "DeliveryDead"
+            ]
+        },
+        "domain.Event": {
+            "type": "object",
+            "properties": {
+                "cat_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "mission_id": {
+                    "type": "integer"
+                },
+                "target_id": {
+                    "type": "integer"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.Type"
+                }
+            }
+        },
+        "domain.Kind": {
+            "type": "string",
+            "enum": [
+                "cats",
+                "missions"
+            ],
+            "x-enum-varnames": [
+                "KindCats",
+                "KindMissions"
+            ]
+        },
+        "domain.Mission": {
+            "type": "object",
+            "properties": {
+                "aborted_at": {
+                    "type": "string"
+                },
+                "assigned_at": {
+                    "type": "string"
+                },
+                "cat_id": {
+                    "type": "integer"
+                },
+                "completed": {
+                    "type": "boolean"
+                },
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deadline": {
+                    "type": "string"
+                },
+                "failed_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "overdue": {
+                    "type": "boolean"
+                },
+                "preferred_breeds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "progress": {
+                    "$ref": "#/definitions/domain.Progress"
+                },
+                "required_experience": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "$ref": "#/definitions/domain.State"
+                },
+                "targets": {
+                    "type": "array",
+                    "items": {
                         "$ref": "#/definitions/domain.Target"
                     }
                 },
@@ -609,18 +2914,465 @@ const docTemplate = `{
                 }
             }
         },
+        "domain.MissionExportRow": {
+            "type": "object",
+            "properties": {
+                "cat_id": {
+                    "type": "integer"
+                },
+                "mission_completed": {
+                    "type": "boolean"
+                },
+                "mission_created_at": {
+                    "type": "string"
+                },
+                "mission_deadline": {
+                    "type": "string"
+                },
+                "mission_id": {
+                    "type": "integer"
+                },
+                "mission_preferred_breeds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "mission_priority": {
+                    "type": "integer"
+                },
+                "mission_required_experience": {
+                    "type": "integer"
+                },
+                "mission_state": {
+                    "$ref": "#/definitions/domain.State"
+                },
+                "target_completed": {
+                    "type": "boolean"
+                },
+                "target_country": {
+                    "type": "string"
+                },
+                "target_deadline": {
+                    "type": "string"
+                },
+                "target_id": {
+                    "type": "integer"
+                },
+                "target_name": {
+                    "type": "string"
+                },
+                "target_notes": {
+                    "type": "string"
+                },
+                "target_outcome": {
+                    "type": "string"
+                },
+                "target_status": {
+                    "$ref": "#/definitions/domain.TargetStatus"
+                },
+                "target_updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.MissionStats": {
+            "type": "object",
+            "properties": {
+                "avg_time_to_complete_seconds": {
+                    "description": "AvgTimeToComplete is the mean time from creation to completion of\ncompleted missions, in seconds; nil until a mission is completed.",
+                    "type": "number"
+                },
+                "by_status": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "completion_rate": {
+                    "description": "CompletionRate is the share of all missions that are completed, 0..1.",
+                    "type": "number"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "unassigned": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.PayrollLine": {
+            "type": "object",
+            "properties": {
+                "headcount": {
+                    "type": "integer"
+                },
+                "period_end": {
+                    "type": "string"
+                },
+                "period_start": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.PayrollPeriod": {
+            "type": "string",
+            "enum": [
+                "month",
+                "quarter",
+                "year"
+            ],
+            "x-enum-varnames": [
+                "PayrollMonth",
+                "PayrollQuarter",
+                "PayrollYear"
+            ]
+        },
+        "domain.PayrollReport": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.PayrollLine"
+                    }
+                },
+                "period": {
+                    "$ref": "#/definitions/domain.PayrollPeriod"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.Progress": {
+            "type": "object",
+            "properties": {
+                "compromised": {
+                    "type": "integer"
+                },
+                "eliminated": {
+                    "type": "integer"
+                },
+                "escaped": {
+                    "type": "integer"
+                },
+                "percent": {
+                    "type": "integer"
+                },
+                "targets": {
+                    "type": "integer"
+                },
+                "terminal": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Report": {
+            "type": "object",
+            "properties": {
+                "batches": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.RowError"
+                    }
+                },
+                "imported": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "$ref": "#/definitions/domain.Kind"
+                },
+                "records": {
+                    "description": "Records is how many cats or missions were read, Valid how many of them\npassed validation and Imported how many were committed.",
+                    "type": "integer"
+                },
+                "targets": {
+                    "description": "Targets is how many targets were committed with the missions.",
+                    "type": "integer"
+                },
+                "valid": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.RowError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "line": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.SalaryChange": {
+            "type": "object",
+            "properties": {
+                "cat_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "effective_from": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "new_salary": {
+                    "type": "number"
+                },
+                "old_salary": {
+                    "type": "number"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.SalaryStats": {
+            "type": "object",
+            "properties": {
+                "average": {
+                    "type": "number"
+                },
+                "headcount": {
+                    "type": "integer"
+                },
+                "max": {
+                    "type": "number"
+                },
+                "min": {
+                    "type": "number"
+                },
+                "total": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.State": {
+            "type": "string",
+            "enum": [
+                "draft",
+                "assigned",
+                "in_progress",
+                "completed",
+                "aborted",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "StateDraft",
+                "StateAssigned",
+                "StateInProgress",
+                "StateCompleted",
+                "StateAborted",
+                "StateFailed"
+            ]
+        },
+        "domain.Subscription": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
         "domain.Target": {
             "type": "object",
             "properties": {
                 "completed": {
                     "type": "boolean"
                 },
+                "compromised_at": {
+                    "type": "string"
+                },
+                "country": {
+                    "type": "string"
+                },
+                "country_name": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deadline": {
+                    "type": "string"
+                },
+                "eliminated_at": {
+                    "type": "string"
+                },
+                "escaped_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "mission_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "notes_redacted": {
+                    "type": "boolean"
+                },
+                "outcome": {
+                    "type": "string"
+                },
+                "overdue": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.TargetStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.TargetImportError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.TargetImportReport": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Target"
+                    }
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.TargetImportError"
+                    }
+                }
+            }
+        },
+        "domain.TargetNote": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string"
+                },
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "target_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.TargetSearchPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.TargetSearchResult"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.TargetSearchResult": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "boolean"
+                },
+                "compromised_at": {
+                    "type": "string"
+                },
                 "country": {
                     "type": "string"
                 },
+                "country_name": {
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "deadline": {
+                    "type": "string"
+                },
+                "eliminated_at": {
+                    "type": "string"
+                },
+                "escaped_at": {
+                    "type": "string"
+                },
+                "highlight": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -633,11 +3385,82 @@ const docTemplate = `{
                 "notes": {
                     "type": "string"
                 },
+                "notes_redacted": {
+                    "type": "boolean"
+                },
+                "outcome": {
+                    "type": "string"
+                },
+                "overdue": {
+                    "type": "boolean"
+                },
+                "rank": {
+                    "type": "number"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.TargetStatus"
+                },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
+        "domain.TargetStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "compromised",
+                "eliminated",
+                "escaped"
+            ],
+            "x-enum-varnames": [
+                "TargetPending",
+                "TargetCompromised",
+                "TargetEliminated",
+                "TargetEscaped"
+            ]
+        },
+        "domain.Type": {
+            "type": "string",
+            "enum": [
+                "mission.created",
+                "mission.assigned",
+                "mission.started",
+                "mission.completed",
+                "mission.aborted",
+                "mission.failed",
+                "target.updated",
+                "mission.overdue",
+                "target.overdue"
+            ],
+            "x-enum-varnames": [
+                "MissionCreated",
+                "MissionAssigned",
+                "MissionStarted",
+                "MissionCompleted",
+                "MissionAborted",
+                "MissionFailed",
+                "TargetUpdated",
+                "MissionOverdue",
+                "TargetOverdue"
+            ]
+        },
+        "handler.AutoAssignDTO": {
+            "type": "object",
+            "properties": {
+                "mission_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        3
+                    ]
+                }
+            }
+        },
         "handler.CatRequest": {
             "type": "object",
             "required": [
@@ -695,10 +3518,31 @@ const docTemplate = `{
                 }
             }
         },
-        "handler.ErrorResponse": {
+        "handler.CreatedSubscription": {
             "type": "object",
             "properties": {
-                "error": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
                     "type": "string"
                 }
             }
@@ -713,6 +3557,76 @@ const docTemplate = `{
                 "completed": {
                     "type": "boolean",
                     "example": false
+                },
+                "deadline": {
+                    "type": "string",
+                    "example": "2026-12-31T18:00:00Z"
+                },
+                "preferred_breeds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Siamese",
+                        "Bengal"
+                    ]
+                },
+                "priority": {
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 0,
+                    "example": 5
+                },
+                "required_experience": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 3
+                }
+            }
+        },
+        "handler.SearchResult": {
+            "type": "object",
+            "properties": {
+                "cat": {
+                    "$ref": "#/definitions/handler.CatResponse"
+                },
+                "highlights": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "rank": {
+                    "type": "number",
+                    "example": 0.87
+                }
+            }
+        },
+        "handler.SubscriptionDTO": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "mission.created",
+                        "mission.completed"
+                    ]
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "s3cr3t"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/hooks/missions"
                 }
             }
         },
@@ -725,7 +3639,11 @@ const docTemplate = `{
                 },
                 "country": {
                     "type": "string",
-                    "example": "Country name"
+                    "example": "United Kingdom"
+                },
+                "deadline": {
+                    "type": "string",
+                    "example": "2026-12-24T12:00:00Z"
                 },
                 "name": {
                     "type": "string",
@@ -734,6 +3652,37 @@ const docTemplate = `{
                 "notes": {
                     "type": "string",
                     "example": "Additional notes"
+                },
+                "outcome": {
+                    "type": "string",
+                    "example": "Spotted the cat at the border"
+                },
+                "status": {
+                    "enum": [
+                        "pending",
+                        "compromised",
+                        "eliminated",
+                        "escaped"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.TargetStatus"
+                        }
+                    ],
+                    "example": "pending"
+                }
+            }
+        },
+        "handler.TargetNoteDTO": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "Agent Whiskers"
+                },
+                "body": {
+                    "type": "string",
+                    "example": "Seen leaving the embassy at 9pm"
                 }
             }
         },
@@ -743,12 +3692,88 @@ const docTemplate = `{
                 "salary"
             ],
             "properties": {
+                "effective_from": {
+                    "type": "string",
+                    "example": "2024-01-01"
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "Annual review"
+                },
                 "salary": {
                     "type": "number",
                     "minimum": 0,
                     "example": 1300.75
                 }
             }
+        },
+        "handler.UpdateTargetDTO": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "deadline": {
+                    "type": "string",
+                    "example": "2026-12-24T12:00:00Z"
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "Updated notes"
+                },
+                "outcome": {
+                    "type": "string",
+                    "example": "Crossed the border before dawn"
+                },
+                "status": {
+                    "enum": [
+                        "pending",
+                        "compromised",
+                        "eliminated",
+                        "escaped"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.TargetStatus"
+                        }
+                    ],
+                    "example": "escaped"
+                }
+            }
+        },
+        "internal_importer_delivery_http.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_mission_delivery_http.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_stats_delivery_http.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_webhook_delivery_http.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
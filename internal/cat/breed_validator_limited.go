@@ -0,0 +1,28 @@
+package cat
+
+import (
+	"context"
+
+	"go-test-assesment/internal/cat/domain"
+	"go-test-assesment/pkg/ratelimit"
+)
+
+// RateLimitedValidator caps the rate of outbound breed lookups across all
+// clients, so that bursts of cat creation cannot get the API key banned.
+type RateLimitedValidator struct {
+	next    domain.BreedValidator
+	limiter *ratelimit.Limiter
+}
+
+func NewRateLimitedValidator(next domain.BreedValidator, limit ratelimit.Limit) *RateLimitedValidator {
+	return &RateLimitedValidator{next: next, limiter: ratelimit.New(limit)}
+}
+
+// ValidateBreed fails fast with a *ratelimit.LimitedError instead of queueing
+// the request when the budget is used up.
+func (v *RateLimitedValidator) ValidateBreed(ctx context.Context, breed string) (bool, error) {
+	if ok, wait := v.limiter.Allow(""); !ok {
+		return false, &ratelimit.LimitedError{RetryAfter: wait}
+	}
+	return v.next.ValidateBreed(ctx, breed)
+}
@@ -0,0 +1,36 @@
+package cat
+
+import (
+	"context"
+	_ "embed"
+	"slices"
+	"strings"
+)
+
+// breedList is a snapshot of the breed names served by TheCatAPI, one per
+// line in alphabetical order.
+//
+//go:embed breeds.txt
+var breedList string
+
+var breeds = strings.Split(strings.TrimSpace(breedList), "\n")
+
+// Breeds returns the breeds known without network access, in alphabetical
+// order.
+func Breeds() []string {
+	return slices.Clone(breeds)
+}
+
+// StaticValidator checks breeds against the embedded snapshot, for
+// development and tests without network access. Breeds added to
+// TheCatAPI since the snapshot are rejected.
+type StaticValidator struct{}
+
+func NewStaticValidator() *StaticValidator {
+	return &StaticValidator{}
+}
+
+func (v *StaticValidator) ValidateBreed(_ context.Context, breed string) (bool, error) {
+	_, found := slices.BinarySearch(breeds, breed)
+	return found, nil
+}
@@ -1,19 +1,23 @@
 package handler
 
 import (
+	"errors"
 	"go-test-assesment/internal/cat/domain"
-	"go-test-assesment/internal/cat/usecase"
+	"go-test-assesment/pkg/export"
+	"go-test-assesment/pkg/money"
+	"go-test-assesment/pkg/ratelimit"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type CatHandler struct {
-	usecase *usecase.CatUsecase
+	usecase domain.Usecase
 }
 
-func NewCatHandler(r *gin.Engine, uc *usecase.CatUsecase) {
+func NewCatHandler(r *gin.Engine, uc domain.Usecase) {
 	h := &CatHandler{usecase: uc}
 
 	group := r.Group("/cats")
@@ -21,30 +25,65 @@ func NewCatHandler(r *gin.Engine, uc *usecase.CatUsecase) {
 		group.POST("", h.Create)
 		group.GET("/:id", h.GetByID)
 		group.PUT("/:id/salary", h.UpdateSalary)
+		group.GET("/:id/salary/history", h.SalaryHistory)
+		group.GET("/payroll", h.Payroll)
+		group.GET("/search", h.Search)
 		group.DELETE("/:id", h.Delete)
 		group.GET("", h.List)
 	}
+	r.GET("/export/cats", h.Export)
 }
 
 type CatRequest struct {
-	Name              string  `json:"name" binding:"required,min=2,max=50" example:"Tom"`
-	YearsOfExperience int     `json:"years_of_experience" binding:"required,gte=0,lte=50" example:"3"`
-	Breed             string  `json:"breed" binding:"required" example:"Siamese"`
-	Salary            float64 `json:"salary" binding:"required,gte=0" example:"1200.50"`
+	Name              string       `json:"name" binding:"required,min=2,max=50" example:"Tom"`
+	YearsOfExperience int          `json:"years_of_experience" binding:"required,gte=0,lte=50" example:"3"`
+	Breed             string       `json:"breed" binding:"required" example:"Siamese"`
+	Salary            money.Amount `json:"salary" binding:"required,gte=0" swaggertype:"number" example:"1200.50"`
 }
 
 // swagger:model UpdateSalaryRequest
 type UpdateSalaryRequest struct {
-	Salary float64 `json:"salary" binding:"required,gte=0" example:"1300.75"`
+	Salary        money.Amount `json:"salary" binding:"required,gte=0" swaggertype:"number" example:"1300.75"`
+	Reason        string       `json:"reason" binding:"max=500" example:"Annual review"`
+	EffectiveFrom string       `json:"effective_from,omitempty" example:"2024-01-01"`
 }
 
 // swagger:model CatResponse
 type CatResponse struct {
-	ID                int64   `json:"id" example:"1"`
-	Name              string  `json:"name" example:"Tom"`
-	YearsOfExperience int     `json:"years_of_experience" example:"3"`
-	Breed             string  `json:"breed" example:"Siamese"`
-	Salary            float64 `json:"salary" example:"1200.50"`
+	ID                int64        `json:"id" example:"1"`
+	Name              string       `json:"name" example:"Tom"`
+	YearsOfExperience int          `json:"years_of_experience" example:"3"`
+	Breed             string       `json:"breed" example:"Siamese"`
+	Salary            money.Amount `json:"salary" swaggertype:"number" example:"1200.50"`
+}
+
+func parseID(c *gin.Context) (int64, error) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if id <= 0 {
+		return 0, errors.New("id must be positive")
+	}
+	return id, nil
+}
+
+const dateLayout = "2006-01-02"
+
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrCatNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrEffectiveDateOrder), errors.Is(err, domain.ErrCatOnMission):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrNegativeSalary), errors.Is(err, domain.ErrFutureEffectiveDate),
+		errors.Is(err, domain.ErrInvalidPeriod), errors.Is(err, domain.ErrInvalidRange),
+		errors.Is(err, domain.ErrEmptyQuery), errors.Is(err, domain.ErrQueryTooLong),
+		errors.Is(err, export.ErrUnknownFormat):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
 }
 
 func toCatResponse(c *domain.Cat) *CatResponse {
@@ -63,8 +102,11 @@ func toCatResponse(c *domain.Cat) *CatResponse {
 // @Accept json
 // @Produce json
 // @Param cat body CatRequest true "Cat details"
+// @Param Idempotency-Key header string false "Replays the stored response when a request is retried with the same key"
 // @Success 201 {object} CatResponse
 // @Failure 400 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 429 {object} map[string]string "Rate limit exceeded, see Retry-After"
 // @Router /cats [post]
 func (h *CatHandler) Create(c *gin.Context) {
 	var req CatRequest
@@ -82,6 +124,12 @@ func (h *CatHandler) Create(c *gin.Context) {
 	}
 
 	if err := h.usecase.Create(c.Request.Context(), cat); err != nil {
+		var limited *ratelimit.LimitedError
+		if errors.As(err, &limited) {
+			ratelimit.Reject(c, limited.RetryAfter)
+			c.Error(err)
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		c.Error(err)
 		return
@@ -100,8 +148,8 @@ func (h *CatHandler) Create(c *gin.Context) {
 // @Failure 404 {object} map[string]string
 // @Router /cats/{id} [get]
 func (h *CatHandler) GetByID(c *gin.Context) {
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err != nil || id <= 0 {
+	id, err := parseID(c)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		c.Error(err)
 		return
@@ -122,13 +170,16 @@ func (h *CatHandler) GetByID(c *gin.Context) {
 // @Tags cats
 // @Accept json
 // @Param id path int true "Cat ID"
+// @Description Record a salary change in the cat's ledger. It takes effect today unless an earlier effective_from date is given; it cannot predate the latest recorded change.
 // @Param salary body UpdateSalaryRequest true "New salary"
 // @Success 204 "No Content"
 // @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Effective date before the latest change"
 // @Router /cats/{id}/salary [put]
 func (h *CatHandler) UpdateSalary(c *gin.Context) {
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err != nil || id <= 0 {
+	id, err := parseID(c)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		c.Error(err)
 		return
@@ -141,8 +192,22 @@ func (h *CatHandler) UpdateSalary(c *gin.Context) {
 		return
 	}
 
-	if err := h.usecase.UpdateSalary(c.Request.Context(), id, req.Salary); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	change := &domain.SalaryChange{
+		CatID:     id,
+		NewSalary: req.Salary,
+		Reason:    req.Reason,
+	}
+	if req.EffectiveFrom != "" {
+		change.EffectiveFrom, err = time.Parse(dateLayout, req.EffectiveFrom)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "effective_from must be a date (YYYY-MM-DD)"})
+			c.Error(err)
+			return
+		}
+	}
+
+	if err := h.usecase.UpdateSalary(c.Request.Context(), change); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		c.Error(err)
 		return
 	}
@@ -152,21 +217,24 @@ func (h *CatHandler) UpdateSalary(c *gin.Context) {
 
 // Delete godoc
 // @Summary Delete a cat by ID
+// @Description The cat is marked deleted and its salary history kept. A cat on a mission that is not closed cannot be deleted.
 // @Tags cats
 // @Param id path int true "Cat ID"
 // @Success 204 "No Content"
 // @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /cats/{id} [delete]
 func (h *CatHandler) Delete(c *gin.Context) {
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err != nil || id <= 0 {
+	id, err := parseID(c)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		c.Error(err)
 		return
 	}
 
 	if err := h.usecase.Delete(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		c.Error(err)
 		return
 	}
@@ -196,3 +264,138 @@ func (h *CatHandler) List(c *gin.Context) {
 
 	c.JSON(http.StatusOK, res)
 }
+
+var catColumns = []export.Column[*CatResponse]{
+	{Name: "id", Value: func(c *CatResponse) string { return strconv.FormatInt(c.ID, 10) }},
+	{Name: "name", Value: func(c *CatResponse) string { return c.Name }},
+	{Name: "years_of_experience", Value: func(c *CatResponse) string { return strconv.Itoa(c.YearsOfExperience) }},
+	{Name: "breed", Value: func(c *CatResponse) string { return c.Breed }},
+	{Name: "salary", Value: func(c *CatResponse) string { return c.Salary.String() }},
+}
+
+// Export godoc
+// @Summary Export cats
+// @Description Download every cat as CSV (with a header row), JSON Lines or a JSON array, streamed from the database in ID order.
+// @Tags cats
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce json
+// @Param format query string false "Output format" Enums(csv, jsonl, json) default(csv)
+// @Success 200 {array} CatResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /export/cats [get]
+func (h *CatHandler) Export(c *gin.Context) {
+	err := export.Serve(c, "cats", catColumns, func(write func(*CatResponse) error) error {
+		return h.usecase.Export(c.Request.Context(), func(cat *domain.Cat) error {
+			return write(toCatResponse(cat))
+		})
+	})
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+	}
+}
+
+// SalaryHistory godoc
+// @Summary Get a cat's salary history
+// @Description List the salary ledger of a cat, oldest change first.
+// @Tags cats
+// @Produce json
+// @Param id path int true "Cat ID"
+// @Success 200 {array} domain.SalaryChange
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /cats/{id}/salary/history [get]
+func (h *CatHandler) SalaryHistory(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		c.Error(err)
+		return
+	}
+
+	history, err := h.usecase.SalaryHistory(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// Payroll godoc
+// @Summary Payroll report
+// @Description Total monthly salaries per period over the whole months between from and to. Each cat counts at the salary in effect on the last day of every month.
+// @Tags cats
+// @Produce json
+// @Param from query string true "First month, as a date (YYYY-MM-DD)"
+// @Param to query string true "Last month, as a date (YYYY-MM-DD)"
+// @Param period query string false "Grouping period" Enums(month, quarter, year) default(month)
+// @Success 200 {object} domain.PayrollReport
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /cats/payroll [get]
+func (h *CatHandler) Payroll(c *gin.Context) {
+	from, errFrom := time.Parse(dateLayout, c.Query("from"))
+	to, errTo := time.Parse(dateLayout, c.Query("to"))
+	if err := errors.Join(errFrom, errTo); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be dates (YYYY-MM-DD)"})
+		c.Error(err)
+		return
+	}
+	period := domain.PayrollPeriod(c.DefaultQuery("period", string(domain.PayrollMonth)))
+
+	report, err := h.usecase.Payroll(c.Request.Context(), from, to, period)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// SearchResult mirrors domain.SearchResult with the API's cat representation.
+type SearchResult struct {
+	Cat        *CatResponse      `json:"cat"`
+	Rank       float64           `json:"rank" example:"0.87"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+// Search godoc
+// @Summary Search cats
+// @Description Find cats by partial name or breed. Words match by prefix, and near misses match by trigram similarity. Results are ranked best first, with matched words in name and breed wrapped in <mark> tags.
+// @Tags cats
+// @Produce json
+// @Param q query string true "Search text"
+// @Param limit query int false "Maximum number of results, at most 100" default(20)
+// @Success 200 {array} SearchResult
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /cats/search [get]
+func (h *CatHandler) Search(c *gin.Context) {
+	limit := 0
+	if v := c.Query("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			c.Error(err)
+			return
+		}
+	}
+
+	results, err := h.usecase.Search(c.Request.Context(), c.Query("q"), limit)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	res := make([]SearchResult, 0, len(results))
+	for _, r := range results {
+		res = append(res, SearchResult{Cat: toCatResponse(r.Cat), Rank: r.Rank, Highlights: r.Highlights})
+	}
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,635 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	handler "go-test-assesment/internal/cat/delivery/http"
+	cat "go-test-assesment/internal/cat/domain"
+	"go-test-assesment/pkg/money"
+	"go-test-assesment/pkg/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeCatUsecase struct {
+	createFn       func(ctx context.Context, c *cat.Cat) error
+	getByIDFn      func(ctx context.Context, id int64) (*cat.Cat, error)
+	updateSalaryFn func(ctx context.Context, change *cat.SalaryChange) error
+	historyFn      func(ctx context.Context, catID int64) ([]*cat.SalaryChange, error)
+	payrollFn      func(ctx context.Context, from, to time.Time, period cat.PayrollPeriod) (*cat.PayrollReport, error)
+	searchFn       func(ctx context.Context, query string, limit int) ([]*cat.SearchResult, error)
+	deleteFn       func(ctx context.Context, id int64) error
+	listFn         func(ctx context.Context) ([]*cat.Cat, error)
+	exportFn       func(ctx context.Context, fn func(*cat.Cat) error) error
+}
+
+func (f *fakeCatUsecase) Create(ctx context.Context, c *cat.Cat) error {
+	return f.createFn(ctx, c)
+}
+func (f *fakeCatUsecase) GetByID(ctx context.Context, id int64) (*cat.Cat, error) {
+	return f.getByIDFn(ctx, id)
+}
+func (f *fakeCatUsecase) UpdateSalary(ctx context.Context, change *cat.SalaryChange) error {
+	return f.updateSalaryFn(ctx, change)
+}
+func (f *fakeCatUsecase) SalaryHistory(ctx context.Context, catID int64) ([]*cat.SalaryChange, error) {
+	return f.historyFn(ctx, catID)
+}
+func (f *fakeCatUsecase) Search(ctx context.Context, query string, limit int) ([]*cat.SearchResult, error) {
+	return f.searchFn(ctx, query, limit)
+}
+func (f *fakeCatUsecase) Payroll(ctx context.Context, from, to time.Time, period cat.PayrollPeriod) (*cat.PayrollReport, error) {
+	return f.payrollFn(ctx, from, to, period)
+}
+func (f *fakeCatUsecase) Delete(ctx context.Context, id int64) error {
+	return f.deleteFn(ctx, id)
+}
+func (f *fakeCatUsecase) List(ctx context.Context) ([]*cat.Cat, error) {
+	return f.listFn(ctx)
+}
+func (f *fakeCatUsecase) Export(ctx context.Context, fn func(*cat.Cat) error) error {
+	return f.exportFn(ctx, fn)
+}
+
+func newRouter(uc cat.Usecase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handler.NewCatHandler(r, uc)
+	return r
+}
+
+func doRequest(r http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCatHandler_Create(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		createErr  error
+		wantStatus int
+		wantCalled bool
+	}{
+		{
+			name:       "success",
+			body:       `{"name":"Tom","years_of_experience":3,"breed":"Siamese","salary":1200.5}`,
+			wantStatus: http.StatusCreated,
+			wantCalled: true,
+		},
+		{
+			name:       "malformed json",
+			body:       `{"name":`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing name",
+			body:       `{"years_of_experience":3,"breed":"Siamese","salary":1200.5}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "name too short",
+			body:       `{"name":"T","years_of_experience":3,"breed":"Siamese","salary":1200.5}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "experience out of range",
+			body:       `{"name":"Tom","years_of_experience":51,"breed":"Siamese","salary":1200.5}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "negative salary",
+			body:       `{"name":"Tom","years_of_experience":3,"breed":"Siamese","salary":-1}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "salary too large",
+			body:       `{"name":"Tom","years_of_experience":3,"breed":"Siamese","salary":100000000}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "usecase error",
+			body:       `{"name":"Tom","years_of_experience":3,"breed":"Unknown","salary":1200.5}`,
+			createErr:  errors.New("invalid breed"),
+			wantStatus: http.StatusBadRequest,
+			wantCalled: true,
+		},
+		{
+			name:       "breed lookups rate limited",
+			body:       `{"name":"Tom","years_of_experience":3,"breed":"Siamese","salary":1200.5}`,
+			createErr:  fmt.Errorf("error validating breed: %w", &ratelimit.LimitedError{RetryAfter: 1500 * time.Millisecond}),
+			wantStatus: http.StatusTooManyRequests,
+			wantCalled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			uc := &fakeCatUsecase{
+				createFn: func(ctx context.Context, c *cat.Cat) error {
+					called = true
+					c.ID = 7
+					return tt.createErr
+				},
+			}
+
+			w := doRequest(newRouter(uc), http.MethodPost, "/cats", tt.body)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusTooManyRequests && w.Header().Get("Retry-After") != "2" {
+				t.Errorf("Retry-After = %q, want 2", w.Header().Get("Retry-After"))
+			}
+			if called != tt.wantCalled {
+				t.Errorf("usecase called = %v, want %v", called, tt.wantCalled)
+			}
+			if tt.wantStatus == http.StatusCreated {
+				var resp handler.CatResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("decode response: %v", err)
+				}
+				if resp.ID != 7 || resp.Name != "Tom" || resp.Breed != "Siamese" || resp.Salary != money.MustParse("1200.50") {
+					t.Errorf("unexpected response: %+v", resp)
+				}
+			}
+		})
+	}
+}
+
+func TestCatHandler_GetByID(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		cat        *cat.Cat
+		repoErr    error
+		wantStatus int
+	}{
+		{
+			name:       "success",
+			path:       "/cats/1",
+			cat:        &cat.Cat{ID: 1, Name: "Tom", Breed: "Siamese", Salary: 1500, YearsOfExperience: 3},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "non numeric id",
+			path:       "/cats/abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "zero id",
+			path:       "/cats/0",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "not found",
+			path:       "/cats/2",
+			repoErr:    errors.New("no rows in result set"),
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &fakeCatUsecase{
+				getByIDFn: func(ctx context.Context, id int64) (*cat.Cat, error) {
+					return tt.cat, tt.repoErr
+				},
+			}
+
+			w := doRequest(newRouter(uc), http.MethodGet, tt.path, "")
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK {
+				var resp handler.CatResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("decode response: %v", err)
+				}
+				if resp.ID != tt.cat.ID || resp.YearsOfExperience != tt.cat.YearsOfExperience {
+					t.Errorf("unexpected response: %+v", resp)
+				}
+			}
+		})
+	}
+}
+
+func TestCatHandler_UpdateSalary(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		ucErr      error
+		wantStatus int
+		wantSalary money.Amount
+		wantChange *cat.SalaryChange
+	}{
+		{
+			name:       "success",
+			path:       "/cats/1/salary",
+			body:       `{"salary":1300.75}`,
+			wantStatus: http.StatusNoContent,
+			wantSalary: money.MustParse("1300.75"),
+		},
+		{
+			name:       "with reason and effective date",
+			path:       "/cats/1/salary",
+			body:       `{"salary":"1300.75","reason":"Annual review","effective_from":"2024-01-01"}`,
+			wantStatus: http.StatusNoContent,
+			wantSalary: money.MustParse("1300.75"),
+			wantChange: &cat.SalaryChange{
+				CatID:         1,
+				NewSalary:     money.MustParse("1300.75"),
+				Reason:        "Annual review",
+				EffectiveFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "invalid effective date",
+			path:       "/cats/1/salary",
+			body:       `{"salary":1300.75,"effective_from":"01/01/2024"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "sub-cent salary",
+			path:       "/cats/1/salary",
+			body:       `{"salary":1300.755}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "cat not found",
+			path:       "/cats/1/salary",
+			body:       `{"salary":1300.75}`,
+			ucErr:      cat.ErrCatNotFound,
+			wantStatus: http.StatusNotFound,
+			wantSalary: money.MustParse("1300.75"),
+		},
+		{
+			name:       "backdated before latest change",
+			path:       "/cats/1/salary",
+			body:       `{"salary":1300.75}`,
+			ucErr:      cat.ErrEffectiveDateOrder,
+			wantStatus: http.StatusConflict,
+			wantSalary: money.MustParse("1300.75"),
+		},
+		{
+			name:       "invalid id",
+			path:       "/cats/-1/salary",
+			body:       `{"salary":1300.75}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "malformed json",
+			path:       "/cats/1/salary",
+			body:       `salary=1`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing salary",
+			path:       "/cats/1/salary",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "usecase error",
+			path:       "/cats/1/salary",
+			body:       `{"salary":1300.75}`,
+			ucErr:      errors.New("db update error"),
+			wantStatus: http.StatusInternalServerError,
+			wantSalary: money.MustParse("1300.75"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotSalary money.Amount
+			uc := &fakeCatUsecase{
+				updateSalaryFn: func(ctx context.Context, change *cat.SalaryChange) error {
+					gotSalary = change.NewSalary
+					if tt.wantChange != nil && *change != *tt.wantChange {
+						t.Errorf("change passed to usecase = %+v, want %+v", change, tt.wantChange)
+					}
+					return tt.ucErr
+				},
+			}
+
+			w := doRequest(newRouter(uc), http.MethodPut, tt.path, tt.body)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if gotSalary != tt.wantSalary {
+				t.Errorf("salary passed to usecase = %v, want %v", gotSalary, tt.wantSalary)
+			}
+		})
+	}
+}
+
+func TestCatHandler_Delete(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		ucErr      error
+		wantStatus int
+	}{
+		{
+			name:       "success",
+			path:       "/cats/1",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "invalid id",
+			path:       "/cats/one",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "not found",
+			path:       "/cats/1",
+			ucErr:      cat.ErrCatNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "on a mission",
+			path:       "/cats/1",
+			ucErr:      cat.ErrCatOnMission,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "usecase error",
+			path:       "/cats/1",
+			ucErr:      errors.New("db delete error"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &fakeCatUsecase{
+				deleteFn: func(ctx context.Context, id int64) error {
+					return tt.ucErr
+				},
+			}
+
+			w := doRequest(newRouter(uc), http.MethodDelete, tt.path, "")
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCatHandler_List(t *testing.T) {
+	tests := []struct {
+		name       string
+		cats       []*cat.Cat
+		ucErr      error
+		wantStatus int
+		wantCount  int
+	}{
+		{
+			name: "success",
+			cats: []*cat.Cat{
+				{ID: 1, Name: "Tom", Breed: "Siamese", Salary: 1500},
+				{ID: 2, Name: "Jerry", Breed: "Persian", Salary: 1800},
+			},
+			wantStatus: http.StatusOK,
+			wantCount:  2,
+		},
+		{
+			name:       "usecase error",
+			ucErr:      errors.New("db error"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &fakeCatUsecase{
+				listFn: func(ctx context.Context) ([]*cat.Cat, error) {
+					return tt.cats, tt.ucErr
+				},
+			}
+
+			w := doRequest(newRouter(uc), http.MethodGet, "/cats", "")
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK {
+				var resp []handler.CatResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("decode response: %v", err)
+				}
+				if len(resp) != tt.wantCount {
+					t.Errorf("cats count = %d, want %d", len(resp), tt.wantCount)
+				}
+			}
+		})
+	}
+}
+
+func TestCatHandler_Export(t *testing.T) {
+	cats := []*cat.Cat{
+		{ID: 1, Name: "Tom", YearsOfExperience: 3, Breed: "Siamese", Salary: money.MustParse("1500.5")},
+		{ID: 2, Name: "Jerry, Jr.", YearsOfExperience: 1, Breed: "Persian", Salary: money.MustParse("1800")},
+	}
+	tests := []struct {
+		name            string
+		query           string
+		ucErr           error
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "csv by default",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8",
+			wantBody: "id,name,years_of_experience,breed,salary\n" +
+				"1,Tom,3,Siamese,1500.50\n" +
+				"2,\"Jerry, Jr.\",1,Persian,1800.00\n",
+		},
+		{
+			name:            "json lines",
+			query:           "?format=jsonl",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/x-ndjson",
+			wantBody: `{"id":1,"name":"Tom","years_of_experience":3,"breed":"Siamese","salary":1500.50}` + "\n" +
+				`{"id":2,"name":"Jerry, Jr.","years_of_experience":1,"breed":"Persian","salary":1800.00}` + "\n",
+		},
+		{
+			name:       "unknown format",
+			query:      "?format=xml",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "error before the first row",
+			ucErr:      errors.New("db error"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &fakeCatUsecase{
+				exportFn: func(ctx context.Context, fn func(*cat.Cat) error) error {
+					if tt.ucErr != nil {
+						return tt.ucErr
+					}
+					for _, c := range cats {
+						if err := fn(c); err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+			}
+
+			w := doRequest(newRouter(uc), http.MethodGet, "/export/cats"+tt.query, "")
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				if cd := w.Header().Get("Content-Disposition"); cd != "" {
+					t.Errorf("Content-Disposition = %q on an error", cd)
+				}
+				return
+			}
+			if ct := w.Header().Get("Content-Type"); ct != tt.wantContentType {
+				t.Errorf("Content-Type = %q, want %q", ct, tt.wantContentType)
+			}
+			if got := w.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestCatHandler_SalaryHistory(t *testing.T) {
+	old := money.MustParse("1000")
+	uc := &fakeCatUsecase{
+		historyFn: func(ctx context.Context, catID int64) ([]*cat.SalaryChange, error) {
+			if catID != 1 {
+				return nil, cat.ErrCatNotFound
+			}
+			return []*cat.SalaryChange{
+				{ID: 1, CatID: 1, NewSalary: old, Reason: "initial salary"},
+				{ID: 2, CatID: 1, OldSalary: &old, NewSalary: money.MustParse("1250.50"), Reason: "promotion"},
+			}, nil
+		},
+	}
+	r := newRouter(uc)
+
+	w := doRequest(r, http.MethodGet, "/cats/1/salary/history", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var resp []map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp) != 2 || resp[1]["old_salary"] != 1000.0 || resp[1]["new_salary"] != 1250.5 {
+		t.Errorf("unexpected response: %s", w.Body.String())
+	}
+	if _, ok := resp[0]["old_salary"]; ok {
+		t.Errorf("first entry should have no old_salary: %s", w.Body.String())
+	}
+
+	if w := doRequest(r, http.MethodGet, "/cats/2/salary/history", ""); w.Code != http.StatusNotFound {
+		t.Errorf("unknown cat status = %d", w.Code)
+	}
+}
+
+func TestCatHandler_Payroll(t *testing.T) {
+	var gotPeriod cat.PayrollPeriod
+	uc := &fakeCatUsecase{
+		payrollFn: func(ctx context.Context, from, to time.Time, period cat.PayrollPeriod) (*cat.PayrollReport, error) {
+			gotPeriod = period
+			if period == "week" {
+				return nil, cat.ErrInvalidPeriod
+			}
+			return &cat.PayrollReport{From: from, To: to, Period: period, Total: money.MustParse("10.10")}, nil
+		},
+	}
+	r := newRouter(uc)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantPeriod cat.PayrollPeriod
+	}{
+		{"default period", "from=2024-01-01&to=2024-12-31", http.StatusOK, cat.PayrollMonth},
+		{"quarter", "from=2024-01-01&to=2024-12-31&period=quarter", http.StatusOK, cat.PayrollQuarter},
+		{"invalid period", "from=2024-01-01&to=2024-12-31&period=week", http.StatusBadRequest, "week"},
+		{"missing range", "from=2024-01-01", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotPeriod = ""
+			w := doRequest(r, http.MethodGet, "/cats/payroll?"+tt.query, "")
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if gotPeriod != tt.wantPeriod {
+				t.Errorf("period = %q, want %q", gotPeriod, tt.wantPeriod)
+			}
+		})
+	}
+}
+
+func TestCatHandler_Search(t *testing.T) {
+	var gotQuery string
+	var gotLimit int
+	uc := &fakeCatUsecase{
+		searchFn: func(ctx context.Context, query string, limit int) ([]*cat.SearchResult, error) {
+			gotQuery, gotLimit = query, limit
+			if query == "" {
+				return nil, cat.ErrEmptyQuery
+			}
+			return []*cat.SearchResult{{
+				Cat:        &cat.Cat{ID: 3, Name: "Tom", Breed: "Siamese", Salary: money.MustParse("1500")},
+				Rank:       0.9,
+				Highlights: map[string]string{"name": "Tom", "breed": "<mark>Siamese</mark>"},
+			}}, nil
+		},
+	}
+	r := newRouter(uc)
+
+	w := doRequest(r, http.MethodGet, "/cats/search?q=sia&limit=5", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if gotQuery != "sia" || gotLimit != 5 {
+		t.Errorf("usecase got (%q, %d)", gotQuery, gotLimit)
+	}
+	var resp []handler.SearchResult
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp) != 1 || resp[0].Cat.ID != 3 || resp[0].Highlights["breed"] != "<mark>Siamese</mark>" {
+		t.Errorf("unexpected response: %s", w.Body.String())
+	}
+
+	if w := doRequest(r, http.MethodGet, "/cats/search", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("empty query status = %d", w.Code)
+	}
+	if w := doRequest(r, http.MethodGet, "/cats/search?q=tom&limit=x", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid limit status = %d", w.Code)
+	}
+}
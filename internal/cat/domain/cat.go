@@ -1,19 +1,124 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-test-assesment/pkg/money"
+)
+
+var (
+	ErrCatNotFound         = errors.New("cat not found")
+	ErrCatOnMission        = errors.New("cat is on a mission that is not closed")
+	ErrNegativeSalary      = errors.New("salary cannot be negative")
+	ErrFutureEffectiveDate = errors.New("effective date cannot be in the future")
+	// ErrEffectiveDateOrder is returned for a change dated before the latest
+	// recorded one, which would rewrite the salary history.
+	ErrEffectiveDateOrder = errors.New("effective date is before the latest salary change")
+	ErrInvalidPeriod      = errors.New("period must be one of month, quarter or year")
+	ErrInvalidRange       = errors.New("invalid payroll date range")
+	ErrEmptyQuery         = errors.New("search query cannot be empty")
+	ErrQueryTooLong       = errors.New("search query is too long")
+)
 
 type Cat struct {
-	ID                int64   `json:"id"`
-	Name              string  `json:"name"`
-	YearsOfExperience int     `json:"years_of_experience"`
-	Breed             string  `json:"breed"`
-	Salary            float64 `json:"salary"`
+	ID                int64        `json:"id"`
+	Name              string       `json:"name"`
+	YearsOfExperience int          `json:"years_of_experience"`
+	Breed             string       `json:"breed"`
+	Salary            money.Amount `json:"salary" swaggertype:"number"`
+}
+
+// SalaryChange is an entry in a cat's salary ledger. The first entry is
+// recorded when the cat is created and has no OldSalary.
+type SalaryChange struct {
+	ID            int64         `json:"id"`
+	CatID         int64         `json:"cat_id"`
+	OldSalary     *money.Amount `json:"old_salary,omitempty" swaggertype:"number"`
+	NewSalary     money.Amount  `json:"new_salary" swaggertype:"number"`
+	EffectiveFrom time.Time     `json:"effective_from"`
+	Reason        string        `json:"reason"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+type PayrollPeriod string
+
+const (
+	PayrollMonth   PayrollPeriod = "month"
+	PayrollQuarter PayrollPeriod = "quarter"
+	PayrollYear    PayrollPeriod = "year"
+)
+
+// Months is the length of the period in months, or 0 if it is unknown.
+func (p PayrollPeriod) Months() int {
+	switch p {
+	case PayrollMonth:
+		return 1
+	case PayrollQuarter:
+		return 3
+	case PayrollYear:
+		return 12
+	default:
+		return 0
+	}
+}
+
+type PayrollLine struct {
+	PeriodStart time.Time    `json:"period_start"`
+	PeriodEnd   time.Time    `json:"period_end"`
+	Total       money.Amount `json:"total" swaggertype:"number"`
+	Headcount   int          `json:"headcount"`
+}
+
+type PayrollReport struct {
+	From   time.Time     `json:"from"`
+	To     time.Time     `json:"to"`
+	Period PayrollPeriod `json:"period"`
+	Lines  []PayrollLine `json:"lines"`
+	Total  money.Amount  `json:"total" swaggertype:"number"`
+}
+
+// SearchResult is a cat matching a search, best matches having the highest
+// Rank. Highlights holds the name and breed, HTML-escaped, with matched words
+// wrapped in <mark> tags.
+type SearchResult struct {
+	Cat        *Cat              `json:"cat"`
+	Rank       float64           `json:"rank"`
+	Highlights map[string]string `json:"highlights"`
 }
 
 type Repository interface {
+	// Store creates the cat along with the first entry of its salary ledger.
 	Store(ctx context.Context, c *Cat) error
 	GetByID(ctx context.Context, id int64) (*Cat, error)
-	UpdateSalary(ctx context.Context, id int64, salary float64) error
+	// UpdateSalary records change and makes its salary the current one.
+	UpdateSalary(ctx context.Context, change *SalaryChange) error
+	// Delete hides the cat from everything but its salary history and past
+	// payrolls, keeping its ledger. It returns ErrCatNotFound for a missing
+	// or deleted cat and ErrCatOnMission for one on an open mission.
+	Delete(ctx context.Context, id int64) error
+	List(ctx context.Context) ([]*Cat, error)
+	// Each calls fn for every cat in ID order as rows are read, stopping at
+	// the first error fn returns.
+	Each(ctx context.Context, fn func(*Cat) error) error
+	SalaryHistory(ctx context.Context, catID int64) ([]*SalaryChange, error)
+	// Payroll totals salaries for every month between from and to, grouped
+	// into periods.
+	Payroll(ctx context.Context, from, to time.Time, period PayrollPeriod) ([]PayrollLine, error)
+	// Search matches query against name and breed, by words and their
+	// prefixes as well as by trigram similarity to tolerate typos.
+	Search(ctx context.Context, query string, limit int) ([]*SearchResult, error)
+}
+
+type Usecase interface {
+	Create(ctx context.Context, c *Cat) error
+	GetByID(ctx context.Context, id int64) (*Cat, error)
+	UpdateSalary(ctx context.Context, change *SalaryChange) error
 	Delete(ctx context.Context, id int64) error
 	List(ctx context.Context) ([]*Cat, error)
+	Export(ctx context.Context, fn func(*Cat) error) error
+	SalaryHistory(ctx context.Context, catID int64) ([]*SalaryChange, error)
+	Payroll(ctx context.Context, from, to time.Time, period PayrollPeriod) (*PayrollReport, error)
+	Search(ctx context.Context, query string, limit int) ([]*SearchResult, error)
 }
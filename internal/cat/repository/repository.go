@@ -2,8 +2,14 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"go-test-assesment/internal/cat/domain"
+	"go-test-assesment/pkg/money"
+	"strings"
+	"time"
+	"unicode"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -16,36 +22,99 @@ func NewPostgresCatRepository(db *pgxpool.Pool) domain.Repository {
 }
 
 func (r *postgresCatRepository) Store(ctx context.Context, c *domain.Cat) error {
-	query := `INSERT INTO cats (name, years_of_experience, breed, salary) VALUES ($1, $2, $3, $4) RETURNING id`
-	return r.db.QueryRow(ctx, query, c.Name, c.YearsOfExperience, c.Breed, c.Salary).Scan(&c.ID)
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		query := `INSERT INTO cats (name, years_of_experience, breed, salary) VALUES ($1, $2, $3, $4) RETURNING id`
+		if err := tx.QueryRow(ctx, query, c.Name, c.YearsOfExperience, c.Breed, c.Salary).Scan(&c.ID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO salary_changes (cat_id, old_salary, new_salary, effective_from, reason)
+			VALUES ($1, NULL, $2, CURRENT_DATE, 'initial salary')`, c.ID, c.Salary)
+		return err
+	})
 }
 
 func (r *postgresCatRepository) GetByID(ctx context.Context, id int64) (*domain.Cat, error) {
-	query := `SELECT id, name, years_of_experience, breed, salary FROM cats WHERE id = $1`
+	query := `SELECT id, name, years_of_experience, breed, salary FROM cats WHERE id = $1 AND deleted_at IS NULL`
 	row := r.db.QueryRow(ctx, query, id)
 
 	var c domain.Cat
 	err := row.Scan(&c.ID, &c.Name, &c.YearsOfExperience, &c.Breed, &c.Salary)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrCatNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 	return &c, nil
 }
 
-func (r *postgresCatRepository) UpdateSalary(ctx context.Context, id int64, salary float64) error {
-	query := `UPDATE cats SET salary = $1 WHERE id = $2`
-	_, err := r.db.Exec(ctx, query, salary, id)
-	return err
+func (r *postgresCatRepository) UpdateSalary(ctx context.Context, change *domain.SalaryChange) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		// Locking the cat serializes concurrent changes to its ledger.
+		var current money.Amount
+		err := tx.QueryRow(ctx, `SELECT salary FROM cats WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, change.CatID).
+			Scan(&current)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrCatNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var latest *time.Time
+		err = tx.QueryRow(ctx, `SELECT max(effective_from) FROM salary_changes WHERE cat_id = $1`, change.CatID).
+			Scan(&latest)
+		if err != nil {
+			return err
+		}
+		if latest != nil && change.EffectiveFrom.Before(*latest) {
+			return domain.ErrEffectiveDateOrder
+		}
+
+		change.OldSalary = &current
+		query := `
+			INSERT INTO salary_changes (cat_id, old_salary, new_salary, effective_from, reason)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, created_at`
+		err = tx.QueryRow(ctx, query, change.CatID, change.OldSalary, change.NewSalary, change.EffectiveFrom, change.Reason).
+			Scan(&change.ID, &change.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `UPDATE cats SET salary = $1 WHERE id = $2`, change.NewSalary, change.CatID)
+		return err
+	})
 }
 
+// Delete marks the cat as deleted rather than removing it, so that its
+// salary ledger is kept.
 func (r *postgresCatRepository) Delete(ctx context.Context, id int64) error {
-	query := `DELETE FROM cats WHERE id = $1`
-	_, err := r.db.Exec(ctx, query, id)
-	return err
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		// Assignments lock the cat too, so none can slip in before the
+		// delete.
+		var onMission bool
+		err := tx.QueryRow(ctx, `
+			SELECT EXISTS (SELECT 1 FROM missions m
+			               WHERE m.cat_id = c.id AND m.state IN ('draft', 'assigned', 'in_progress'))
+			FROM cats c WHERE c.id = $1 AND c.deleted_at IS NULL FOR UPDATE`, id).Scan(&onMission)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrCatNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if onMission {
+			return domain.ErrCatOnMission
+		}
+		_, err = tx.Exec(ctx, `UPDATE cats SET deleted_at = now() WHERE id = $1`, id)
+		return err
+	})
 }
 
 func (r *postgresCatRepository) List(ctx context.Context) ([]*domain.Cat, error) {
-	query := `SELECT id, name, years_of_experience, breed, salary FROM cats`
+	query := `SELECT id, name, years_of_experience, breed, salary FROM cats WHERE deleted_at IS NULL`
 	rows, err := r.db.Query(ctx, query)
 	if err != nil {
 		return nil, err
@@ -64,3 +133,149 @@ func (r *postgresCatRepository) List(ctx context.Context) ([]*domain.Cat, error)
 
 	return cats, nil
 }
+
+func (r *postgresCatRepository) Each(ctx context.Context, fn func(*domain.Cat) error) error {
+	query := `SELECT id, name, years_of_experience, breed, salary FROM cats WHERE deleted_at IS NULL ORDER BY id`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c domain.Cat
+		if err := rows.Scan(&c.ID, &c.Name, &c.YearsOfExperience, &c.Breed, &c.Salary); err != nil {
+			return err
+		}
+		if err := fn(&c); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// SalaryHistory also returns the ledger of a deleted cat.
+func (r *postgresCatRepository) SalaryHistory(ctx context.Context, catID int64) ([]*domain.SalaryChange, error) {
+	var exists bool
+	if err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM cats WHERE id = $1)`, catID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, domain.ErrCatNotFound
+	}
+
+	query := `
+		SELECT id, cat_id, old_salary, new_salary, effective_from, reason, created_at
+		FROM salary_changes
+		WHERE cat_id = $1
+		ORDER BY effective_from, id`
+	rows, err := r.db.Query(ctx, query, catID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []*domain.SalaryChange{}
+	for rows.Next() {
+		var sc domain.SalaryChange
+		err := rows.Scan(&sc.ID, &sc.CatID, &sc.OldSalary, &sc.NewSalary, &sc.EffectiveFrom, &sc.Reason, &sc.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, &sc)
+	}
+	return changes, rows.Err()
+}
+
+// Payroll counts each cat at the salary in effect on the last day of every
+// month, so a change takes effect for the whole month it falls in. A deleted
+// cat counts until the month it was deleted in.
+func (r *postgresCatRepository) Payroll(ctx context.Context, from, to time.Time, period domain.PayrollPeriod) ([]domain.PayrollLine, error) {
+	query := `
+		WITH months AS (
+			SELECT (m + interval '1 month' - interval '1 day')::date AS month_end, m::date AS month_start
+			FROM generate_series(date_trunc('month', $1::date), date_trunc('month', $2::date), interval '1 month') AS m
+		)
+		SELECT date_trunc($3, months.month_start)::date AS period_start,
+		       COALESCE(SUM(rate.new_salary), 0),
+		       COUNT(DISTINCT rate.cat_id)
+		FROM months
+		LEFT JOIN LATERAL (
+			SELECT DISTINCT ON (sc.cat_id) sc.cat_id, sc.new_salary
+			FROM salary_changes sc
+			JOIN cats c ON c.id = sc.cat_id
+			WHERE sc.effective_from <= months.month_end
+			  AND (c.deleted_at IS NULL OR c.deleted_at::date > months.month_end)
+			ORDER BY sc.cat_id, sc.effective_from DESC, sc.id DESC
+		) rate ON true
+		GROUP BY 1
+		ORDER BY 1`
+	rows, err := r.db.Query(ctx, query, from, to, string(period))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []domain.PayrollLine{}
+	for rows.Next() {
+		var l domain.PayrollLine
+		if err := rows.Scan(&l.PeriodStart, &l.Total, &l.Headcount); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
+}
+
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+
+// htmlEscaped escapes column for HTML in SQL, so that the only markup in a
+// headline is the one ts_headline adds.
+func htmlEscaped(column string) string {
+	return "replace(replace(replace(" + column + ", '&', '&amp;'), '<', '&lt;'), '>', '&gt;')"
+}
+
+func (r *postgresCatRepository) Search(ctx context.Context, query string, limit int) ([]*domain.SearchResult, error) {
+	searchQuery := `
+		WITH q AS (SELECT to_tsquery('simple', $2) AS tsq)
+		SELECT c.id, c.name, c.years_of_experience, c.breed, c.salary,
+		       ts_rank(c.search_vector, q.tsq) + GREATEST(similarity(c.name, $1), similarity(c.breed, $1)) AS rank,
+		       ts_headline('simple', ` + htmlEscaped("c.name") + `, q.tsq, $3),
+		       ts_headline('simple', ` + htmlEscaped("c.breed") + `, q.tsq, $3)
+		FROM cats c, q
+		WHERE (c.search_vector @@ q.tsq OR c.name % $1 OR c.breed % $1) AND c.deleted_at IS NULL
+		ORDER BY rank DESC, c.id
+		LIMIT $4`
+	rows, err := r.db.Query(ctx, searchQuery, query, prefixQuery(query), headlineOptions, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []*domain.SearchResult{}
+	for rows.Next() {
+		var c domain.Cat
+		var name, breed string
+		res := &domain.SearchResult{Cat: &c}
+		err := rows.Scan(&c.ID, &c.Name, &c.YearsOfExperience, &c.Breed, &c.Salary, &res.Rank, &name, &breed)
+		if err != nil {
+			return nil, err
+		}
+		res.Highlights = map[string]string{"name": name, "breed": breed}
+		results = append(results, res)
+	}
+	return results, rows.Err()
+}
+
+// prefixQuery turns free text into a tsquery matching every word as a
+// prefix, e.g. "sia bl" becomes "sia:* & bl:*". Anything but letters and
+// digits is dropped so that user input cannot inject tsquery operators.
+func prefixQuery(q string) string {
+	words := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = w + ":*"
+	}
+	return strings.Join(words, " & ")
+}
@@ -0,0 +1,104 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"go-test-assesment/db"
+	"go-test-assesment/internal/cat/domain"
+	"go-test-assesment/internal/cat/repository"
+	"go-test-assesment/pkg/money"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openDatabase connects to the scratch database at TEST_DATABASE_URL,
+// applies the schema and empties it. Tests are skipped without one.
+func openDatabase(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, url)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	_, err = pool.Exec(ctx, db.Schema)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `TRUNCATE cats, missions RESTART IDENTITY CASCADE`)
+	require.NoError(t, err)
+	return pool
+}
+
+func TestSearch_EscapesHighlights(t *testing.T) {
+	repo := repository.NewPostgresCatRepository(openDatabase(t))
+	ctx := context.Background()
+	c := &domain.Cat{Name: "Tom <script>", YearsOfExperience: 2, Breed: "Siamese & co", Salary: money.FromCents(100000)}
+	require.NoError(t, repo.Store(ctx, c))
+
+	results, err := repo.Search(ctx, "tom", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "<mark>Tom</mark> &lt;script&gt;", results[0].Highlights["name"])
+	assert.Equal(t, "Siamese &amp; co", results[0].Highlights["breed"])
+}
+
+func TestDelete_KeepsSalaryLedger(t *testing.T) {
+	pool := openDatabase(t)
+	repo := repository.NewPostgresCatRepository(pool)
+	ctx := context.Background()
+	c := &domain.Cat{Name: "Tom", YearsOfExperience: 2, Breed: "Bengal", Salary: money.FromCents(100000)}
+	require.NoError(t, repo.Store(ctx, c))
+	_, err := pool.Exec(ctx, `UPDATE salary_changes SET effective_from = '2024-01-10' WHERE cat_id = $1`, c.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Delete(ctx, c.ID))
+	_, err = pool.Exec(ctx, `UPDATE cats SET deleted_at = '2024-02-15' WHERE id = $1`, c.ID)
+	require.NoError(t, err)
+
+	_, err = repo.GetByID(ctx, c.ID)
+	assert.ErrorIs(t, err, domain.ErrCatNotFound)
+	cats, err := repo.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, cats)
+
+	history, err := repo.SalaryHistory(ctx, c.ID)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+
+	lines, err := repo.Payroll(ctx, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), domain.PayrollMonth)
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	assert.Equal(t, 1, lines[0].Headcount, "before the deletion")
+	assert.Equal(t, 0, lines[1].Headcount, "the month of the deletion")
+
+	_, err = pool.Exec(ctx, `DELETE FROM cats WHERE id = $1`, c.ID)
+	assert.ErrorContains(t, err, "salary_changes_cat_id_fkey")
+}
+
+func TestDelete_RefusesMissingAndBusyCats(t *testing.T) {
+	pool := openDatabase(t)
+	repo := repository.NewPostgresCatRepository(pool)
+	ctx := context.Background()
+	c := &domain.Cat{Name: "Tom", YearsOfExperience: 2, Breed: "Bengal", Salary: money.FromCents(100000)}
+	require.NoError(t, repo.Store(ctx, c))
+
+	assert.ErrorIs(t, repo.Delete(ctx, c.ID+1), domain.ErrCatNotFound)
+
+	var missionID int64
+	err := pool.QueryRow(ctx, `INSERT INTO missions (cat_id, state) VALUES ($1, 'in_progress') RETURNING id`, c.ID).Scan(&missionID)
+	require.NoError(t, err)
+	assert.ErrorIs(t, repo.Delete(ctx, c.ID), domain.ErrCatOnMission)
+
+	_, err = pool.Exec(ctx, `UPDATE missions SET state = 'aborted' WHERE id = $1`, missionID)
+	require.NoError(t, err)
+	require.NoError(t, repo.Delete(ctx, c.ID))
+	assert.ErrorIs(t, repo.Delete(ctx, c.ID), domain.ErrCatNotFound)
+}
@@ -5,17 +5,30 @@ import (
 	"errors"
 	"fmt"
 	cat "go-test-assesment/internal/cat/domain"
+	"strings"
+	"time"
+)
+
+const (
+	// maxPayrollMonths bounds the range of a single payroll report.
+	maxPayrollMonths = 120
+
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxQueryLength     = 100
 )
 
 type CatUsecase struct {
 	repo           cat.Repository
 	breedValidator cat.BreedValidator
+	now            func() time.Time
 }
 
 func NewCatUsecase(repo cat.Repository, bv cat.BreedValidator) *CatUsecase {
 	return &CatUsecase{
 		repo:           repo,
 		breedValidator: bv,
+		now:            time.Now,
 	}
 }
 
@@ -23,6 +36,9 @@ func (uc *CatUsecase) Create(ctx context.Context, c *cat.Cat) error {
 	if c.Name == "" {
 		return errors.New("cat name cannot be empty")
 	}
+	if c.Salary < 0 {
+		return cat.ErrNegativeSalary
+	}
 
 	valid, err := uc.breedValidator.ValidateBreed(ctx, c.Breed)
 	if err != nil {
@@ -39,11 +55,21 @@ func (uc *CatUsecase) GetByID(ctx context.Context, id int64) (*cat.Cat, error) {
 	return uc.repo.GetByID(ctx, id)
 }
 
-func (uc *CatUsecase) UpdateSalary(ctx context.Context, id int64, salary float64) error {
-	if salary < 0 {
-		return errors.New("salary cannot be negative")
+// UpdateSalary records a salary change. It takes effect today unless an
+// earlier EffectiveFrom is given; changes cannot be scheduled ahead.
+func (uc *CatUsecase) UpdateSalary(ctx context.Context, change *cat.SalaryChange) error {
+	if change.NewSalary < 0 {
+		return cat.ErrNegativeSalary
 	}
-	return uc.repo.UpdateSalary(ctx, id, salary)
+	today := truncateDay(uc.now())
+	if change.EffectiveFrom.IsZero() {
+		change.EffectiveFrom = today
+	}
+	change.EffectiveFrom = truncateDay(change.EffectiveFrom)
+	if change.EffectiveFrom.After(today) {
+		return cat.ErrFutureEffectiveDate
+	}
+	return uc.repo.UpdateSalary(ctx, change)
 }
 
 func (uc *CatUsecase) Delete(ctx context.Context, id int64) error {
@@ -53,3 +79,74 @@ func (uc *CatUsecase) Delete(ctx context.Context, id int64) error {
 func (uc *CatUsecase) List(ctx context.Context) ([]*cat.Cat, error) {
 	return uc.repo.List(ctx)
 }
+
+// Export streams every cat to fn without loading them all into memory.
+func (uc *CatUsecase) Export(ctx context.Context, fn func(*cat.Cat) error) error {
+	return uc.repo.Each(ctx, fn)
+}
+
+func (uc *CatUsecase) SalaryHistory(ctx context.Context, catID int64) ([]*cat.SalaryChange, error) {
+	return uc.repo.SalaryHistory(ctx, catID)
+}
+
+// Payroll totals monthly salaries over the whole months between from and to.
+// Periods are calendar months, quarters or years; the first and last may be
+// partial when the range does not line up with them.
+func (uc *CatUsecase) Payroll(ctx context.Context, from, to time.Time, period cat.PayrollPeriod) (*cat.PayrollReport, error) {
+	months := period.Months()
+	if months == 0 {
+		return nil, cat.ErrInvalidPeriod
+	}
+	from = firstOfMonth(from)
+	to = firstOfMonth(to).AddDate(0, 1, -1)
+	if to.Before(from) {
+		return nil, cat.ErrInvalidRange
+	}
+	if from.AddDate(0, maxPayrollMonths, 0).Before(to) {
+		return nil, fmt.Errorf("%w: at most %d months", cat.ErrInvalidRange, maxPayrollMonths)
+	}
+
+	lines, err := uc.repo.Payroll(ctx, from, to, period)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &cat.PayrollReport{From: from, To: to, Period: period, Lines: lines}
+	for i := range report.Lines {
+		l := &report.Lines[i]
+		l.PeriodEnd = l.PeriodStart.AddDate(0, months, -1)
+		if l.PeriodStart.Before(from) {
+			l.PeriodStart = from
+		}
+		if l.PeriodEnd.After(to) {
+			l.PeriodEnd = to
+		}
+		report.Total += l.Total
+	}
+	return report, nil
+}
+
+// Search finds cats by partial name or breed, returning up to limit
+// results: 20 when limit is not positive and at most 100.
+func (uc *CatUsecase) Search(ctx context.Context, query string, limit int) ([]*cat.SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, cat.ErrEmptyQuery
+	}
+	if len(query) > maxQueryLength {
+		return nil, cat.ErrQueryTooLong
+	}
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	limit = min(limit, maxSearchLimit)
+	return uc.repo.Search(ctx, query, limit)
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func firstOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
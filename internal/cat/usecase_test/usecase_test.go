@@ -3,18 +3,25 @@ package usecase_test
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	cat "go-test-assesment/internal/cat/domain"
 	"go-test-assesment/internal/cat/usecase"
+	"go-test-assesment/pkg/money"
 )
 
 type mockCatRepo struct {
 	storeFn        func(ctx context.Context, c *cat.Cat) error
 	getByIDFn      func(ctx context.Context, id int64) (*cat.Cat, error)
-	updateSalaryFn func(ctx context.Context, id int64, salary float64) error
+	updateSalaryFn func(ctx context.Context, change *cat.SalaryChange) error
+	historyFn      func(ctx context.Context, catID int64) ([]*cat.SalaryChange, error)
+	payrollFn      func(ctx context.Context, from, to time.Time, period cat.PayrollPeriod) ([]cat.PayrollLine, error)
+	searchFn       func(ctx context.Context, query string, limit int) ([]*cat.SearchResult, error)
 	deleteFn       func(ctx context.Context, id int64) error
 	listFn         func(ctx context.Context) ([]*cat.Cat, error)
+	eachFn         func(ctx context.Context, fn func(*cat.Cat) error) error
 }
 
 func (m *mockCatRepo) Store(ctx context.Context, c *cat.Cat) error {
@@ -23,8 +30,17 @@ func (m *mockCatRepo) Store(ctx context.Context, c *cat.Cat) error {
 func (m *mockCatRepo) GetByID(ctx context.Context, id int64) (*cat.Cat, error) {
 	return m.getByIDFn(ctx, id)
 }
-func (m *mockCatRepo) UpdateSalary(ctx context.Context, id int64, salary float64) error {
-	return m.updateSalaryFn(ctx, id, salary)
+func (m *mockCatRepo) UpdateSalary(ctx context.Context, change *cat.SalaryChange) error {
+	return m.updateSalaryFn(ctx, change)
+}
+func (m *mockCatRepo) SalaryHistory(ctx context.Context, catID int64) ([]*cat.SalaryChange, error) {
+	return m.historyFn(ctx, catID)
+}
+func (m *mockCatRepo) Search(ctx context.Context, query string, limit int) ([]*cat.SearchResult, error) {
+	return m.searchFn(ctx, query, limit)
+}
+func (m *mockCatRepo) Payroll(ctx context.Context, from, to time.Time, period cat.PayrollPeriod) ([]cat.PayrollLine, error) {
+	return m.payrollFn(ctx, from, to, period)
 }
 func (m *mockCatRepo) Delete(ctx context.Context, id int64) error {
 	return m.deleteFn(ctx, id)
@@ -32,6 +48,9 @@ func (m *mockCatRepo) Delete(ctx context.Context, id int64) error {
 func (m *mockCatRepo) List(ctx context.Context) ([]*cat.Cat, error) {
 	return m.listFn(ctx)
 }
+func (m *mockCatRepo) Each(ctx context.Context, fn func(*cat.Cat) error) error {
+	return m.eachFn(ctx, fn)
+}
 
 type mockBreedValidator struct {
 	validateFn func(ctx context.Context, breed string) (bool, error)
@@ -115,7 +134,8 @@ func TestCatUsecase_UpdateSalary(t *testing.T) {
 	tests := []struct {
 		name       string
 		id         int64
-		salary     float64
+		salary     money.Amount
+		effective  time.Time
 		repoErr    error
 		wantErr    bool
 		errMessage string
@@ -141,12 +161,33 @@ func TestCatUsecase_UpdateSalary(t *testing.T) {
 			repoErr: errors.New("db update error"),
 			wantErr: true,
 		},
+		{
+			name:      "backdated",
+			id:        1,
+			salary:    1000,
+			effective: time.Now().AddDate(0, -1, 0),
+			wantErr:   false,
+		},
+		{
+			name:       "effective in the future",
+			id:         1,
+			salary:     1000,
+			effective:  time.Now().AddDate(0, 0, 2),
+			wantErr:    true,
+			errMessage: cat.ErrFutureEffectiveDate.Error(),
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := &mockCatRepo{
-				updateSalaryFn: func(ctx context.Context, id int64, salary float64) error {
+				updateSalaryFn: func(ctx context.Context, change *cat.SalaryChange) error {
+					if change.CatID != tt.id || change.NewSalary != tt.salary {
+						t.Errorf("unexpected change passed to repository: %+v", change)
+					}
+					if change.EffectiveFrom.IsZero() {
+						t.Error("effective date was not defaulted")
+					}
 					return tt.repoErr
 				},
 			}
@@ -155,7 +196,7 @@ func TestCatUsecase_UpdateSalary(t *testing.T) {
 
 			uc := usecase.NewCatUsecase(repo, validator)
 
-			err := uc.UpdateSalary(ctx, tt.id, tt.salary)
+			err := uc.UpdateSalary(ctx, &cat.SalaryChange{CatID: tt.id, NewSalary: tt.salary, EffectiveFrom: tt.effective})
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("UpdateSalary() error = %v, wantErr %v", err, tt.wantErr)
@@ -322,3 +363,89 @@ func TestCatUsecase_List(t *testing.T) {
 		})
 	}
 }
+
+func TestCatUsecase_Payroll(t *testing.T) {
+	ctx := context.Background()
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	var gotFrom, gotTo time.Time
+	repo := &mockCatRepo{
+		payrollFn: func(ctx context.Context, from, to time.Time, period cat.PayrollPeriod) ([]cat.PayrollLine, error) {
+			gotFrom, gotTo = from, to
+			return []cat.PayrollLine{
+				{PeriodStart: date(2024, 1, 1), Total: money.MustParse("3000.10"), Headcount: 2},
+				{PeriodStart: date(2024, 4, 1), Total: money.MustParse("4500.05"), Headcount: 3},
+			}, nil
+		},
+	}
+	uc := usecase.NewCatUsecase(repo, &mockBreedValidator{})
+
+	report, err := uc.Payroll(ctx, date(2024, 2, 15), date(2024, 5, 3), cat.PayrollQuarter)
+	if err != nil {
+		t.Fatalf("Payroll() error = %v", err)
+	}
+	if !gotFrom.Equal(date(2024, 2, 1)) || !gotTo.Equal(date(2024, 5, 31)) {
+		t.Errorf("range passed to repository = %v..%v, want whole months", gotFrom, gotTo)
+	}
+	if report.Total != money.MustParse("7500.15") {
+		t.Errorf("total = %v, want 7500.15", report.Total)
+	}
+	// Periods are clipped to the requested range.
+	first, last := report.Lines[0], report.Lines[1]
+	if !first.PeriodStart.Equal(date(2024, 2, 1)) || !first.PeriodEnd.Equal(date(2024, 3, 31)) {
+		t.Errorf("first period = %v..%v", first.PeriodStart, first.PeriodEnd)
+	}
+	if !last.PeriodStart.Equal(date(2024, 4, 1)) || !last.PeriodEnd.Equal(date(2024, 5, 31)) {
+		t.Errorf("last period = %v..%v", last.PeriodStart, last.PeriodEnd)
+	}
+
+	if _, err := uc.Payroll(ctx, date(2024, 1, 1), date(2024, 2, 1), "week"); !errors.Is(err, cat.ErrInvalidPeriod) {
+		t.Errorf("invalid period error = %v", err)
+	}
+	if _, err := uc.Payroll(ctx, date(2024, 3, 1), date(2024, 2, 1), cat.PayrollMonth); !errors.Is(err, cat.ErrInvalidRange) {
+		t.Errorf("reversed range error = %v", err)
+	}
+	if _, err := uc.Payroll(ctx, date(2000, 1, 1), date(2024, 2, 1), cat.PayrollMonth); !errors.Is(err, cat.ErrInvalidRange) {
+		t.Errorf("long range error = %v", err)
+	}
+}
+
+func TestCatUsecase_Search(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		query     string
+		limit     int
+		wantQuery string
+		wantLimit int
+		wantErr   error
+	}{
+		{name: "trims query", query: "  sia ", limit: 5, wantQuery: "sia", wantLimit: 5},
+		{name: "default limit", query: "tom", wantQuery: "tom", wantLimit: 20},
+		{name: "limit capped", query: "tom", limit: 1000, wantQuery: "tom", wantLimit: 100},
+		{name: "empty query", query: "   ", wantErr: cat.ErrEmptyQuery},
+		{name: "query too long", query: strings.Repeat("a", 101), wantErr: cat.ErrQueryTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotQuery string
+			var gotLimit int
+			repo := &mockCatRepo{
+				searchFn: func(ctx context.Context, query string, limit int) ([]*cat.SearchResult, error) {
+					gotQuery, gotLimit = query, limit
+					return nil, nil
+				},
+			}
+			uc := usecase.NewCatUsecase(repo, &mockBreedValidator{})
+
+			_, err := uc.Search(ctx, tt.query, tt.limit)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Search() error = %v, want %v", err, tt.wantErr)
+			}
+			if gotQuery != tt.wantQuery || gotLimit != tt.wantLimit {
+				t.Errorf("repository got (%q, %d), want (%q, %d)", gotQuery, gotLimit, tt.wantQuery, tt.wantLimit)
+			}
+		})
+	}
+}
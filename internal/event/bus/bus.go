@@ -0,0 +1,110 @@
+package bus
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-test-assesment/internal/event/domain"
+)
+
+const subscriberBuffer = 64
+
+type subscriber struct {
+	ch     chan domain.Event
+	filter domain.Filter
+}
+
+// Bus is an in-process event bus. It keeps the most recent events so that
+// reconnecting subscribers can resume from a Last-Event-ID.
+type Bus struct {
+	mu      sync.Mutex
+	lastID  int64
+	history []domain.Event
+	size    int
+	subs    map[*subscriber]struct{}
+}
+
+func NewBus(historySize int) *Bus {
+	return &Bus{
+		size: historySize,
+		subs: make(map[*subscriber]struct{}),
+	}
+}
+
+func (b *Bus) Publish(_ context.Context, e domain.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Events read from a persistent feed keep their IDs so that clients can
+	// resume against any replica.
+	if e.ID == 0 {
+		e.ID = b.lastID + 1
+	}
+	if e.ID > b.lastID {
+		b.lastID = e.ID
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now().UTC()
+	}
+
+	if b.size > 0 {
+		if len(b.history) == b.size {
+			copy(b.history, b.history[1:])
+			b.history = b.history[:b.size-1]
+		}
+		b.history = append(b.history, e)
+	}
+
+	for s := range b.subs {
+		if !s.filter.Match(e) {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			// A subscriber that cannot keep up is dropped; it can reconnect
+			// and resume from the last event it received.
+			b.remove(s)
+		}
+	}
+}
+
+func (b *Bus) Subscribe(ctx context.Context, filter domain.Filter, lastEventID int64) <-chan domain.Event {
+	b.mu.Lock()
+	var backlog []domain.Event
+	if lastEventID > 0 {
+		for _, e := range b.history {
+			if e.ID > lastEventID && filter.Match(e) {
+				backlog = append(backlog, e)
+			}
+		}
+	}
+	s := &subscriber{
+		ch:     make(chan domain.Event, len(backlog)+subscriberBuffer),
+		filter: filter,
+	}
+	for _, e := range backlog {
+		s.ch <- e
+	}
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		b.remove(s)
+		b.mu.Unlock()
+	}()
+
+	return s.ch
+}
+
+// remove must be called with b.mu held.
+func (b *Bus) remove(s *subscriber) {
+	if _, ok := b.subs[s]; !ok {
+		return
+	}
+	delete(b.subs, s)
+	close(s.ch)
+}
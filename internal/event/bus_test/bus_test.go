@@ -0,0 +1,120 @@
+package bus_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-test-assesment/internal/event/bus"
+	"go-test-assesment/internal/event/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func receive(t *testing.T, ch <-chan domain.Event) domain.Event {
+	t.Helper()
+	select {
+	case e, ok := <-ch:
+		require.True(t, ok, "channel closed")
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+		return domain.Event{}
+	}
+}
+
+func assertNoEvent(t *testing.T, ch <-chan domain.Event) {
+	t.Helper()
+	select {
+	case e := <-ch:
+		t.Fatalf("unexpected event: %+v", e)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestBus_PublishAssignsSequentialIDs(t *testing.T) {
+	b := bus.NewBus(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := b.Subscribe(ctx, domain.Filter{}, 0)
+	b.Publish(ctx, domain.Event{Type: domain.MissionCreated, MissionID: 1})
+	b.Publish(ctx, domain.Event{Type: domain.MissionAssigned, MissionID: 1})
+
+	first := receive(t, ch)
+	second := receive(t, ch)
+	assert.Equal(t, int64(1), first.ID)
+	assert.Equal(t, domain.MissionCreated, first.Type)
+	assert.False(t, first.CreatedAt.IsZero())
+	assert.Equal(t, int64(2), second.ID)
+}
+
+func TestBus_Filter(t *testing.T) {
+	b := bus.NewBus(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	missionID := int64(2)
+	catID := int64(7)
+	byMission := b.Subscribe(ctx, domain.Filter{MissionID: &missionID}, 0)
+	byCat := b.Subscribe(ctx, domain.Filter{CatID: &catID}, 0)
+
+	other := int64(8)
+	b.Publish(ctx, domain.Event{Type: domain.MissionCreated, MissionID: 1})
+	b.Publish(ctx, domain.Event{Type: domain.MissionAssigned, MissionID: 2, CatID: &catID})
+	b.Publish(ctx, domain.Event{Type: domain.MissionAssigned, MissionID: 3, CatID: &other})
+
+	assert.Equal(t, int64(2), receive(t, byMission).MissionID)
+	assertNoEvent(t, byMission)
+	assert.Equal(t, int64(2), receive(t, byCat).MissionID)
+	assertNoEvent(t, byCat)
+}
+
+func TestBus_ResumeFromLastEventID(t *testing.T) {
+	b := bus.NewBus(3)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for i := int64(1); i <= 5; i++ {
+		b.Publish(ctx, domain.Event{Type: domain.TargetUpdated, MissionID: i})
+	}
+
+	// Only the three most recent events are retained.
+	ch := b.Subscribe(ctx, domain.Filter{}, 1)
+	assert.Equal(t, int64(3), receive(t, ch).ID)
+	assert.Equal(t, int64(4), receive(t, ch).ID)
+	assert.Equal(t, int64(5), receive(t, ch).ID)
+	assertNoEvent(t, ch)
+
+	b.Publish(ctx, domain.Event{Type: domain.TargetUpdated, MissionID: 6})
+	assert.Equal(t, int64(6), receive(t, ch).ID)
+}
+
+func TestBus_NoReplayWithoutLastEventID(t *testing.T) {
+	b := bus.NewBus(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b.Publish(ctx, domain.Event{Type: domain.MissionCreated, MissionID: 1})
+	ch := b.Subscribe(ctx, domain.Filter{}, 0)
+	assertNoEvent(t, ch)
+}
+
+func TestBus_UnsubscribeOnContextDone(t *testing.T) {
+	b := bus.NewBus(10)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch := b.Subscribe(ctx, domain.Filter{}, 0)
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("subscription was not closed")
+	}
+
+	// Publishing after the subscriber left must not block or panic.
+	b.Publish(context.Background(), domain.Event{Type: domain.MissionCreated, MissionID: 1})
+}
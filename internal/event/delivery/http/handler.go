@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-test-assesment/internal/event/domain"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+const heartbeatInterval = 15 * time.Second
+
+type Handler struct {
+	subscriber domain.Subscriber
+}
+
+func NewHandler(s domain.Subscriber) *Handler {
+	return &Handler{subscriber: s}
+}
+
+func (h *Handler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/events", h.stream)
+}
+
+func parseOptionalID(c *gin.Context, name string) (*int64, bool) {
+	v, ok := c.GetQuery(name)
+	if !ok {
+		return nil, true
+	}
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		c.Error(err)
+		return nil, false
+	}
+	return &id, true
+}
+
+// stream godoc
+// @Summary Stream mission and target events
+// @Description Server-Sent Events stream of mission created/assigned/completed and target updated events. Reconnecting clients may send the Last-Event-ID header (or last_event_id query parameter) to replay events they missed.
+// @Tags Events
+// @Produce text/event-stream
+// @Param mission_id query int false "Only events of this mission"
+// @Param cat_id query int false "Only events of missions assigned to this cat"
+// @Param Last-Event-ID header int false "Resume after this event ID"
+// @Param last_event_id query int false "Resume after this event ID"
+// @Success 200 {object} domain.Event
+// @Failure 400 {object} map[string]string
+// @Router /events [get]
+func (h *Handler) stream(c *gin.Context) {
+	var filter domain.Filter
+	var ok bool
+	if filter.MissionID, ok = parseOptionalID(c, "mission_id"); !ok {
+		return
+	}
+	if filter.CatID, ok = parseOptionalID(c, "cat_id"); !ok {
+		return
+	}
+
+	lastID := c.GetHeader("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.Query("last_event_id")
+	}
+	var lastEventID int64
+	if lastID != "" {
+		id, err := strconv.ParseInt(lastID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid last event id"})
+			c.Error(err)
+			return
+		}
+		lastEventID = id
+	}
+
+	ctx := c.Request.Context()
+	events := h.subscriber.Subscribe(ctx, filter, lastEventID)
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Header("Content-Type", sse.ContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.Render(-1, sse.Event{
+				Id:    strconv.FormatInt(e.ID, 10),
+				Event: string(e.Type),
+				Data:  e,
+			})
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": heartbeat\n\n")
+			return err == nil
+		case <-ctx.Done():
+			return false
+		}
+	})
+}
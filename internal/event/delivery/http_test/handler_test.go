@@ -0,0 +1,131 @@
+package handler_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-test-assesment/internal/event/bus"
+	handler "go-test-assesment/internal/event/delivery/http"
+	"go-test-assesment/internal/event/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newServer(b *bus.Bus) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handler.NewHandler(b).RegisterRoutes(r)
+	return httptest.NewServer(r)
+}
+
+// readEvent collects SSE fields until the blank line that terminates an event.
+func readEvent(t *testing.T, lines <-chan string) map[string]string {
+	t.Helper()
+	fields := map[string]string{}
+	for {
+		select {
+		case line, ok := <-lines:
+			require.True(t, ok, "stream closed")
+			if line == "" {
+				if len(fields) > 0 {
+					return fields
+				}
+				continue
+			}
+			if strings.HasPrefix(line, ":") {
+				continue
+			}
+			key, value, _ := strings.Cut(line, ":")
+			fields[key] = value
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for event")
+			return nil
+		}
+	}
+}
+
+func openStream(t *testing.T, ctx context.Context, url string, header http.Header) (*http.Response, <-chan string) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	return resp, lines
+}
+
+func TestHandler_StreamFiltersEvents(t *testing.T) {
+	b := bus.NewBus(10)
+	srv := newServer(b)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	resp, lines := openStream(t, ctx, srv.URL+"/events?mission_id=2", nil)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// Response headers are only flushed once the handler has subscribed.
+	b.Publish(ctx, domain.Event{Type: domain.MissionCreated, MissionID: 1})
+	b.Publish(ctx, domain.Event{Type: domain.MissionAssigned, MissionID: 2})
+
+	ev := readEvent(t, lines)
+	assert.Equal(t, "mission.assigned", ev["event"])
+	assert.Equal(t, "2", ev["id"])
+	assert.Contains(t, ev["data"], `"mission_id":2`)
+}
+
+func TestHandler_StreamResumesFromLastEventID(t *testing.T) {
+	b := bus.NewBus(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b.Publish(ctx, domain.Event{Type: domain.MissionCreated, MissionID: 1})
+	b.Publish(ctx, domain.Event{Type: domain.MissionAssigned, MissionID: 1})
+	b.Publish(ctx, domain.Event{Type: domain.MissionCompleted, MissionID: 1})
+
+	srv := newServer(b)
+	defer srv.Close()
+
+	resp, lines := openStream(t, ctx, srv.URL+"/events", http.Header{"Last-Event-Id": {"1"}})
+	defer resp.Body.Close()
+
+	assert.Equal(t, "2", readEvent(t, lines)["id"])
+	assert.Equal(t, "3", readEvent(t, lines)["id"])
+}
+
+func TestHandler_StreamRejectsInvalidParams(t *testing.T) {
+	srv := newServer(bus.NewBus(10))
+	defer srv.Close()
+
+	for _, path := range []string{
+		"/events?mission_id=abc",
+		"/events?cat_id=1.5",
+		"/events?last_event_id=x",
+	} {
+		resp, err := http.Get(srv.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, path)
+	}
+}
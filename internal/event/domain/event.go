@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+type Type string
+
+const (
+	MissionCreated   Type = "mission.created"
+	MissionAssigned  Type = "mission.assigned"
+	MissionStarted   Type = "mission.started"
+	MissionCompleted Type = "mission.completed"
+	MissionAborted   Type = "mission.aborted"
+	MissionFailed    Type = "mission.failed"
+	TargetUpdated    Type = "target.updated"
+	MissionOverdue   Type = "mission.overdue"
+	TargetOverdue    Type = "target.overdue"
+)
+
+type Event struct {
+	ID        int64           `json:"id"`
+	Type      Type            `json:"type"`
+	MissionID int64           `json:"mission_id"`
+	CatID     *int64          `json:"cat_id,omitempty"`
+	TargetID  *int64          `json:"target_id,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Filter selects events by mission and/or cat; nil fields match everything.
+type Filter struct {
+	MissionID *int64
+	CatID     *int64
+}
+
+func (f Filter) Match(e Event) bool {
+	if f.MissionID != nil && *f.MissionID != e.MissionID {
+		return false
+	}
+	if f.CatID != nil && (e.CatID == nil || *f.CatID != *e.CatID) {
+		return false
+	}
+	return true
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, e Event)
+}
+
+type Subscriber interface {
+	// Subscribe streams events matching filter until ctx is done. Events
+	// newer than lastEventID that are still retained are replayed first.
+	Subscribe(ctx context.Context, filter Filter, lastEventID int64) <-chan Event
+}
+
+// Repository reads the persistent change feed recorded by the database.
+type Repository interface {
+	LatestID(ctx context.Context) (int64, error)
+	ListAfter(ctx context.Context, afterID int64, limit int) ([]Event, error)
+	ListByIDs(ctx context.Context, ids []int64) ([]Event, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,212 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go-test-assesment/internal/event/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Channel is the NOTIFY channel the events table trigger announces new rows on.
+const Channel = "mission_events"
+
+const (
+	batchSize     = 500
+	minBackoff    = 500 * time.Millisecond
+	maxBackoff    = 30 * time.Second
+	gapTimeout    = time.Minute
+	maxGap        = 1000
+	pollInterval  = 30 * time.Second
+	pruneInterval = time.Hour
+	retention     = 7 * 24 * time.Hour
+	closeTimeout  = 5 * time.Second
+)
+
+// Conn is a connection that is already listening on Channel.
+type Conn interface {
+	WaitForNotification(ctx context.Context) (*pgconn.Notification, error)
+	Close(ctx context.Context) error
+}
+
+type Dialer func(ctx context.Context) (Conn, error)
+
+// PostgresDialer opens a dedicated connection outside of the pool, since a
+// LISTEN is bound to the session that issued it.
+func PostgresDialer(dsn string) Dialer {
+	return func(ctx context.Context) (Conn, error) {
+		conn, err := pgx.Connect(ctx, dsn)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{Channel}.Sanitize()); err != nil {
+			conn.Close(ctx)
+			return nil, err
+		}
+		return conn, nil
+	}
+}
+
+// Listener relays events recorded in the database to local subscribers.
+// Notifications only signal that new rows exist; the rows themselves are
+// always read from the events table, so events committed while the
+// connection was down are caught up after reconnecting.
+type Listener struct {
+	dial      Dialer
+	repo      domain.Repository
+	publisher domain.Publisher
+
+	lastID int64
+	// gaps holds IDs skipped over by a read. A transaction that allocated an
+	// ID may commit after a later one, so gaps are retried until gapTimeout.
+	gaps map[int64]time.Time
+}
+
+func NewListener(dial Dialer, repo domain.Repository, publisher domain.Publisher) *Listener {
+	return &Listener{
+		dial:      dial,
+		repo:      repo,
+		publisher: publisher,
+		gaps:      make(map[int64]time.Time),
+	}
+}
+
+// Run blocks until ctx is done, reconnecting with exponential backoff.
+// Only events recorded after Run starts are relayed.
+func (l *Listener) Run(ctx context.Context) error {
+	lastID, err := l.repo.LatestID(ctx)
+	if err != nil {
+		return err
+	}
+	l.lastID = lastID
+
+	backoff := minBackoff
+	for {
+		connected, err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if connected {
+			backoff = minBackoff
+		}
+		log.Printf("[ERROR] event listener: %v; reconnecting in %v", err, backoff)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// listen returns when the connection fails; connected reports whether it
+// was established at all.
+func (l *Listener) listen(ctx context.Context) (connected bool, err error) {
+	conn, err := l.dial(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+		defer cancel()
+		conn.Close(closeCtx)
+	}()
+
+	// Anything committed while we were not listening.
+	if err := l.catchUp(ctx); err != nil {
+		return true, err
+	}
+
+	for {
+		waitCtx, cancel := context.WithTimeout(ctx, pollInterval)
+		_, err := conn.WaitForNotification(waitCtx)
+		cancel()
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			return true, err
+		}
+		// A timeout still triggers a read so that pending gaps are retried.
+		if err := l.catchUp(ctx); err != nil {
+			return true, err
+		}
+	}
+}
+
+func (l *Listener) catchUp(ctx context.Context) error {
+	if err := l.fillGaps(ctx); err != nil {
+		return err
+	}
+	for {
+		events, err := l.repo.ListAfter(ctx, l.lastID, batchSize)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, e := range events {
+			if e.ID-l.lastID <= maxGap {
+				for id := l.lastID + 1; id < e.ID; id++ {
+					l.gaps[id] = now
+				}
+			}
+			l.lastID = e.ID
+			l.publisher.Publish(ctx, e)
+		}
+		if len(events) < batchSize {
+			return nil
+		}
+	}
+}
+
+func (l *Listener) fillGaps(ctx context.Context) error {
+	if len(l.gaps) == 0 {
+		return nil
+	}
+	now := time.Now()
+	ids := make([]int64, 0, len(l.gaps))
+	for id, seen := range l.gaps {
+		if now.Sub(seen) > gapTimeout {
+			// Most likely a rolled back transaction.
+			delete(l.gaps, id)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	events, err := l.repo.ListByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, e := range events {
+		delete(l.gaps, e.ID)
+		l.publisher.Publish(ctx, e)
+	}
+	return nil
+}
+
+// Prune deletes events older than a week every hour until ctx is done. The
+// triggers record events whichever way they are published, so it has to run
+// without a Listener too.
+func Prune(ctx context.Context, repo domain.Repository) {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := repo.DeleteBefore(ctx, time.Now().Add(-retention)); err != nil && ctx.Err() == nil {
+				log.Printf("[ERROR] event prune: %v", err)
+			}
+		}
+	}
+}
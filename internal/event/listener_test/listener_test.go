@@ -0,0 +1,207 @@
+package listener_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go-test-assesment/internal/event/domain"
+	"go-test-assesment/internal/event/listener"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	mu     sync.Mutex
+	events map[int64]domain.Event
+}
+
+func newFakeRepo(ids ...int64) *fakeRepo {
+	r := &fakeRepo{events: map[int64]domain.Event{}}
+	for _, id := range ids {
+		r.add(id)
+	}
+	return r
+}
+
+func (r *fakeRepo) add(id int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events[id] = domain.Event{ID: id, Type: domain.TargetUpdated, MissionID: 1}
+}
+
+func (r *fakeRepo) LatestID(context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var latest int64
+	for id := range r.events {
+		latest = max(latest, id)
+	}
+	return latest, nil
+}
+
+func (r *fakeRepo) ListAfter(_ context.Context, afterID int64, limit int) ([]domain.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []domain.Event
+	for id := afterID + 1; len(out) < limit; id++ {
+		if id > afterID+1000 {
+			break
+		}
+		if e, ok := r.events[id]; ok {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeRepo) ListByIDs(_ context.Context, ids []int64) ([]domain.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []domain.Event
+	for _, id := range ids {
+		if e, ok := r.events[id]; ok {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeRepo) DeleteBefore(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+type fakeConn struct {
+	notify chan error
+}
+
+func (c *fakeConn) WaitForNotification(ctx context.Context) (*pgconn.Notification, error) {
+	select {
+	case err := <-c.notify:
+		if err != nil {
+			return nil, err
+		}
+		return &pgconn.Notification{Channel: listener.Channel}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *fakeConn) Close(context.Context) error { return nil }
+
+type recordingPublisher struct {
+	mu  sync.Mutex
+	ids []int64
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e domain.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.ids = append(p.ids, e.ID)
+}
+
+func (p *recordingPublisher) published() []int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]int64(nil), p.ids...)
+}
+
+func (p *recordingPublisher) waitFor(t *testing.T, want []int64) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(want, p.published())
+	}, 3*time.Second, 5*time.Millisecond, "published %v, want %v", p.published(), want)
+}
+
+func TestListener_RelaysNewEventsOnly(t *testing.T) {
+	repo := newFakeRepo(1, 2)
+	conn := &fakeConn{notify: make(chan error)}
+	pub := &recordingPublisher{}
+	l := listener.NewListener(func(context.Context) (listener.Conn, error) { return conn, nil }, repo, pub)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go l.Run(ctx)
+	// Once the first notification is consumed the listener is past its
+	// starting position, so later additions count as new events.
+	conn.notify <- nil
+
+	repo.add(3)
+	conn.notify <- nil
+	pub.waitFor(t, []int64{3})
+
+	repo.add(4)
+	repo.add(5)
+	conn.notify <- nil
+	pub.waitFor(t, []int64{3, 4, 5})
+}
+
+func TestListener_CatchesUpAfterReconnect(t *testing.T) {
+	repo := newFakeRepo()
+	first := &fakeConn{notify: make(chan error)}
+	second := &fakeConn{notify: make(chan error)}
+
+	var mu sync.Mutex
+	dials := 0
+	dial := func(context.Context) (listener.Conn, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		dials++
+		switch dials {
+		case 1:
+			return first, nil
+		case 2:
+			return nil, errors.New("connection refused")
+		default:
+			return second, nil
+		}
+	}
+	pub := &recordingPublisher{}
+	l := listener.NewListener(dial, repo, pub)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go l.Run(ctx)
+	first.notify <- nil
+
+	repo.add(1)
+	first.notify <- nil
+	pub.waitFor(t, []int64{1})
+
+	// The connection drops and events are committed while nobody listens.
+	first.notify <- errors.New("connection reset")
+	repo.add(2)
+	repo.add(3)
+
+	pub.waitFor(t, []int64{1, 2, 3})
+}
+
+func TestListener_FillsGapsCommittedOutOfOrder(t *testing.T) {
+	repo := newFakeRepo()
+	conn := &fakeConn{notify: make(chan error)}
+	pub := &recordingPublisher{}
+	l := listener.NewListener(func(context.Context) (listener.Conn, error) { return conn, nil }, repo, pub)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go l.Run(ctx)
+	conn.notify <- nil
+
+	// Event 2 was allocated first but its transaction commits after event 3.
+	repo.add(1)
+	repo.add(3)
+	conn.notify <- nil
+	pub.waitFor(t, []int64{1, 3})
+
+	repo.add(2)
+	conn.notify <- nil
+	pub.waitFor(t, []int64{1, 3, 2})
+
+	repo.add(4)
+	conn.notify <- nil
+	pub.waitFor(t, []int64{1, 3, 2, 4})
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go-test-assesment/internal/event/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type EventPostgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewEventPostgres(pool *pgxpool.Pool) *EventPostgres {
+	return &EventPostgres{pool: pool}
+}
+
+const selectEvents = `SELECT id, type, mission_id, cat_id, target_id, data, created_at FROM events`
+
+func (r *EventPostgres) LatestID(ctx context.Context) (int64, error) {
+	var id int64
+	err := r.pool.QueryRow(ctx, `SELECT COALESCE(MAX(id), 0) FROM events`).Scan(&id)
+	return id, err
+}
+
+func (r *EventPostgres) ListAfter(ctx context.Context, afterID int64, limit int) ([]domain.Event, error) {
+	rows, err := r.pool.Query(ctx, selectEvents+` WHERE id > $1 ORDER BY id LIMIT $2`, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanEvents(rows)
+}
+
+func (r *EventPostgres) ListByIDs(ctx context.Context, ids []int64) ([]domain.Event, error) {
+	rows, err := r.pool.Query(ctx, selectEvents+` WHERE id = ANY($1) ORDER BY id`, ids)
+	if err != nil {
+		return nil, err
+	}
+	return scanEvents(rows)
+}
+
+func (r *EventPostgres) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.pool.Exec(ctx, `DELETE FROM events WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
+
+func scanEvents(rows pgx.Rows) ([]domain.Event, error) {
+	defer rows.Close()
+
+	var events []domain.Event
+	for rows.Next() {
+		var e domain.Event
+		if err := rows.Scan(
+			&e.ID, &e.Type, &e.MissionID, &e.CatID, &e.TargetID, &e.Data, &e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go-test-assesment/internal/importer/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	usecase domain.Usecase
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func NewHandler(u domain.Usecase) *Handler {
+	return &Handler{usecase: u}
+}
+
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrUnknownKind), errors.Is(err, domain.ErrUnknownFormat),
+		errors.Is(err, domain.ErrEmptyInput), errors.Is(err, domain.ErrMalformed):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *Handler) RegisterRoutes(r *gin.Engine) {
+	r.POST("/import", h.importFile)
+}
+
+// importFile godoc
+// @Summary Bulk import cats or missions
+// @Description Import cats or missions with their targets from an uploaded CSV or JSON Lines file. CSV files use the columns of the matching export; rows of a mission CSV sharing a mission_id make up one mission, which gets a new ID. Every record is validated first, breeds with a bounded number of parallel lookups; valid records are then committed in batches, each in its own transaction. Invalid records and the records of a failed batch are listed by line. With dry_run nothing is written.
+// @Tags Import
+// @Accept multipart/form-data
+// @Produce json
+// @Param kind query string true "What the file holds" Enums(cats, missions)
+// @Param format query string false "File format; defaults to jsonl for .jsonl and .ndjson files, csv otherwise" Enums(csv, jsonl)
+// @Param dry_run query bool false "Only validate the file"
+// @Param batch_size query int false "Records per transaction (1-1000)" default(100)
+// @Param file formData file true "CSV or JSON Lines file"
+// @Success 200 {object} domain.Report "Dry run"
+// @Success 201 {object} domain.Report "All records imported"
+// @Success 207 {object} domain.Report "Some records were not imported"
+// @Failure 400 {object} ErrorResponse "Invalid parameters or unreadable file"
+// @Failure 422 {object} domain.Report "No record was imported"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /import [post]
+func (h *Handler) importFile(c *gin.Context) {
+	opts := domain.Options{Kind: domain.Kind(c.Query("kind"))}
+	var err error
+	if v, ok := c.GetQuery("dry_run"); ok {
+		if opts.DryRun, err = strconv.ParseBool(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run"})
+			c.Error(err)
+			return
+		}
+	}
+	if v, ok := c.GetQuery("batch_size"); ok {
+		if opts.BatchSize, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid batch_size"})
+			c.Error(err)
+			return
+		}
+	}
+
+	fh, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	opts.Format = domain.Format(c.Query("format"))
+	if opts.Format == "" {
+		opts.Format = domain.FormatOf(fh.Filename)
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	defer f.Close()
+
+	report, err := h.usecase.Import(c.Request.Context(), f, opts)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	switch {
+	case report.DryRun:
+		c.JSON(http.StatusOK, report)
+	case len(report.Errors) == 0:
+		c.JSON(http.StatusCreated, report)
+	case report.Imported > 0:
+		c.JSON(http.StatusMultiStatus, report)
+	default:
+		c.JSON(http.StatusUnprocessableEntity, report)
+	}
+}
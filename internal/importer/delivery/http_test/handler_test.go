@@ -0,0 +1,112 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	handler "go-test-assesment/internal/importer/delivery/http"
+	"go-test-assesment/internal/importer/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUsecase struct {
+	report *domain.Report
+	err    error
+	opts   domain.Options
+	body   string
+}
+
+func (u *fakeUsecase) Import(_ context.Context, r io.Reader, opts domain.Options) (*domain.Report, error) {
+	u.opts = opts
+	data, _ := io.ReadAll(r)
+	u.body = string(data)
+	return u.report, u.err
+}
+
+func upload(t *testing.T, uc domain.Usecase, query, filename, content string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handler.NewHandler(uc).RegisterRoutes(r)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if filename != "" {
+		fw, err := mw.CreateFormFile("file", filename)
+		require.NoError(t, err)
+		_, err = fw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/import"+query, &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestImport(t *testing.T) {
+	uc := &fakeUsecase{report: &domain.Report{Kind: domain.KindCats, Records: 1, Valid: 1, Imported: 1, Batches: 1}}
+
+	w := upload(t, uc, "?kind=cats&batch_size=50", "cats.jsonl", `{"name":"Tom"}`)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, domain.Options{Kind: domain.KindCats, Format: domain.FormatJSONL, BatchSize: 50}, uc.opts)
+	assert.Equal(t, `{"name":"Tom"}`, uc.body)
+	assert.JSONEq(t, `{"kind":"cats","dry_run":false,"records":1,"valid":1,"imported":1,"batches":1}`, w.Body.String())
+}
+
+func TestImport_Status(t *testing.T) {
+	failed := []domain.RowError{{Line: 2, Error: "invalid breed"}}
+	tests := []struct {
+		name   string
+		query  string
+		report domain.Report
+		want   int
+	}{
+		{"dry run", "?kind=cats&dry_run=true", domain.Report{DryRun: true, Errors: failed}, http.StatusOK},
+		{"partial", "?kind=cats", domain.Report{Imported: 1, Errors: failed}, http.StatusMultiStatus},
+		{"nothing imported", "?kind=cats", domain.Report{Errors: failed}, http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &fakeUsecase{report: &tt.report}
+			w := upload(t, uc, tt.query, "cats.csv", "name,breed,salary\n")
+			assert.Equal(t, tt.want, w.Code, w.Body.String())
+			assert.Equal(t, domain.FormatCSV, uc.opts.Format)
+		})
+	}
+}
+
+func TestImport_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		filename string
+		err      error
+		want     int
+	}{
+		{"invalid dry_run", "?kind=cats&dry_run=maybe", "cats.csv", nil, http.StatusBadRequest},
+		{"invalid batch_size", "?kind=cats&batch_size=lots", "cats.csv", nil, http.StatusBadRequest},
+		{"missing file", "?kind=cats", "", nil, http.StatusBadRequest},
+		{"unknown kind", "?kind=dogs", "dogs.csv", domain.ErrUnknownKind, http.StatusBadRequest},
+		{"malformed", "?kind=cats", "cats.csv", domain.ErrMalformed, http.StatusBadRequest},
+		{"database", "?kind=cats", "cats.csv", errors.New("connection refused"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := upload(t, &fakeUsecase{err: tt.err}, tt.query, tt.filename, "x")
+			assert.Equal(t, tt.want, w.Code, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+
+	cat "go-test-assesment/internal/cat/domain"
+	mission "go-test-assesment/internal/mission/domain"
+)
+
+var (
+	ErrUnknownKind   = errors.New("kind must be cats or missions")
+	ErrUnknownFormat = errors.New("format must be csv or jsonl")
+	ErrEmptyInput    = errors.New("input is empty")
+	ErrMalformed     = errors.New("malformed input")
+)
+
+// Kind is what an import file holds.
+type Kind string
+
+const (
+	KindCats     Kind = "cats"
+	KindMissions Kind = "missions"
+)
+
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+)
+
+// FormatOf guesses the format of a file from its name: JSON Lines for
+// .jsonl and .ndjson, CSV otherwise.
+func FormatOf(filename string) Format {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".jsonl", ".ndjson":
+		return FormatJSONL
+	default:
+		return FormatCSV
+	}
+}
+
+type Options struct {
+	Kind   Kind
+	Format Format
+	// DryRun validates every record without writing anything.
+	DryRun bool
+	// BatchSize is how many records are committed per transaction.
+	BatchSize int
+	// Concurrency bounds the number of breed lookups in flight.
+	Concurrency int
+}
+
+// RowError reports a record that was not imported. Line is the line of the
+// record in the input; for a mission spread over several CSV rows it is the
+// first of them.
+type RowError struct {
+	Line  int    `json:"line"`
+	Name  string `json:"name,omitempty"`
+	Error string `json:"error"`
+}
+
+type Report struct {
+	Kind   Kind `json:"kind"`
+	DryRun bool `json:"dry_run"`
+	// Records is how many cats or missions were read, Valid how many of them
+	// passed validation and Imported how many were committed.
+	Records  int `json:"records"`
+	Valid    int `json:"valid"`
+	Imported int `json:"imported"`
+	// Targets is how many targets were committed with the missions.
+	Targets int        `json:"targets,omitempty"`
+	Batches int        `json:"batches"`
+	Errors  []RowError `json:"errors,omitempty"`
+}
+
+// Repository writes whole batches, each in its own transaction.
+type Repository interface {
+	// StoreCats inserts cats along with the first entry of their salary
+	// ledgers, filling in their IDs.
+	StoreCats(ctx context.Context, cats []*cat.Cat) error
+	// StoreMissions inserts missions with their targets, filling in IDs. It
+	// returns ErrCatUnavailable if the cat of an open mission is on another
+	// open mission, checked under the lock that assignments take.
+	StoreMissions(ctx context.Context, missions []*mission.Mission) error
+	// ExistingCats returns which of ids belong to a cat that is not deleted.
+	ExistingCats(ctx context.Context, ids []int64) (map[int64]bool, error)
+	// BusyCats returns which of ids are on an open mission.
+	BusyCats(ctx context.Context, ids []int64) (map[int64]bool, error)
+}
+
+type Usecase interface {
+	Import(ctx context.Context, r io.Reader, opts Options) (*Report, error)
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	cat "go-test-assesment/internal/cat/domain"
+	mission "go-test-assesment/internal/mission/domain"
+	"go-test-assesment/pkg/envelope"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// openMission matches the missions m that hold their cat, as in the mission
+// repository.
+const openMission = `m.state IN ('draft', 'assigned', 'in_progress')`
+
+type ImportPostgres struct {
+	pool *pgxpool.Pool
+	keys *envelope.Keyring
+}
+
+func NewImportPostgres(pool *pgxpool.Pool) *ImportPostgres {
+	return NewImportPostgresWithKeys(pool, nil)
+}
+
+// NewImportPostgresWithKeys seals target notes with keys, as the mission
+// repository does; nil keys store them in plaintext.
+func NewImportPostgresWithKeys(pool *pgxpool.Pool, keys *envelope.Keyring) *ImportPostgres {
+	return &ImportPostgres{pool: pool, keys: keys}
+}
+
+func (r *ImportPostgres) StoreCats(ctx context.Context, cats []*cat.Cat) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		// Same rows as the cat repository's Store, sent in one round trip.
+		query := `
+			WITH c AS (
+				INSERT INTO cats (name, years_of_experience, breed, salary)
+				VALUES ($1, $2, $3, $4)
+				RETURNING id, salary
+			)
+			INSERT INTO salary_changes (cat_id, old_salary, new_salary, effective_from, reason)
+			SELECT id, NULL, salary, CURRENT_DATE, 'initial salary' FROM c
+			RETURNING cat_id`
+		batch := &pgx.Batch{}
+		for _, c := range cats {
+			batch.Queue(query, c.Name, c.YearsOfExperience, c.Breed, c.Salary).
+				QueryRow(func(row pgx.Row) error {
+					return row.Scan(&c.ID)
+				})
+		}
+		return tx.SendBatch(ctx, batch).Close()
+	})
+}
+
+func (r *ImportPostgres) StoreMissions(ctx context.Context, missions []*mission.Mission) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		for _, m := range missions {
+			if m.CatID != nil && !m.Closed() {
+				// Locking the cat serializes its assignments, as in the
+				// mission repository's AssignCat.
+				var busy bool
+				err := tx.QueryRow(ctx, `
+					SELECT EXISTS (SELECT 1 FROM missions m WHERE m.cat_id = c.id AND `+openMission+`)
+					FROM cats c WHERE c.id = $1 AND c.deleted_at IS NULL FOR UPDATE`, m.CatID).Scan(&busy)
+				if errors.Is(err, pgx.ErrNoRows) || busy {
+					return fmt.Errorf("cat %d: %w", *m.CatID, mission.ErrCatUnavailable)
+				}
+				if err != nil {
+					return err
+				}
+			}
+			err := tx.QueryRow(ctx, `
+				INSERT INTO missions (cat_id, state, completed, assigned_at, started_at, completed_at, aborted_at,
+				                      failed_at, priority, required_experience, preferred_breeds, deadline,
+				                      created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, COALESCE($11::text[], '{}'), $12, now(), now())
+				RETURNING id, created_at, updated_at`,
+				m.CatID, m.CurrentState(), m.Completed, m.AssignedAt, m.StartedAt, m.CompletedAt, m.AbortedAt,
+				m.FailedAt, m.Priority, m.RequiredExperience, m.PreferredBreeds, m.Deadline).
+				Scan(&m.ID, &m.CreatedAt, &m.UpdatedAt)
+			if err != nil {
+				return err
+			}
+			for i := range m.Targets {
+				t := &m.Targets[i]
+				t.MissionID = m.ID
+				notes, sealed, err := r.keys.Seal(t.Notes)
+				if err != nil {
+					return err
+				}
+				err = tx.QueryRow(ctx, `
+					WITH t AS (
+					    INSERT INTO targets (mission_id, name, country, notes, notes_sealed, status, outcome,
+					                         completed, compromised_at, eliminated_at, escaped_at, deadline,
+					                         created_at, updated_at)
+					    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, now(), now())
+					    RETURNING id, notes, notes_sealed, created_at, updated_at
+					), note AS (
+					    INSERT INTO target_notes (target_id, author, body, body_sealed, created_at)
+					    SELECT id, '', notes, notes_sealed, created_at FROM t WHERE notes <> ''
+					)
+					SELECT id, created_at, updated_at FROM t`,
+					t.MissionID, t.Name, t.Country, notes, sealed, t.CurrentStatus(), t.Outcome, t.Completed,
+					t.CompromisedAt, t.EliminatedAt, t.EscapedAt, t.Deadline).
+					Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (r *ImportPostgres) ExistingCats(ctx context.Context, ids []int64) (map[int64]bool, error) {
+	return r.catSet(ctx, `SELECT id FROM cats WHERE id = ANY($1) AND deleted_at IS NULL`, ids)
+}
+
+func (r *ImportPostgres) BusyCats(ctx context.Context, ids []int64) (map[int64]bool, error) {
+	return r.catSet(ctx, `SELECT DISTINCT m.cat_id FROM missions m WHERE m.cat_id = ANY($1) AND `+openMission, ids)
+}
+
+// catSet runs query with ids and returns the cat IDs it selects.
+func (r *ImportPostgres) catSet(ctx context.Context, query string, ids []int64) (map[int64]bool, error) {
+	rows, err := r.pool.Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	found, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[int64]bool, len(found))
+	for _, id := range found {
+		existing[id] = true
+	}
+	return existing, nil
+}
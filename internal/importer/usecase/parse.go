@@ -0,0 +1,323 @@
+package usecase
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	cat "go-test-assesment/internal/cat/domain"
+	"go-test-assesment/internal/importer/domain"
+	mission "go-test-assesment/internal/mission/domain"
+	"go-test-assesment/pkg/money"
+)
+
+// maxLineSize bounds a single JSON Lines record.
+const maxLineSize = 1 << 20
+
+// record is a cat or a mission read from the input. err is set when the
+// record could not be read or failed validation; such records are reported
+// and skipped.
+type record struct {
+	line    int
+	name    string
+	cat     *cat.Cat
+	mission *mission.Mission
+	err     error
+}
+
+type catLine struct {
+	Name              string       `json:"name"`
+	YearsOfExperience int          `json:"years_of_experience"`
+	Breed             string       `json:"breed"`
+	Salary            money.Amount `json:"salary"`
+}
+
+type missionLine struct {
+	CatID              *int64     `json:"cat_id"`
+	State              string     `json:"state"`
+	Completed          bool       `json:"completed"`
+	Priority           int        `json:"priority"`
+	RequiredExperience int        `json:"required_experience"`
+	PreferredBreeds    []string   `json:"preferred_breeds"`
+	Deadline           *time.Time `json:"deadline"`
+	Targets            []struct {
+		Name      string     `json:"name"`
+		Country   string     `json:"country"`
+		Notes     string     `json:"notes"`
+		Status    string     `json:"status"`
+		Outcome   string     `json:"outcome"`
+		Completed bool       `json:"completed"`
+		Deadline  *time.Time `json:"deadline"`
+	} `json:"targets"`
+}
+
+func parse(r io.Reader, kind domain.Kind, format domain.Format) ([]*record, error) {
+	switch {
+	case kind == domain.KindCats && format == domain.FormatCSV:
+		return parseCatsCSV(r)
+	case kind == domain.KindCats:
+		return parseJSONL(r, parseCatLine)
+	case format == domain.FormatCSV:
+		return parseMissionsCSV(r)
+	default:
+		return parseJSONL(r, parseMissionLine)
+	}
+}
+
+// parseJSONL reads one record per non-blank line. A line that does not
+// decode is reported on its own rather than failing the whole input.
+func parseJSONL(r io.Reader, decode func([]byte, *record)) ([]*record, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	var records []*record
+	for line := 1; scanner.Scan(); line++ {
+		data := scanner.Bytes()
+		if len(strings.TrimSpace(string(data))) == 0 {
+			continue
+		}
+		rec := &record{line: line}
+		decode(data, rec)
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrMalformed, err)
+	}
+	return records, nil
+}
+
+func parseCatLine(data []byte, rec *record) {
+	var l catLine
+	if err := json.Unmarshal(data, &l); err != nil {
+		rec.err = fmt.Errorf("invalid json: %w", err)
+		return
+	}
+	rec.name = l.Name
+	rec.cat = &cat.Cat{Name: l.Name, YearsOfExperience: l.YearsOfExperience, Breed: l.Breed, Salary: l.Salary}
+}
+
+func parseMissionLine(data []byte, rec *record) {
+	var l missionLine
+	if err := json.Unmarshal(data, &l); err != nil {
+		rec.err = fmt.Errorf("invalid json: %w", err)
+		return
+	}
+	m := &mission.Mission{
+		CatID: l.CatID, State: mission.State(l.State), Completed: l.Completed, Priority: l.Priority,
+		RequiredExperience: l.RequiredExperience, PreferredBreeds: l.PreferredBreeds, Deadline: l.Deadline,
+	}
+	for _, t := range l.Targets {
+		m.Targets = append(m.Targets, mission.Target{
+			Name: t.Name, Country: t.Country, Notes: t.Notes, Status: mission.TargetStatus(t.Status),
+			Outcome: t.Outcome, Completed: t.Completed, Deadline: t.Deadline,
+		})
+	}
+	rec.mission = m
+}
+
+// csvReader wraps a CSV file whose first row names its columns.
+type csvReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader, required ...string) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, domain.ErrEmptyInput
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrMalformed, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, h := range header {
+		columns[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, name := range required {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: csv header is missing %q column", domain.ErrMalformed, name)
+		}
+	}
+	// Rows may omit trailing optional columns.
+	reader.FieldsPerRecord = -1
+	return &csvReader{reader: reader, columns: columns}, nil
+}
+
+// next returns the next row and the line it starts on, or io.EOF.
+func (r *csvReader) next() ([]string, int, error) {
+	row, err := r.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, 0, io.EOF
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", domain.ErrMalformed, err)
+	}
+	line, _ := r.reader.FieldPos(0)
+	return row, line, nil
+}
+
+func (r *csvReader) field(row []string, name string) string {
+	i, ok := r.columns[name]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+// parseCatsCSV reads the columns of the cat export; its id column, if any,
+// is ignored.
+func parseCatsCSV(r io.Reader) ([]*record, error) {
+	cr, err := newCSVReader(r, "name", "breed", "salary")
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*record
+	for {
+		row, line, err := cr.next()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		c := &cat.Cat{Name: cr.field(row, "name"), Breed: cr.field(row, "breed")}
+		rec := &record{line: line, name: c.Name}
+		records = append(records, rec)
+		if v := cr.field(row, "years_of_experience"); v != "" {
+			if c.YearsOfExperience, err = strconv.Atoi(v); err != nil {
+				rec.err = fmt.Errorf("invalid years_of_experience %q", v)
+				continue
+			}
+		}
+		if c.Salary, err = money.Parse(cr.field(row, "salary")); err != nil {
+			rec.err = fmt.Errorf("invalid salary %q: %w", cr.field(row, "salary"), err)
+			continue
+		}
+		rec.cat = c
+	}
+}
+
+// parseMissionsCSV reads the columns of the mission export: one row per
+// target, rows sharing a mission_id making up one mission. The mission_id
+// only groups rows; new IDs are assigned on import. Rows without one are a
+// mission each, and a row without target_name is a mission with no targets.
+func parseMissionsCSV(r io.Reader) ([]*record, error) {
+	cr, err := newCSVReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*record
+	byRef := make(map[string]*record)
+	for {
+		row, line, err := cr.next()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		ref := cr.field(row, "mission_id")
+		rec, ok := byRef[ref]
+		if !ok || ref == "" {
+			rec = &record{line: line, name: ref, mission: &mission.Mission{}}
+			records = append(records, rec)
+			if ref != "" {
+				byRef[ref] = rec
+			}
+			if err := readMissionColumns(cr, row, rec.mission); err != nil {
+				rec.err = fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+
+		if cr.field(row, "target_name") == "" {
+			continue
+		}
+		t := mission.Target{
+			Name:    cr.field(row, "target_name"),
+			Country: cr.field(row, "target_country"),
+			Notes:   cr.field(row, "target_notes"),
+			Status:  mission.TargetStatus(cr.field(row, "target_status")),
+			Outcome: cr.field(row, "target_outcome"),
+		}
+		if v := cr.field(row, "target_completed"); v != "" {
+			if t.Completed, err = strconv.ParseBool(v); err != nil && rec.err == nil {
+				rec.err = fmt.Errorf("line %d: invalid target_completed value %q", line, v)
+			}
+		}
+		if t.Deadline, err = parseTime(cr.field(row, "target_deadline")); err != nil && rec.err == nil {
+			rec.err = fmt.Errorf("line %d: invalid target_deadline: %w", line, err)
+		}
+		rec.mission.Targets = append(rec.mission.Targets, t)
+	}
+}
+
+func readMissionColumns(cr *csvReader, row []string, m *mission.Mission) error {
+	if v := cr.field(row, "cat_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid cat_id %q", v)
+		}
+		m.CatID = &id
+	}
+	m.State = mission.State(cr.field(row, "mission_state"))
+	if v := cr.field(row, "mission_completed"); v != "" {
+		completed, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid mission_completed value %q", v)
+		}
+		m.Completed = completed
+	}
+	for _, f := range []struct {
+		name string
+		dst  *int
+	}{
+		{"mission_priority", &m.Priority},
+		{"mission_required_experience", &m.RequiredExperience},
+	} {
+		if v := cr.field(row, f.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s %q", f.name, v)
+			}
+			*f.dst = n
+		}
+	}
+	if v := cr.field(row, "mission_preferred_breeds"); v != "" {
+		for _, b := range strings.Split(v, ";") {
+			if b = strings.TrimSpace(b); b != "" {
+				m.PreferredBreeds = append(m.PreferredBreeds, b)
+			}
+		}
+	}
+	deadline, err := parseTime(cr.field(row, "mission_deadline"))
+	if err != nil {
+		return fmt.Errorf("invalid mission_deadline: %w", err)
+	}
+	m.Deadline = deadline
+	return nil
+}
+
+// parseTime reads an optional RFC 3339 time as written by the export.
+func parseTime(v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not an RFC 3339 time", v)
+	}
+	return &t, nil
+}
@@ -0,0 +1,288 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	cat "go-test-assesment/internal/cat/domain"
+	"go-test-assesment/internal/importer/domain"
+	mission "go-test-assesment/internal/mission/domain"
+	"go-test-assesment/pkg/country"
+	"go-test-assesment/pkg/ratelimit"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	defaultBatchSize   = 100
+	maxBatchSize       = 1000
+	defaultConcurrency = 4
+	maxConcurrency     = 16
+)
+
+type ImportUsecase struct {
+	repo   domain.Repository
+	breeds cat.BreedValidator
+}
+
+func NewImportUsecase(repo domain.Repository, breeds cat.BreedValidator) *ImportUsecase {
+	return &ImportUsecase{repo: repo, breeds: breeds}
+}
+
+// Import reads cats or missions from r, validates all of them and stores
+// the valid ones in batches of opts.BatchSize, each batch in its own
+// transaction. Invalid records and the records of a batch that failed to
+// commit are listed in the report; they do not stop the import.
+//
+// An error is returned only when the input cannot be read at all, in which
+// case nothing is stored.
+func (uc *ImportUsecase) Import(ctx context.Context, r io.Reader, opts domain.Options) (*domain.Report, error) {
+	if opts.Kind != domain.KindCats && opts.Kind != domain.KindMissions {
+		return nil, domain.ErrUnknownKind
+	}
+	if opts.Format == "" {
+		opts.Format = domain.FormatCSV
+	}
+	if opts.Format != domain.FormatCSV && opts.Format != domain.FormatJSONL {
+		return nil, domain.ErrUnknownFormat
+	}
+	if opts.BatchSize <= 0 || opts.BatchSize > maxBatchSize {
+		opts.BatchSize = defaultBatchSize
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultConcurrency
+	}
+	opts.Concurrency = min(opts.Concurrency, maxConcurrency)
+
+	records, err := parse(r, opts.Kind, opts.Format)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, domain.ErrEmptyInput
+	}
+
+	if opts.Kind == domain.KindCats {
+		err = uc.validateCats(ctx, records, opts.Concurrency)
+	} else {
+		err = uc.validateMissions(ctx, records)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.Report{Kind: opts.Kind, DryRun: opts.DryRun, Records: len(records)}
+	var valid []*record
+	for _, rec := range records {
+		if rec.err == nil {
+			valid = append(valid, rec)
+		}
+	}
+	report.Valid = len(valid)
+
+	if !opts.DryRun {
+		for start := 0; start < len(valid); start += opts.BatchSize {
+			batch := valid[start:min(start+opts.BatchSize, len(valid))]
+			report.Batches++
+			if err := uc.store(ctx, opts.Kind, batch); err != nil {
+				for _, rec := range batch {
+					rec.err = fmt.Errorf("batch %d failed: %w", report.Batches, err)
+				}
+				continue
+			}
+			report.Imported += len(batch)
+			for _, rec := range batch {
+				if rec.mission != nil {
+					report.Targets += len(rec.mission.Targets)
+				}
+			}
+		}
+	}
+
+	for _, rec := range records {
+		if rec.err != nil {
+			report.Errors = append(report.Errors, domain.RowError{Line: rec.line, Name: rec.name, Error: rec.err.Error()})
+		}
+	}
+	return report, nil
+}
+
+func (uc *ImportUsecase) store(ctx context.Context, kind domain.Kind, batch []*record) error {
+	if kind == domain.KindCats {
+		cats := make([]*cat.Cat, len(batch))
+		for i, rec := range batch {
+			cats[i] = rec.cat
+		}
+		return uc.repo.StoreCats(ctx, cats)
+	}
+	missions := make([]*mission.Mission, len(batch))
+	for i, rec := range batch {
+		missions[i] = rec.mission
+	}
+	return uc.repo.StoreMissions(ctx, missions)
+}
+
+// validateCats applies the checks of cat creation. Each distinct breed is
+// looked up once, with at most concurrency lookups in flight.
+func (uc *ImportUsecase) validateCats(ctx context.Context, records []*record, concurrency int) error {
+	breeds := make(map[string]bool)
+	for _, rec := range records {
+		if rec.err != nil {
+			continue
+		}
+		switch c := rec.cat; {
+		case c.Name == "":
+			rec.err = errors.New("cat name cannot be empty")
+		case c.YearsOfExperience < 0:
+			rec.err = errors.New("years of experience cannot be negative")
+		case c.Salary < 0:
+			rec.err = cat.ErrNegativeSalary
+		default:
+			breeds[c.Breed] = true
+		}
+	}
+
+	var mu sync.Mutex
+	results := make(map[string]error, len(breeds))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+	for breed := range breeds {
+		g.Go(func() error {
+			err := uc.validateBreed(gctx, breed)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			mu.Lock()
+			results[breed] = err
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	for _, rec := range records {
+		if rec.err == nil {
+			rec.err = results[rec.cat.Breed]
+		}
+	}
+	return nil
+}
+
+// validateBreed waits out the breed API's rate limit instead of failing,
+// since an import is not interactive.
+func (uc *ImportUsecase) validateBreed(ctx context.Context, breed string) error {
+	for {
+		valid, err := uc.breeds.ValidateBreed(ctx, breed)
+		var limited *ratelimit.LimitedError
+		if errors.As(err, &limited) {
+			select {
+			case <-time.After(limited.RetryAfter):
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("error validating breed: %w", err)
+		}
+		if !valid {
+			return errors.New("invalid breed")
+		}
+		return nil
+	}
+}
+
+func (uc *ImportUsecase) validateMissions(ctx context.Context, records []*record) error {
+	var catIDs []int64
+	for _, rec := range records {
+		if rec.err != nil {
+			continue
+		}
+		if rec.err = rec.mission.ValidateRequirements(); rec.err != nil {
+			continue
+		}
+		now := time.Now()
+		if rec.err = validateTargets(rec.mission, now); rec.err != nil {
+			continue
+		}
+		if rec.err = rec.mission.InitState(now); rec.err != nil {
+			continue
+		}
+		if id := rec.mission.CatID; id != nil {
+			catIDs = append(catIDs, *id)
+		}
+	}
+	if len(catIDs) == 0 {
+		return nil
+	}
+
+	existing, err := uc.repo.ExistingCats(ctx, catIDs)
+	if err != nil {
+		return err
+	}
+	busy, err := uc.repo.BusyCats(ctx, catIDs)
+	if err != nil {
+		return err
+	}
+	// A cat is on one open mission at a time, whether it is already on one
+	// or an earlier record of the input takes it.
+	taken := make(map[int64]int)
+	for _, rec := range records {
+		if rec.err != nil || rec.mission.CatID == nil {
+			continue
+		}
+		id := *rec.mission.CatID
+		switch line, ok := taken[id]; {
+		case !existing[id]:
+			rec.err = fmt.Errorf("cat %d not found", id)
+		case rec.mission.Closed():
+		case busy[id]:
+			rec.err = fmt.Errorf("cat %d: %w", id, mission.ErrCatUnavailable)
+		case ok:
+			rec.err = fmt.Errorf("cat %d: %w: the mission on line %d takes it", id, mission.ErrCatUnavailable, line)
+		default:
+			taken[id] = rec.line
+		}
+	}
+	return nil
+}
+
+// validateTargets checks the targets of m as the mission usecase does when
+// they are added, replacing each country with its ISO code and setting its
+// status at now. Deadlines that are over are accepted, so that exports can
+// be imported again.
+func validateTargets(m *mission.Mission, now time.Time) error {
+	seen := make(map[string]bool, len(m.Targets))
+	for i := range m.Targets {
+		t := &m.Targets[i]
+		if strings.TrimSpace(t.Name) == "" {
+			return errors.New("target name cannot be empty")
+		}
+		if strings.TrimSpace(t.Country) == "" {
+			return fmt.Errorf("target %q: country cannot be empty", t.Name)
+		}
+		code, ok := country.Normalize(t.Country)
+		if !ok {
+			return fmt.Errorf("target %q: %w: %q", t.Name, mission.ErrUnknownCountry, t.Country)
+		}
+		t.Country = code
+		if seen[t.Name] {
+			return fmt.Errorf("target %q: %w", t.Name, mission.ErrDuplicateTarget)
+		}
+		if err := m.CheckTargetDeadline(t.Deadline); err != nil {
+			return fmt.Errorf("target %q: %w", t.Name, err)
+		}
+		if err := t.InitStatus(now); err != nil {
+			return fmt.Errorf("target %q: %w", t.Name, err)
+		}
+		seen[t.Name] = true
+	}
+	return nil
+}
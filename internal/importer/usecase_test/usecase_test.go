@@ -0,0 +1,376 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cat "go-test-assesment/internal/cat/domain"
+	"go-test-assesment/internal/importer/domain"
+	"go-test-assesment/internal/importer/usecase"
+	mission "go-test-assesment/internal/mission/domain"
+	"go-test-assesment/pkg/money"
+	"go-test-assesment/pkg/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	cats       [][]*cat.Cat
+	missions   [][]*mission.Mission
+	catIDs     map[int64]bool
+	busyCats   map[int64]bool
+	failBatch  int
+	storeCalls int
+}
+
+func (r *fakeRepo) StoreCats(_ context.Context, cats []*cat.Cat) error {
+	r.storeCalls++
+	if r.storeCalls == r.failBatch {
+		return errors.New("deadlock detected")
+	}
+	r.cats = append(r.cats, cats)
+	return nil
+}
+
+func (r *fakeRepo) StoreMissions(_ context.Context, missions []*mission.Mission) error {
+	r.storeCalls++
+	r.missions = append(r.missions, missions)
+	return nil
+}
+
+func (r *fakeRepo) ExistingCats(_ context.Context, ids []int64) (map[int64]bool, error) {
+	existing := map[int64]bool{}
+	for _, id := range ids {
+		if r.catIDs[id] {
+			existing[id] = true
+		}
+	}
+	return existing, nil
+}
+
+func (r *fakeRepo) BusyCats(_ context.Context, ids []int64) (map[int64]bool, error) {
+	busy := map[int64]bool{}
+	for _, id := range ids {
+		if r.busyCats[id] {
+			busy[id] = true
+		}
+	}
+	return busy, nil
+}
+
+// fakeBreeds accepts the breeds in valid, counts lookups and tracks how
+// many run at once.
+type fakeBreeds struct {
+	valid   map[string]bool
+	limited atomic.Int32
+	calls   atomic.Int32
+	active  atomic.Int32
+	peak    atomic.Int32
+}
+
+func (b *fakeBreeds) ValidateBreed(_ context.Context, breed string) (bool, error) {
+	b.calls.Add(1)
+	n := b.active.Add(1)
+	defer b.active.Add(-1)
+	for {
+		peak := b.peak.Load()
+		if n <= peak || b.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	if b.limited.Add(-1) >= 0 {
+		return false, &ratelimit.LimitedError{RetryAfter: time.Millisecond}
+	}
+	return b.valid[breed], nil
+}
+
+func TestImport_CatsCSV(t *testing.T) {
+	repo := &fakeRepo{}
+	breeds := &fakeBreeds{valid: map[string]bool{"Siamese": true, "Bengal": true}}
+	uc := usecase.NewImportUsecase(repo, breeds)
+
+	input := "id,name,years_of_experience,breed,salary\n" +
+		"1,Tom,3,Siamese,1200.50\n" +
+		"2,,1,Siamese,100\n" +
+		"3,Felix,2,Unicorn,100\n" +
+		"4,Kitty,x,Bengal,100\n" +
+		"5,Luna,1,Bengal,-1\n" +
+		"6,Simba,4,Bengal,900\n"
+	report, err := uc.Import(context.Background(), strings.NewReader(input), domain.Options{Kind: domain.KindCats})
+	require.NoError(t, err)
+
+	assert.Equal(t, 6, report.Records)
+	assert.Equal(t, 2, report.Valid)
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, 1, report.Batches)
+	assert.Equal(t, []domain.RowError{
+		{Line: 3, Error: "cat name cannot be empty"},
+		{Line: 4, Name: "Felix", Error: "invalid breed"},
+		{Line: 5, Name: "Kitty", Error: `invalid years_of_experience "x"`},
+		{Line: 6, Name: "Luna", Error: cat.ErrNegativeSalary.Error()},
+	}, report.Errors)
+	require.Len(t, repo.cats, 1)
+	assert.Equal(t, &cat.Cat{Name: "Tom", YearsOfExperience: 3, Breed: "Siamese", Salary: money.FromCents(120050)}, repo.cats[0][0])
+	// Each distinct breed of a well-formed row is looked up once.
+	assert.EqualValues(t, 3, breeds.calls.Load())
+}
+
+func TestImport_BreedLookupsAreBoundedAndRetried(t *testing.T) {
+	breeds := &fakeBreeds{valid: map[string]bool{}}
+	breeds.limited.Store(3)
+	var b strings.Builder
+	for i := range 20 {
+		b.WriteString(`{"name":"Cat","breed":"breed-` + string(rune('a'+i)) + `","salary":1}` + "\n")
+	}
+	uc := usecase.NewImportUsecase(&fakeRepo{}, breeds)
+
+	report, err := uc.Import(context.Background(), strings.NewReader(b.String()),
+		domain.Options{Kind: domain.KindCats, Format: domain.FormatJSONL, Concurrency: 3})
+	require.NoError(t, err)
+
+	assert.Len(t, report.Errors, 20)
+	assert.Equal(t, "invalid breed", report.Errors[0].Error)
+	assert.EqualValues(t, 23, breeds.calls.Load())
+	assert.LessOrEqual(t, breeds.peak.Load(), int32(3))
+}
+
+func TestImport_CanceledWhileRateLimited(t *testing.T) {
+	breeds := &fakeBreeds{}
+	breeds.limited.Store(1 << 30)
+	uc := usecase.NewImportUsecase(&fakeRepo{}, breeds)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := uc.Import(ctx, strings.NewReader("name,breed,salary\nTom,Siamese,1\n"), domain.Options{Kind: domain.KindCats})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestImport_DryRun(t *testing.T) {
+	repo := &fakeRepo{}
+	uc := usecase.NewImportUsecase(repo, &fakeBreeds{valid: map[string]bool{"Siamese": true}})
+
+	report, err := uc.Import(context.Background(), strings.NewReader("name,breed,salary\nTom,Siamese,1\n"),
+		domain.Options{Kind: domain.KindCats, DryRun: true})
+	require.NoError(t, err)
+
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 1, report.Valid)
+	assert.Zero(t, report.Imported)
+	assert.Zero(t, report.Batches)
+	assert.Zero(t, repo.storeCalls)
+}
+
+func TestImport_FailedBatchIsReported(t *testing.T) {
+	repo := &fakeRepo{failBatch: 2}
+	uc := usecase.NewImportUsecase(repo, &fakeBreeds{valid: map[string]bool{"Siamese": true}})
+	input := "name,breed,salary\nA,Siamese,1\nB,Siamese,1\nC,Siamese,1\nD,Siamese,1\nE,Siamese,1\n"
+
+	report, err := uc.Import(context.Background(), strings.NewReader(input),
+		domain.Options{Kind: domain.KindCats, BatchSize: 2})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, report.Batches)
+	assert.Equal(t, 3, report.Imported)
+	require.Len(t, report.Errors, 2)
+	assert.Equal(t, domain.RowError{Line: 4, Name: "C", Error: "batch 2 failed: deadlock detected"}, report.Errors[0])
+	assert.Equal(t, 5, report.Errors[1].Line)
+}
+
+func TestImport_MissionsCSV(t *testing.T) {
+	repo := &fakeRepo{catIDs: map[int64]bool{7: true}}
+	uc := usecase.NewImportUsecase(repo, &fakeBreeds{})
+
+	input := "mission_id,cat_id,mission_completed,target_name,target_country,target_notes,target_completed\n" +
+		"1,7,false,Alpha,UK,watch,false\n" +
+		"2,,false,,,,\n" +
+		"1,7,false,Bravo,france,,true\n" +
+		"3,9,false,Charlie,DE,,\n" +
+		"4,,false,Delta,Atlantis,,\n" +
+		"5,,false,Echo,DE,,\n" +
+		"5,,false,Echo,FR,,\n"
+	report, err := uc.Import(context.Background(), strings.NewReader(input), domain.Options{Kind: domain.KindMissions})
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, report.Records)
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, 2, report.Targets)
+	require.Len(t, report.Errors, 3)
+	assert.Equal(t, domain.RowError{Line: 5, Name: "3", Error: "cat 9 not found"}, report.Errors[0])
+	assert.Equal(t, 6, report.Errors[1].Line)
+	assert.Contains(t, report.Errors[1].Error, mission.ErrUnknownCountry.Error())
+	assert.Contains(t, report.Errors[2].Error, mission.ErrDuplicateTarget.Error())
+
+	require.Len(t, repo.missions, 1)
+	first := repo.missions[0][0]
+	assert.Equal(t, int64(7), *first.CatID)
+	require.Len(t, first.Targets, 2)
+	assert.Equal(t, "GB", first.Targets[0].Country)
+	assert.Equal(t, "FR", first.Targets[1].Country)
+	assert.True(t, first.Targets[1].Completed)
+	assert.Empty(t, repo.missions[0][1].Targets)
+}
+
+func TestImport_MissionCatAvailability(t *testing.T) {
+	repo := &fakeRepo{catIDs: map[int64]bool{1: true, 2: true, 3: true}, busyCats: map[int64]bool{2: true}}
+	uc := usecase.NewImportUsecase(repo, &fakeBreeds{})
+
+	input := "mission_id,cat_id,mission_state\n" +
+		"1,1,assigned\n" +
+		"2,1,in_progress\n" +
+		"3,2,assigned\n" +
+		"4,2,completed\n" +
+		"5,1,aborted\n" +
+		"6,3,assigned\n"
+	report, err := uc.Import(context.Background(), strings.NewReader(input), domain.Options{Kind: domain.KindMissions})
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, report.Imported)
+	require.Len(t, report.Errors, 2)
+	assert.Equal(t, domain.RowError{Line: 3, Name: "2",
+		Error: "cat 1: cat is no longer available: the mission on line 2 takes it"}, report.Errors[0])
+	assert.Equal(t, domain.RowError{Line: 4, Name: "3", Error: "cat 2: cat is no longer available"}, report.Errors[1])
+}
+
+func TestImport_MissionDeadlines(t *testing.T) {
+	repo := &fakeRepo{}
+	uc := usecase.NewImportUsecase(repo, &fakeBreeds{})
+
+	input := "mission_id,mission_deadline,target_name,target_country,target_deadline\n" +
+		"1,2025-03-01T12:00:00Z,Alpha,GB,2025-02-01T12:00:00Z\n" +
+		"2,2025-03-01T12:00:00Z,Bravo,GB,2025-04-01T12:00:00Z\n" +
+		"3,tomorrow,Charlie,GB,\n"
+	report, err := uc.Import(context.Background(), strings.NewReader(input), domain.Options{Kind: domain.KindMissions})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Imported)
+	require.Len(t, report.Errors, 2)
+	assert.Contains(t, report.Errors[0].Error, mission.ErrTargetDeadline.Error())
+	assert.Contains(t, report.Errors[1].Error, "invalid mission_deadline")
+
+	// Deadlines that are already over are kept, so exports can be imported again.
+	imported := repo.missions[0][0]
+	assert.Equal(t, time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC), *imported.Deadline)
+	assert.Equal(t, time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC), *imported.Targets[0].Deadline)
+}
+
+func TestImport_MissionRequirements(t *testing.T) {
+	repo := &fakeRepo{}
+	uc := usecase.NewImportUsecase(repo, &fakeBreeds{})
+
+	input := "mission_id,mission_priority,mission_required_experience,mission_preferred_breeds\n" +
+		"1,7,3,Bengal; ; Siamese; bengal\n" +
+		"2,11,0,\n" +
+		"3,high,0,\n"
+	report, err := uc.Import(context.Background(), strings.NewReader(input), domain.Options{Kind: domain.KindMissions})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Imported)
+	require.Len(t, report.Errors, 2)
+	assert.Equal(t, mission.ErrInvalidPriority.Error(), report.Errors[0].Error)
+	assert.Contains(t, report.Errors[1].Error, "invalid mission_priority")
+
+	imported := repo.missions[0][0]
+	assert.Equal(t, 7, imported.Priority)
+	assert.Equal(t, 3, imported.RequiredExperience)
+	assert.Equal(t, []string{"Bengal", "Siamese"}, imported.PreferredBreeds)
+}
+
+func TestImport_MissionStates(t *testing.T) {
+	repo := &fakeRepo{catIDs: map[int64]bool{4: true}}
+	uc := usecase.NewImportUsecase(repo, &fakeBreeds{})
+
+	input := "mission_id,cat_id,mission_state,mission_completed\n" +
+		"1,4,in_progress,false\n" +
+		"2,,,true\n" +
+		"3,,assigned,false\n" +
+		"4,4,aborted,true\n" +
+		"5,,paused,false\n"
+	report, err := uc.Import(context.Background(), strings.NewReader(input), domain.Options{Kind: domain.KindMissions})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, report.Imported)
+	require.Len(t, report.Errors, 3)
+	for _, e := range report.Errors {
+		assert.Contains(t, e.Error, mission.ErrInvalidState.Error())
+	}
+
+	started, completed := repo.missions[0][0], repo.missions[0][1]
+	assert.Equal(t, mission.StateInProgress, started.State)
+	assert.NotNil(t, started.StartedAt)
+	assert.NotNil(t, started.AssignedAt)
+	assert.Equal(t, mission.StateCompleted, completed.State)
+	assert.True(t, completed.Completed)
+	assert.NotNil(t, completed.CompletedAt)
+}
+
+func TestImport_TargetStatuses(t *testing.T) {
+	repo := &fakeRepo{}
+	uc := usecase.NewImportUsecase(repo, &fakeBreeds{})
+
+	input := "mission_id,target_name,target_country,target_status,target_outcome,target_completed\n" +
+		"1,Alpha,FR,escaped,left by boat,true\n" +
+		"1,Bravo,FR,,,true\n" +
+		"2,Charlie,FR,compromised,,true\n" +
+		"3,Delta,FR,captured,,false\n"
+	report, err := uc.Import(context.Background(), strings.NewReader(input), domain.Options{Kind: domain.KindMissions})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Imported)
+	require.Len(t, report.Errors, 2)
+	for _, e := range report.Errors {
+		assert.Contains(t, e.Error, mission.ErrInvalidTargetStatus.Error())
+	}
+
+	targets := repo.missions[0][0].Targets
+	require.Len(t, targets, 2)
+	assert.Equal(t, mission.TargetEscaped, targets[0].Status)
+	assert.Equal(t, "left by boat", targets[0].Outcome)
+	assert.NotNil(t, targets[0].EscapedAt)
+	assert.Equal(t, mission.TargetEliminated, targets[1].Status)
+}
+
+func TestImport_MissionsJSONL(t *testing.T) {
+	repo := &fakeRepo{}
+	uc := usecase.NewImportUsecase(repo, &fakeBreeds{})
+	input := `{"targets":[{"name":"Alpha","country":"Germany"}]}` + "\n\n" + `{"targets":` + "\n"
+
+	report, err := uc.Import(context.Background(), strings.NewReader(input),
+		domain.Options{Kind: domain.KindMissions, Format: domain.FormatJSONL})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, report.Records)
+	assert.Equal(t, 1, report.Imported)
+	require.Len(t, report.Errors, 1)
+	assert.Equal(t, 3, report.Errors[0].Line)
+	assert.Contains(t, report.Errors[0].Error, "invalid json")
+	assert.Equal(t, "DE", repo.missions[0][0].Targets[0].Country)
+}
+
+func TestImport_InvalidInput(t *testing.T) {
+	uc := usecase.NewImportUsecase(&fakeRepo{}, &fakeBreeds{})
+	tests := []struct {
+		name  string
+		input string
+		opts  domain.Options
+		want  error
+	}{
+		{"unknown kind", "", domain.Options{Kind: "dogs"}, domain.ErrUnknownKind},
+		{"unknown format", "", domain.Options{Kind: domain.KindCats, Format: "xml"}, domain.ErrUnknownFormat},
+		{"empty csv", "", domain.Options{Kind: domain.KindCats}, domain.ErrEmptyInput},
+		{"header only", "name,breed,salary\n", domain.Options{Kind: domain.KindCats}, domain.ErrEmptyInput},
+		{"missing column", "name,breed\nTom,Siamese\n", domain.Options{Kind: domain.KindCats}, domain.ErrMalformed},
+		{"bad quoting", "name,breed,salary\n\"Tom,Siamese,1\n", domain.Options{Kind: domain.KindCats}, domain.ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.Import(context.Background(), strings.NewReader(tt.input), tt.opts)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
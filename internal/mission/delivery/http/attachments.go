@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+
+	"go-test-assesment/internal/mission/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AttachmentHandler struct {
+	usecase domain.AttachmentUsecase
+}
+
+func NewAttachmentHandler(u domain.AttachmentUsecase) *AttachmentHandler {
+	return &AttachmentHandler{usecase: u}
+}
+
+func (h *AttachmentHandler) RegisterRoutes(r *gin.Engine) {
+	targets := r.Group("/targets")
+	{
+		targets.POST("/:id/attachments", h.addAttachment)
+		targets.GET("/:id/attachments", h.listAttachments)
+		targets.GET("/:id/attachments/:attachmentID", h.downloadAttachment)
+		targets.DELETE("/:id/attachments/:attachmentID", h.deleteAttachment)
+	}
+}
+
+// parseAttachmentIDs reads the target and attachment IDs of the request,
+// responding with 400 when one is invalid. The attachment ID is only read
+// when withAttachment is set.
+func parseAttachmentIDs(c *gin.Context, withAttachment bool) (targetID, id int64, ok bool) {
+	targetID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid target id"})
+		c.Error(err)
+		return 0, 0, false
+	}
+	if !withAttachment {
+		return targetID, 0, true
+	}
+	id, err = strconv.ParseInt(c.Param("attachmentID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment id"})
+		c.Error(err)
+		return 0, 0, false
+	}
+	return targetID, id, true
+}
+
+// addAttachment godoc
+// @Summary Upload Target Attachment
+// @Description Attach a photo (JPEG, PNG, GIF, WebP), PDF document or plain text file to a target. The type is sniffed from the content and the SHA-256 checksum of the content is returned; when the optional sha256 field is sent, the content must match it. Attachments cannot be added once the target is eliminated or escaped or its mission is closed.
+// @Tags Targets
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Target ID"
+// @Param file formData file true "File to attach"
+// @Param sha256 formData string false "Hex SHA-256 checksum of the file"
+// @Success 201 {object} domain.Attachment
+// @Failure 400 {object} ErrorResponse "Missing file, invalid target ID, filename or checksum, or empty file"
+// @Failure 404 {object} ErrorResponse "Target not found"
+// @Failure 409 {object} ErrorResponse "Attachments are locked"
+// @Failure 413 {object} ErrorResponse "File too large"
+// @Failure 415 {object} ErrorResponse "Unsupported file type"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /targets/{id}/attachments [post]
+func (h *AttachmentHandler) addAttachment(c *gin.Context) {
+	targetID, _, ok := parseAttachmentIDs(c, false)
+	if !ok {
+		return
+	}
+
+	fh, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	defer f.Close()
+
+	a, err := h.usecase.AddAttachment(c.Request.Context(), targetID, domain.AttachmentUpload{
+		Filename: fh.Filename,
+		Content:  f,
+		SHA256:   c.PostForm("sha256"),
+	})
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, a)
+}
+
+// listAttachments godoc
+// @Summary List Target Attachments
+// @Description Retrieve the attachments of a target, oldest first.
+// @Tags Targets
+// @Produce json
+// @Param id path int true "Target ID"
+// @Success 200 {array} domain.Attachment
+// @Failure 400 {object} ErrorResponse "Invalid target ID"
+// @Failure 404 {object} ErrorResponse "Target not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /targets/{id}/attachments [get]
+func (h *AttachmentHandler) listAttachments(c *gin.Context) {
+	targetID, _, ok := parseAttachmentIDs(c, false)
+	if !ok {
+		return
+	}
+
+	attachments, err := h.usecase.ListAttachments(c.Request.Context(), targetID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, attachments)
+}
+
+// downloadAttachment godoc
+// @Summary Download Target Attachment
+// @Description Download the content of an attachment under its original filename. The ETag is its SHA-256 checksum.
+// @Tags Targets
+// @Produce octet-stream
+// @Param id path int true "Target ID"
+// @Param attachmentID path int true "Attachment ID"
+// @Success 200 {file} binary
+// @Failure 400 {object} ErrorResponse "Invalid target or attachment ID"
+// @Failure 404 {object} ErrorResponse "Attachment not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /targets/{id}/attachments/{attachmentID} [get]
+func (h *AttachmentHandler) downloadAttachment(c *gin.Context) {
+	targetID, id, ok := parseAttachmentIDs(c, true)
+	if !ok {
+		return
+	}
+
+	a, content, err := h.usecase.OpenAttachment(c.Request.Context(), targetID, id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, a.Size, a.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename}),
+		"ETag":                   `"` + a.SHA256 + `"`,
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// deleteAttachment godoc
+// @Summary Delete Target Attachment
+// @Description Delete an attachment of a target, unless the target is eliminated or escaped or its mission is closed.
+// @Tags Targets
+// @Param id path int true "Target ID"
+// @Param attachmentID path int true "Attachment ID"
+// @Success 204 "Attachment deleted"
+// @Failure 400 {object} ErrorResponse "Invalid target or attachment ID"
+// @Failure 404 {object} ErrorResponse "Attachment not found"
+// @Failure 409 {object} ErrorResponse "Attachments are locked"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /targets/{id}/attachments/{attachmentID} [delete]
+func (h *AttachmentHandler) deleteAttachment(c *gin.Context) {
+	targetID, id, ok := parseAttachmentIDs(c, true)
+	if !ok {
+		return
+	}
+
+	if err := h.usecase.DeleteAttachment(c.Request.Context(), targetID, id); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
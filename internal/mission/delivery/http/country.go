@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"go-test-assesment/internal/mission/domain"
+	"go-test-assesment/pkg/country"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// responseLanguage picks the language of country names from the lang query
+// parameter, then Accept-Language, and announces it in Content-Language.
+func responseLanguage(c *gin.Context) language.Tag {
+	lang := country.Language(c.Query("lang"), c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", lang.String())
+	c.Header("Vary", "Accept-Language")
+	return lang
+}
+
+func nameCountries(lang language.Tag, targets []domain.Target) {
+	for i := range targets {
+		targets[i].CountryName = country.Name(targets[i].Country, lang)
+	}
+}
+
+func nameCountry(lang language.Tag, t *domain.Target) {
+	t.CountryName = country.Name(t.Country, lang)
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"go-test-assesment/internal/mission/domain"
+	"go-test-assesment/pkg/export"
+)
+
+type exportRow = domain.MissionExportRow
+
+// missionColumns lays out the CSV export; empty cells stand for missing
+// targets and unassigned missions. Preferred breeds are separated by ";".
+var missionColumns = []export.Column[exportRow]{
+	{Name: "mission_id", Value: func(r exportRow) string { return formatInt(r.MissionID) }},
+	{Name: "cat_id", Value: func(r exportRow) string { return optional(r.CatID, formatInt) }},
+	{Name: "mission_state", Value: func(r exportRow) string { return string(r.MissionState) }},
+	{Name: "mission_completed", Value: func(r exportRow) string { return strconv.FormatBool(r.MissionCompleted) }},
+	{Name: "mission_priority", Value: func(r exportRow) string { return strconv.Itoa(r.MissionPriority) }},
+	{Name: "mission_required_experience", Value: func(r exportRow) string { return strconv.Itoa(r.MissionRequiredExperience) }},
+	{Name: "mission_preferred_breeds", Value: func(r exportRow) string { return strings.Join(r.MissionPreferredBreeds, ";") }},
+	{Name: "mission_deadline", Value: func(r exportRow) string { return optional(r.MissionDeadline, formatTime) }},
+	{Name: "mission_created_at", Value: func(r exportRow) string { return formatTime(r.MissionCreatedAt) }},
+	{Name: "target_id", Value: func(r exportRow) string { return optional(r.TargetID, formatInt) }},
+	{Name: "target_name", Value: func(r exportRow) string { return optional(r.TargetName, identity) }},
+	{Name: "target_country", Value: func(r exportRow) string { return optional(r.TargetCountry, identity) }},
+	{Name: "target_notes", Value: func(r exportRow) string { return optional(r.TargetNotes, identity) }},
+	{Name: "target_status", Value: func(r exportRow) string { return optional(r.TargetStatus, formatStatus) }},
+	{Name: "target_outcome", Value: func(r exportRow) string { return optional(r.TargetOutcome, identity) }},
+	{Name: "target_completed", Value: func(r exportRow) string { return optional(r.TargetCompleted, strconv.FormatBool) }},
+	{Name: "target_deadline", Value: func(r exportRow) string { return optional(r.TargetDeadline, formatTime) }},
+	{Name: "target_updated_at", Value: func(r exportRow) string { return optional(r.TargetUpdatedAt, formatTime) }},
+}
+
+func optional[T any](v *T, format func(T) string) string {
+	if v == nil {
+		return ""
+	}
+	return format(*v)
+}
+
+func formatInt(i int64) string { return strconv.FormatInt(i, 10) }
+
+func formatTime(t time.Time) string { return t.Format(time.RFC3339) }
+
+func identity(s string) string { return s }
+
+func formatStatus(s domain.TargetStatus) string { return string(s) }
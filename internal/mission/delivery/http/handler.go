@@ -1,64 +1,177 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"go-test-assesment/internal/mission/domain"
+	"go-test-assesment/pkg/export"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	usecase domain.Usecase
+	usecase     domain.Usecase
+	redactNotes bool
 }
 
 type MissionDTO struct {
-	CatID     *int64 `json:"cat_id,omitempty" example:"123"`
-	Completed bool   `json:"completed" example:"false"`
+	CatID              *int64     `json:"cat_id,omitempty" example:"123"`
+	Completed          bool       `json:"completed" example:"false"`
+	Priority           int        `json:"priority" example:"5" minimum:"0" maximum:"10"`
+	RequiredExperience int        `json:"required_experience" example:"3" minimum:"0"`
+	PreferredBreeds    []string   `json:"preferred_breeds,omitempty" example:"Siamese,Bengal"`
+	Deadline           *time.Time `json:"deadline,omitempty" example:"2026-12-31T18:00:00Z"`
+}
+
+func (dto MissionDTO) mission(id int64) domain.Mission {
+	return domain.Mission{
+		ID:                 id,
+		CatID:              dto.CatID,
+		Completed:          dto.Completed,
+		Priority:           dto.Priority,
+		RequiredExperience: dto.RequiredExperience,
+		PreferredBreeds:    dto.PreferredBreeds,
+		Deadline:           dto.Deadline,
+	}
+}
+
+// AutoAssignDTO selects the missions to auto-assign; without IDs every
+// open unassigned mission is.
+type AutoAssignDTO struct {
+	MissionIDs []int64 `json:"mission_ids,omitempty" example:"1,2,3"`
 }
 
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+// TargetDTO describes a new target. Country may be an ISO 3166-1 alpha-2 or
+// alpha-3 code, an English name or a common alias such as "UK"; it is stored
+// as the alpha-2 code. Without a status the target is pending, or eliminated
+// when completed.
 type TargetDTO struct {
-	Name      string `json:"name" example:"Target name"`
-	Country   string `json:"country" example:"Country name"`
-	Notes     string `json:"notes,omitempty" example:"Additional notes"`
-	Completed bool   `json:"completed" example:"false"`
+	Name      string              `json:"name" example:"Target name"`
+	Country   string              `json:"country" example:"United Kingdom"`
+	Notes     string              `json:"notes,omitempty" example:"Additional notes"`
+	Status    domain.TargetStatus `json:"status,omitempty" enums:"pending,compromised,eliminated,escaped" example:"pending"`
+	Outcome   string              `json:"outcome,omitempty" example:"Spotted the cat at the border"`
+	Completed bool                `json:"completed" example:"false"`
+	Deadline  *time.Time          `json:"deadline,omitempty" example:"2026-12-24T12:00:00Z"`
+}
+
+type UpdateTargetDTO struct {
+	Notes     *string              `json:"notes,omitempty" example:"Updated notes"`
+	Status    *domain.TargetStatus `json:"status,omitempty" enums:"pending,compromised,eliminated,escaped" example:"escaped"`
+	Outcome   *string              `json:"outcome,omitempty" example:"Crossed the border before dawn"`
+	Completed *bool                `json:"completed,omitempty" example:"true"`
+	Deadline  *time.Time           `json:"deadline,omitempty" example:"2026-12-24T12:00:00Z"`
+}
+
+// TargetNoteDTO is a new entry of a target's notes journal.
+type TargetNoteDTO struct {
+	Author string `json:"author" example:"Agent Whiskers"`
+	Body   string `json:"body" example:"Seen leaving the embassy at 9pm"`
 }
 
 func NewHandler(u domain.Usecase) *Handler {
 	return &Handler{usecase: u}
 }
 
+// RedactListNotes leaves the notes out of the targets returned by the
+// mission list, the target list and target search. A single target and its
+// notes journal still carry them.
+func (h *Handler) RedactListNotes() {
+	h.redactNotes = true
+}
+
+// redact blanks the notes of targets when list notes are redacted.
+func (h *Handler) redact(targets []domain.Target) {
+	if !h.redactNotes {
+		return
+	}
+	for i := range targets {
+		targets[i].RedactNotes()
+	}
+}
+
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrMissionNotFound), errors.Is(err, domain.ErrTargetNotFound),
+		errors.Is(err, domain.ErrAttachmentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrNotesLocked), errors.Is(err, domain.ErrTargetUncomplete),
+		errors.Is(err, domain.ErrMissionCompleted), errors.Is(err, domain.ErrMissionAssigned),
+		errors.Is(err, domain.ErrMissionClosed), errors.Is(err, domain.ErrNoAvailableCat),
+		errors.Is(err, domain.ErrCatUnavailable), errors.Is(err, domain.ErrInvalidTransition),
+		errors.Is(err, domain.ErrNoCat), errors.Is(err, domain.ErrNoTargets), errors.Is(err, domain.ErrTargetsOpen),
+		errors.Is(err, domain.ErrTargetFinal), errors.Is(err, domain.ErrAttachmentsLocked):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidPage), errors.Is(err, domain.ErrQueryTooLong),
+		errors.Is(err, domain.ErrUnknownCountry), errors.Is(err, export.ErrUnknownFormat),
+		errors.Is(err, domain.ErrDeadlinePassed), errors.Is(err, domain.ErrTargetDeadline),
+		errors.Is(err, domain.ErrInvalidPriority), errors.Is(err, domain.ErrInvalidRequired),
+		errors.Is(err, domain.ErrInvalidState), errors.Is(err, domain.ErrInvalidTargetStatus),
+		errors.Is(err, domain.ErrOutcomeStatus), errors.Is(err, domain.ErrNoteEmpty),
+		errors.Is(err, domain.ErrNoteTooLong), errors.Is(err, domain.ErrNoteAuthor),
+		errors.Is(err, domain.ErrAuthorLength), errors.Is(err, domain.ErrAttachmentEmpty),
+		errors.Is(err, domain.ErrChecksumMismatch), errors.Is(err, domain.ErrInvalidFilename),
+		errors.Is(err, domain.ErrNotesEncrypted):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrAttachmentTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, domain.ErrAttachmentType):
+		return http.StatusUnsupportedMediaType
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 func (h *Handler) RegisterRoutes(r *gin.Engine) {
 	missions := r.Group("/missions")
 	{
 		missions.POST("", h.createMission)
 		missions.GET("", h.listMissions)
+		missions.POST("/auto-assign", h.autoAssignAll)
 		missions.GET("/:id", h.getMissionByID)
 		missions.PUT("/:id", h.updateMission)
 		missions.DELETE("/:id", h.deleteMission)
 		missions.POST("/:id/cat/:catID", h.assignCatToMission)
+		missions.POST("/:id/auto-assign", h.autoAssign)
+		missions.POST("/:id/start", h.startMission)
+		missions.POST("/:id/complete", h.completeMission)
+		missions.POST("/:id/fail", h.failMission)
+		missions.POST("/:id/abort", h.abortMission)
 		missions.POST("/:id/targets", h.addTargets)
+		missions.POST("/:id/targets/csv", h.addTargetsCSV)
+		missions.GET("/:id/targets", h.listTargets)
 	}
+	r.GET("/export/missions", h.exportMissions)
 
 	targets := r.Group("/targets")
 	{
+		targets.GET("/search", h.searchTargets)
+		targets.GET("/:id", h.getTargetByID)
 		targets.PUT("/:id", h.updateTarget)
+		targets.POST("/:id/complete", h.completeTarget)
+		targets.GET("/:id/notes", h.listTargetNotes)
+		targets.POST("/:id/notes", h.addTargetNote)
 		targets.DELETE("/:id", h.deleteTarget)
 	}
 }
 
 // createMission godoc
 // @Summary Create a new mission
-// @Description Create a new mission with the provided details.
+// @Description Create a new mission with the provided details. An optional deadline must be in the future. Priority (0-10), required_experience and preferred_breeds are used by auto-assignment.
 // @Tags Missions
 // @Accept json
 // @Produce json
 // @Param mission body MissionDTO true "Mission details"
+// @Param Idempotency-Key header string false "Replays the stored response when a request is retried with the same key"
 // @Success 201 {object} domain.Mission
 // @Failure 400 {object} ErrorResponse "Wrong request format"
 // @Failure 500 {object} ErrorResponse "Internal server error"
@@ -71,13 +184,9 @@ func (h *Handler) createMission(c *gin.Context) {
 		return
 	}
 
-	mission := domain.Mission{
-		CatID:     missionDTO.CatID,
-		Completed: missionDTO.Completed,
-	}
-
+	mission := missionDTO.mission(0)
 	if err := h.usecase.CreateMission(c.Request.Context(), &mission); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		c.Error(err)
 		return
 	}
@@ -91,6 +200,7 @@ func (h *Handler) createMission(c *gin.Context) {
 // @Tags Missions
 // @Produce json
 // @Param id path int true "Mission ID"
+// @Param lang query string false "Language of country names, e.g. fr; defaults to Accept-Language, then English"
 // @Success 200 {object} domain.Mission
 // @Failure 400 {object} ErrorResponse "Invalid mission ID"
 // @Failure 404 {object} ErrorResponse "Mission not found"
@@ -108,30 +218,69 @@ func (h *Handler) getMissionByID(c *gin.Context) {
 		c.Error(err)
 		return
 	}
+	nameCountries(responseLanguage(c), mission.Targets)
 	c.JSON(http.StatusOK, mission)
 }
 
 // listMissions godoc
 // @Summary List all missions
-// @Description Retrieve a list of all missions.
+// @Description Retrieve a list of all missions. A mission is overdue when it is not closed by its deadline or has a target that is not completed by the target's deadline. Notes are blanked, with notes_redacted set, when the server redacts list notes.
 // @Tags Missions
 // @Produce json
+// @Param state query string false "Only missions in this state" Enums(draft, assigned, in_progress, completed, aborted, failed)
+// @Param overdue query bool false "Only missions that are (or are not) overdue"
+// @Param completed query bool false "Only missions that are (or are not) completed"
+// @Param assigned query bool false "Only missions that have (or have no) cat"
+// @Param lang query string false "Language of country names, e.g. fr; defaults to Accept-Language, then English"
 // @Success 200 {array} domain.Mission
+// @Failure 400 {object} ErrorResponse "Invalid filter"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /missions [get]
 func (h *Handler) listMissions(c *gin.Context) {
-	missions, err := h.usecase.ListMissions(c.Request.Context())
+	filter := domain.MissionFilter{State: domain.State(c.Query("state"))}
+	if filter.State != "" && !filter.State.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid state filter"})
+		c.Error(domain.ErrInvalidState)
+		return
+	}
+	for _, f := range []struct {
+		name  string
+		field **bool
+	}{
+		{"overdue", &filter.Overdue},
+		{"completed", &filter.Completed},
+		{"assigned", &filter.Assigned},
+	} {
+		v, ok := c.GetQuery(f.name)
+		if !ok {
+			continue
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + f.name + " filter"})
+			c.Error(err)
+			return
+		}
+		*f.field = &b
+	}
+
+	missions, err := h.usecase.ListMissions(c.Request.Context(), filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		c.Error(err)
 		return
 	}
+	lang := responseLanguage(c)
+	for _, m := range missions {
+		nameCountries(lang, m.Targets)
+		h.redact(m.Targets)
+	}
 	c.JSON(http.StatusOK, missions)
 }
 
 // updateMission godoc
 // @Summary Update a mission
-// @Description Update an existing mission with the provided details.
+// @Description Update an existing mission with the provided details. A changed deadline must be in the future and no earlier than the deadline of any of its targets. Changing the cat assigns the mission anew (or makes it a draft again without one), which is not allowed once it is in progress; completed set to true completes a mission in progress with the checks of POST /missions/{id}/complete. Closed missions cannot be updated, and an update that races with a transition is rejected with 409.
 // @Tags Missions
 // @Accept json
 // @Produce json
@@ -139,6 +288,8 @@ func (h *Handler) listMissions(c *gin.Context) {
 // @Param mission body MissionDTO true "Mission details"
 // @Success 200 {object} domain.Mission
 // @Failure 400 {object} ErrorResponse "Invalid mission ID or request format"
+// @Failure 404 {object} ErrorResponse "Mission not found"
+// @Failure 409 {object} ErrorResponse "Mission closed, cat unavailable or concurrent transition"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /missions/{id} [put]
 func (h *Handler) updateMission(c *gin.Context) {
@@ -156,20 +307,9 @@ func (h *Handler) updateMission(c *gin.Context) {
 		return
 	}
 
-	mission := domain.Mission{
-		ID:        id,
-		CatID:     dto.CatID,
-		Completed: dto.Completed,
-	}
-
-	if err := c.ShouldBindJSON(&mission); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		c.Error(err)
-		return
-	}
-	mission.ID = id
+	mission := dto.mission(id)
 	if err := h.usecase.UpdateMission(c.Request.Context(), &mission); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		c.Error(err)
 		return
 	}
@@ -178,11 +318,13 @@ func (h *Handler) updateMission(c *gin.Context) {
 
 // deleteMission godoc
 // @Summary Delete a mission
-// @Description Delete a mission by its ID.
+// @Description Delete a mission by its ID, with its targets. A mission assigned to a cat cannot be deleted.
 // @Tags Missions
 // @Param id path int true "Mission ID"
 // @Success 204 "No Content"
 // @Failure 400 {object} ErrorResponse "Invalid mission ID"
+// @Failure 404 {object} ErrorResponse "Mission not found"
+// @Failure 409 {object} ErrorResponse "Mission assigned to a cat"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /missions/{id} [delete]
 func (h *Handler) deleteMission(c *gin.Context) {
@@ -193,7 +335,7 @@ func (h *Handler) deleteMission(c *gin.Context) {
 		return
 	}
 	if err := h.usecase.DeleteMission(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		c.Error(err)
 		return
 	}
@@ -201,12 +343,13 @@ func (h *Handler) deleteMission(c *gin.Context) {
 }
 
 // @Summary Assign Cat to Mission
-// @Description Assign a cat to a mission by their IDs.
+// @Description Assign a cat to a draft mission by their IDs. A cat that is on another open mission is rejected with 409.
 // @Tags Missions
 // @Param id path int true "Mission ID"
 // @Param catID path int true "Cat ID"
 // @Success 204 "No Content"
 // @Failure 400 {object} ErrorResponse "Wrong request format"
+// @Failure 409 {object} ErrorResponse "Mission already assigned or cat unavailable"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /missions/{id}/cat/{catID} [post]
 func (h *Handler) assignCatToMission(c *gin.Context) {
@@ -223,29 +366,176 @@ func (h *Handler) assignCatToMission(c *gin.Context) {
 		return
 	}
 	if err := h.usecase.AssignCatToMission(c.Request.Context(), missionID, catID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		c.Error(err)
 		return
 	}
 	c.Status(http.StatusNoContent)
 }
 
+// startMission godoc
+// @Summary Start a mission
+// @Description Move an assigned mission to in_progress. The mission needs a cat and at least one target.
+// @Tags Missions
+// @Produce json
+// @Param id path int true "Mission ID"
+// @Success 200 {object} domain.Mission
+// @Failure 400 {object} ErrorResponse "Invalid mission ID"
+// @Failure 404 {object} ErrorResponse "Mission not found"
+// @Failure 409 {object} ErrorResponse "Transition not allowed"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /missions/{id}/start [post]
+func (h *Handler) startMission(c *gin.Context) {
+	h.transition(c, domain.TransitionStart)
+}
+
+// completeMission godoc
+// @Summary Complete a mission
+// @Description Move a mission in progress to completed once all of its targets are completed.
+// @Tags Missions
+// @Produce json
+// @Param id path int true "Mission ID"
+// @Success 200 {object} domain.Mission
+// @Failure 400 {object} ErrorResponse "Invalid mission ID"
+// @Failure 404 {object} ErrorResponse "Mission not found"
+// @Failure 409 {object} ErrorResponse "Transition not allowed"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /missions/{id}/complete [post]
+func (h *Handler) completeMission(c *gin.Context) {
+	h.transition(c, domain.TransitionComplete)
+}
+
+// failMission godoc
+// @Summary Fail a mission
+// @Description Move a mission in progress to failed.
+// @Tags Missions
+// @Produce json
+// @Param id path int true "Mission ID"
+// @Success 200 {object} domain.Mission
+// @Failure 400 {object} ErrorResponse "Invalid mission ID"
+// @Failure 404 {object} ErrorResponse "Mission not found"
+// @Failure 409 {object} ErrorResponse "Transition not allowed"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /missions/{id}/fail [post]
+func (h *Handler) failMission(c *gin.Context) {
+	h.transition(c, domain.TransitionFail)
+}
+
+// abortMission godoc
+// @Summary Abort a mission
+// @Description Move a draft, assigned or in progress mission to aborted, which frees its cat.
+// @Tags Missions
+// @Produce json
+// @Param id path int true "Mission ID"
+// @Success 200 {object} domain.Mission
+// @Failure 400 {object} ErrorResponse "Invalid mission ID"
+// @Failure 404 {object} ErrorResponse "Mission not found"
+// @Failure 409 {object} ErrorResponse "Transition not allowed"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /missions/{id}/abort [post]
+func (h *Handler) abortMission(c *gin.Context) {
+	h.transition(c, domain.TransitionAbort)
+}
+
+func (h *Handler) transition(c *gin.Context, t domain.Transition) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mission id"})
+		c.Error(err)
+		return
+	}
+	mission, err := h.usecase.TransitionMission(c.Request.Context(), id, t)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	nameCountries(responseLanguage(c), mission.Targets)
+	c.JSON(http.StatusOK, mission)
+}
+
+// autoAssign godoc
+// @Summary Auto-assign a cat to a mission
+// @Description Assign the best available cat: cats on an uncompleted mission or with fewer years of experience than the mission requires are left out, then cats of a preferred breed are chosen first and, among them, the one with the lowest salary. The response explains the choice.
+// @Tags Missions
+// @Produce json
+// @Param id path int true "Mission ID"
+// @Success 200 {object} domain.AutoAssignment
+// @Failure 400 {object} ErrorResponse "Invalid mission ID"
+// @Failure 404 {object} ErrorResponse "Mission not found"
+// @Failure 409 {object} domain.AutoAssignment "No cat is available, or the mission is assigned or completed"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /missions/{id}/auto-assign [post]
+func (h *Handler) autoAssign(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mission id"})
+		c.Error(err)
+		return
+	}
+	assignment, err := h.usecase.AutoAssign(c.Request.Context(), id)
+	if errors.Is(err, domain.ErrNoAvailableCat) {
+		c.JSON(http.StatusConflict, assignment)
+		c.Error(err)
+		return
+	}
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, assignment)
+}
+
+// autoAssignAll godoc
+// @Summary Auto-assign cats to missions
+// @Description Auto-assign the given missions, or every uncompleted unassigned mission without a body, choosing cats as POST /missions/{id}/auto-assign does. Missions with a higher priority pick first, then those with the earliest deadline. Missions that could not be assigned are reported with the reason.
+// @Tags Missions
+// @Accept json
+// @Produce json
+// @Param missions body AutoAssignDTO false "Missions to assign"
+// @Success 200 {object} domain.AutoAssignReport
+// @Failure 400 {object} ErrorResponse "Invalid request format"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /missions/auto-assign [post]
+func (h *Handler) autoAssignAll(c *gin.Context) {
+	var dto AutoAssignDTO
+	if err := c.ShouldBindJSON(&dto); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	report, err := h.usecase.AutoAssignAll(c.Request.Context(), dto.MissionIDs)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
 // addTargets godoc
 // @Summary Add Targets to Mission
-// @Description Add multiple targets to a mission by its ID.
+// @Description Add multiple targets to a mission by its ID. In all_or_nothing mode (the default) any invalid or duplicate target rejects the whole batch; in best_effort mode valid targets are created and the rest are reported per item.
 // @Tags Missions
 // @Accept json
+// @Produce json
 // @Param id path int true "Mission ID"
+// @Param mode query string false "Import mode" Enums(all_or_nothing, best_effort)
 // @Param targets body []TargetDTO true "Targets to add"
-// @Success 201 "Created"
+// @Param Idempotency-Key header string false "Replays the stored response when a request is retried with the same key"
+// @Param lang query string false "Language of country names, e.g. fr; defaults to Accept-Language, then English"
+// @Success 201 {object} domain.TargetImportReport "All targets created"
+// @Success 207 {object} domain.TargetImportReport "Some targets were not created"
 // @Failure 400 {object} ErrorResponse "Invalid request format"
+// @Failure 404 {object} ErrorResponse "Mission not found"
+// @Failure 409 {object} ErrorResponse "Mission is completed"
+// @Failure 422 {object} domain.TargetImportReport "Import rejected"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /missions/{id}/targets [post]
 func (h *Handler) addTargets(c *gin.Context) {
-	missionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mission id"})
-		c.Error(err)
+	missionID, mode, ok := parseImportParams(c)
+	if !ok {
 		return
 	}
 
@@ -263,53 +553,478 @@ func (h *Handler) addTargets(c *gin.Context) {
 			Name:      t.Name,
 			Country:   t.Country,
 			Notes:     t.Notes,
+			Status:    t.Status,
+			Outcome:   t.Outcome,
 			Completed: t.Completed,
+			Deadline:  t.Deadline,
 		})
 	}
 
-	if err := h.usecase.AddTargets(c.Request.Context(), missionID, domainTargets); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	h.importTargets(c, missionID, domainTargets, mode)
+}
+
+// addTargetsCSV godoc
+// @Summary Upload Targets CSV
+// @Description Add targets to a mission from an uploaded CSV file with a header row of name,country and optional notes,status,outcome,completed,deadline columns (deadline in RFC 3339). Import modes behave as in the JSON variant; item indices refer to data rows starting at 0.
+// @Tags Missions
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Mission ID"
+// @Param mode query string false "Import mode" Enums(all_or_nothing, best_effort)
+// @Param file formData file true "CSV file"
+// @Param Idempotency-Key header string false "Replays the stored response when a request is retried with the same key"
+// @Param lang query string false "Language of country names, e.g. fr; defaults to Accept-Language, then English"
+// @Success 201 {object} domain.TargetImportReport "All targets created"
+// @Success 207 {object} domain.TargetImportReport "Some targets were not created"
+// @Failure 400 {object} ErrorResponse "Invalid request format or CSV"
+// @Failure 404 {object} ErrorResponse "Mission not found"
+// @Failure 409 {object} ErrorResponse "Mission is completed"
+// @Failure 422 {object} domain.TargetImportReport "Import rejected"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /missions/{id}/targets/csv [post]
+func (h *Handler) addTargetsCSV(c *gin.Context) {
+	missionID, mode, ok := parseImportParams(c)
+	if !ok {
+		return
+	}
+
+	fh, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	defer f.Close()
+
+	targets, err := parseTargetsCSV(f, missionID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	h.importTargets(c, missionID, targets, mode)
+}
+
+func parseImportParams(c *gin.Context) (int64, domain.ImportMode, bool) {
+	missionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mission id"})
+		c.Error(err)
+		return 0, "", false
+	}
+
+	mode := domain.ImportMode(c.DefaultQuery("mode", string(domain.ImportAllOrNothing)))
+	if mode != domain.ImportAllOrNothing && mode != domain.ImportBestEffort {
+		err := errors.New("invalid import mode")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
+		return 0, "", false
+	}
+	return missionID, mode, true
+}
+
+func (h *Handler) importTargets(c *gin.Context, missionID int64, targets []domain.Target, mode domain.ImportMode) {
+	report, err := h.usecase.AddTargets(c.Request.Context(), missionID, targets, mode)
+	if errors.Is(err, domain.ErrImportRejected) {
+		c.JSON(http.StatusUnprocessableEntity, report)
+		c.Error(err)
+		return
+	}
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	nameCountries(responseLanguage(c), report.Created)
+	if len(report.Errors) > 0 {
+		c.JSON(http.StatusMultiStatus, report)
+		return
+	}
+	c.JSON(http.StatusCreated, report)
+}
+
+// listTargets godoc
+// @Summary List Targets of Mission
+// @Description Retrieve the targets of a mission, optionally filtered by status, completion status and country. Notes are blanked, with notes_redacted set, when the server redacts list notes.
+// @Tags Targets
+// @Produce json
+// @Param id path int true "Mission ID"
+// @Param status query string false "Filter by status" Enums(pending, compromised, eliminated, escaped)
+// @Param completed query bool false "Filter by completion status"
+// @Param country query string false "Filter by country code, name or alias"
+// @Param lang query string false "Language of country names, e.g. fr; defaults to Accept-Language, then English"
+// @Success 200 {array} domain.Target
+// @Failure 400 {object} ErrorResponse "Invalid mission ID or filter"
+// @Failure 404 {object} ErrorResponse "Mission not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /missions/{id}/targets [get]
+func (h *Handler) listTargets(c *gin.Context) {
+	missionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mission id"})
+		c.Error(err)
+		return
+	}
+
+	filter, ok := targetFilter(c)
+	if !ok {
+		return
+	}
+	if v, ok := c.GetQuery("completed"); ok {
+		completed, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid completed filter"})
+			c.Error(err)
+			return
+		}
+		filter.Completed = &completed
+	}
+
+	targets, err := h.usecase.ListTargets(c.Request.Context(), missionID, filter)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	nameCountries(responseLanguage(c), targets)
+	h.redact(targets)
+	c.JSON(http.StatusOK, targets)
+}
+
+// exportMissions godoc
+// @Summary Export missions
+// @Description Download missions flattened with their targets, one row per target and one with empty target fields for a mission without targets, as CSV (with a header row), JSON Lines or a JSON array. Rows are streamed from the database in mission and target ID order. status, completed and country filter the targets as in the target list; with any of them set, missions without matching targets are left out.
+// @Tags Missions
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce json
+// @Param format query string false "Output format" Enums(csv, jsonl, json) default(csv)
+// @Param status query string false "Filter targets by status" Enums(pending, compromised, eliminated, escaped)
+// @Param completed query bool false "Filter targets by completion status"
+// @Param country query string false "Filter targets by country code, name or alias"
+// @Success 200 {array} domain.MissionExportRow
+// @Failure 400 {object} ErrorResponse "Invalid format or filter"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /export/missions [get]
+func (h *Handler) exportMissions(c *gin.Context) {
+	filter, ok := targetFilter(c)
+	if !ok {
+		return
+	}
+	completed, err := optionalBool(c, "completed")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		c.Error(err)
 		return
 	}
+	filter.Completed = completed
 
-	c.Status(http.StatusCreated)
+	err = export.Serve(c, "missions", missionColumns, func(write func(domain.MissionExportRow) error) error {
+		return h.usecase.ExportMissions(c.Request.Context(), filter, write)
+	})
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+	}
+}
+
+// targetFilter reads the status and country filters of a target list,
+// responding with 400 to an unknown status.
+func targetFilter(c *gin.Context) (domain.TargetFilter, bool) {
+	filter := domain.TargetFilter{Status: domain.TargetStatus(c.Query("status")), Country: c.Query("country")}
+	if filter.Status != "" && !filter.Status.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status filter"})
+		c.Error(domain.ErrInvalidTargetStatus)
+		return filter, false
+	}
+	return filter, true
+}
+
+// searchTargets godoc
+// @Summary Search Targets
+// @Description Search targets across all missions. q is full-text searched in the notes (web search syntax: quoted phrases, OR, -excluded); results are ranked best first with matching fragments of the notes in highlight. Without q, targets are listed by ID. q is rejected while notes are encrypted at rest. Notes are blanked, with notes_redacted set, when the server redacts list notes.
+// @Tags Targets
+// @Produce json
+// @Param q query string false "Full-text query over notes"
+// @Param country query string false "Filter by country code, name or alias"
+// @Param completed query bool false "Filter by completion status"
+// @Param assigned query bool false "Filter by whether the mission is assigned to a cat"
+// @Param cat_id query int false "Filter by the cat assigned to the mission"
+// @Param mission_id query int false "Filter by mission"
+// @Param limit query int false "Page size (1-100)" default(20)
+// @Param offset query int false "Number of results to skip" default(0)
+// @Param lang query string false "Language of country names, e.g. fr; defaults to Accept-Language, then English"
+// @Success 200 {object} domain.TargetSearchPage
+// @Failure 400 {object} ErrorResponse "Invalid filter or pagination, or q while notes are encrypted"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /targets/search [get]
+func (h *Handler) searchTargets(c *gin.Context) {
+	search, err := parseTargetSearch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	page, err := h.usecase.SearchTargets(c.Request.Context(), search)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	lang := responseLanguage(c)
+	for i := range page.Items {
+		nameCountry(lang, &page.Items[i].Target)
+		if h.redactNotes {
+			page.Items[i].RedactNotes()
+			page.Items[i].Highlight = ""
+		}
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func parseTargetSearch(c *gin.Context) (domain.TargetSearch, error) {
+	search := domain.TargetSearch{Query: c.Query("q"), Country: c.Query("country")}
+	var err error
+	if search.Completed, err = optionalBool(c, "completed"); err != nil {
+		return search, err
+	}
+	if search.Assigned, err = optionalBool(c, "assigned"); err != nil {
+		return search, err
+	}
+	if search.CatID, err = optionalInt(c, "cat_id"); err != nil {
+		return search, err
+	}
+	if search.MissionID, err = optionalInt(c, "mission_id"); err != nil {
+		return search, err
+	}
+	if search.Limit, err = intQuery(c, "limit"); err != nil {
+		return search, err
+	}
+	search.Offset, err = intQuery(c, "offset")
+	return search, err
+}
+
+func optionalBool(c *gin.Context, name string) (*bool, error) {
+	v, ok := c.GetQuery(name)
+	if !ok {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s filter", name)
+	}
+	return &b, nil
+}
+
+func optionalInt(c *gin.Context, name string) (*int64, error) {
+	v, ok := c.GetQuery(name)
+	if !ok {
+		return nil, nil
+	}
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s filter", name)
+	}
+	return &i, nil
+}
+
+func intQuery(c *gin.Context, name string) (int, error) {
+	v, ok := c.GetQuery(name)
+	if !ok {
+		return 0, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s", name)
+	}
+	return i, nil
+}
+
+// getTargetByID godoc
+// @Summary Get a target by ID
+// @Description Retrieve a single target by its ID.
+// @Tags Targets
+// @Produce json
+// @Param id path int true "Target ID"
+// @Param lang query string false "Language of country names, e.g. fr; defaults to Accept-Language, then English"
+// @Success 200 {object} domain.Target
+// @Failure 400 {object} ErrorResponse "Invalid target ID"
+// @Failure 404 {object} ErrorResponse "Target not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /targets/{id} [get]
+func (h *Handler) getTargetByID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid target id"})
+		c.Error(err)
+		return
+	}
+	target, err := h.usecase.GetTargetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	nameCountry(responseLanguage(c), target)
+	c.JSON(http.StatusOK, target)
 }
 
 // updateTarget godoc
 // @Summary Update Target
-// @Description Update an existing target by its ID.
+// @Description Update the notes, status and/or deadline of a target. status reports the outcome, with the reason in outcome: pending targets can become compromised, and pending or compromised targets eliminated or escaped, which is final. completed set to true eliminates a target that is not eliminated or escaped yet. Changed notes are appended to the target's notes journal (see POST /targets/{id}/notes) without an author. Notes are locked once the target is eliminated or escaped or its mission is closed, and a completed target cannot be marked as not completed. A new deadline must be in the future and no later than the mission's.
 // @Tags Targets
 // @Accept json
 // @Produce json
 // @Param id path int true "Target ID"
-// @Param target body domain.Target true "Target details"
+// @Param target body UpdateTargetDTO true "Fields to update"
+// @Param lang query string false "Language of country names, e.g. fr; defaults to Accept-Language, then English"
 // @Success 200 {object} domain.Target
 // @Failure 400 {object} ErrorResponse "Wrong request format or invalid target ID"
+// @Failure 404 {object} ErrorResponse "Target not found"
+// @Failure 409 {object} ErrorResponse "Target is final or mission is closed"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /targets/{id} [put]
 func (h *Handler) updateTarget(c *gin.Context) {
-	var target domain.Target
-	if err := c.ShouldBindJSON(&target); err != nil {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid target id"})
+		c.Error(err)
+		return
+	}
+
+	var dto UpdateTargetDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	if dto.Notes == nil && dto.Status == nil && dto.Outcome == nil && dto.Completed == nil && dto.Deadline == nil {
+		err := errors.New("nothing to update")
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		c.Error(err)
 		return
 	}
+
+	target, err := h.usecase.UpdateTarget(c.Request.Context(), id, domain.TargetUpdate{
+		Notes:     dto.Notes,
+		Status:    dto.Status,
+		Outcome:   dto.Outcome,
+		Completed: dto.Completed,
+		Deadline:  dto.Deadline,
+	})
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	nameCountry(responseLanguage(c), target)
+	c.JSON(http.StatusOK, target)
+}
+
+// completeTarget godoc
+// @Summary Complete Target
+// @Description Mark a target as completed, which eliminates it. Completing an eliminated or escaped target is a no-op.
+// @Tags Targets
+// @Produce json
+// @Param id path int true "Target ID"
+// @Param lang query string false "Language of country names, e.g. fr; defaults to Accept-Language, then English"
+// @Success 200 {object} domain.Target
+// @Failure 400 {object} ErrorResponse "Invalid target ID"
+// @Failure 404 {object} ErrorResponse "Target not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /targets/{id}/complete [post]
+func (h *Handler) completeTarget(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid target id"})
 		c.Error(err)
 		return
 	}
-	target.ID = id
-	if err := h.usecase.UpdateTarget(c.Request.Context(), &target); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	target, err := h.usecase.CompleteTarget(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		c.Error(err)
 		return
 	}
+	nameCountry(responseLanguage(c), target)
 	c.JSON(http.StatusOK, target)
 }
 
+// addTargetNote godoc
+// @Summary Add Target Note
+// @Description Append an entry to the notes journal of a target, which also becomes the target's notes. Entries cannot be edited or removed, and none can be added once the target is eliminated or escaped or its mission is closed.
+// @Tags Targets
+// @Accept json
+// @Produce json
+// @Param id path int true "Target ID"
+// @Param note body TargetNoteDTO true "Note"
+// @Success 201 {object} domain.TargetNote
+// @Failure 400 {object} ErrorResponse "Wrong request format, invalid target ID, missing author or empty note"
+// @Failure 404 {object} ErrorResponse "Target not found"
+// @Failure 409 {object} ErrorResponse "Notes are locked"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /targets/{id}/notes [post]
+func (h *Handler) addTargetNote(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid target id"})
+		c.Error(err)
+		return
+	}
+
+	var dto TargetNoteDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	note, err := h.usecase.AddTargetNote(c.Request.Context(), id, domain.TargetNote{Author: dto.Author, Body: dto.Body})
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, note)
+}
+
+// listTargetNotes godoc
+// @Summary List Target Notes
+// @Description Retrieve the notes journal of a target, oldest entry first. Entries without an author were written through the notes field of the target.
+// @Tags Targets
+// @Produce json
+// @Param id path int true "Target ID"
+// @Success 200 {array} domain.TargetNote
+// @Failure 400 {object} ErrorResponse "Invalid target ID"
+// @Failure 404 {object} ErrorResponse "Target not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /targets/{id}/notes [get]
+func (h *Handler) listTargetNotes(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid target id"})
+		c.Error(err)
+		return
+	}
+
+	notes, err := h.usecase.ListTargetNotes(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, notes)
+}
+
 // deleteTarget godoc
 // @Summary Delete Target
 // @Description Delete a target by its ID.
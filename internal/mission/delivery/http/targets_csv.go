@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-test-assesment/internal/mission/domain"
+)
+
+// parseTargetsCSV reads targets from CSV with a header row. The name and
+// country columns are required; notes and completed are optional.
+func parseTargetsCSV(r io.Reader, missionID int64) ([]domain.Target, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("csv file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, h := range header {
+		columns[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, required := range []string{"name", "country"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("csv header is missing %q column", required)
+		}
+	}
+	// Rows may omit trailing optional columns.
+	reader.FieldsPerRecord = -1
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var targets []domain.Target
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		t := domain.Target{
+			MissionID: missionID,
+			Name:      field(record, "name"),
+			Country:   field(record, "country"),
+			Notes:     field(record, "notes"),
+			Status:    domain.TargetStatus(field(record, "status")),
+			Outcome:   field(record, "outcome"),
+		}
+		if v := field(record, "completed"); v != "" {
+			t.Completed, err = strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: invalid completed value %q", row, v)
+			}
+		}
+		if v := field(record, "deadline"); v != "" {
+			deadline, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: invalid deadline %q, want RFC 3339", row, v)
+			}
+			t.Deadline = &deadline
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
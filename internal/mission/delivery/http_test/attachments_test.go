@@ -0,0 +1,272 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	handler "go-test-assesment/internal/mission/delivery/http"
+	"go-test-assesment/internal/mission/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockAttachmentUsecase struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentUsecase) AddAttachment(ctx context.Context, targetID int64, upload domain.AttachmentUpload) (*domain.Attachment, error) {
+	content, _ := io.ReadAll(upload.Content)
+	args := m.Called(ctx, targetID, upload.Filename, string(content), upload.SHA256)
+	if obj := args.Get(0); obj != nil {
+		return obj.(*domain.Attachment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAttachmentUsecase) ListAttachments(ctx context.Context, targetID int64) ([]domain.Attachment, error) {
+	args := m.Called(ctx, targetID)
+	if obj := args.Get(0); obj != nil {
+		return obj.([]domain.Attachment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAttachmentUsecase) OpenAttachment(ctx context.Context, targetID, id int64) (*domain.Attachment, io.ReadCloser, error) {
+	args := m.Called(ctx, targetID, id)
+	if obj := args.Get(0); obj != nil {
+		return obj.(*domain.Attachment), io.NopCloser(strings.NewReader(args.String(1))), args.Error(2)
+	}
+	return nil, nil, args.Error(2)
+}
+
+func (m *MockAttachmentUsecase) DeleteAttachment(ctx context.Context, targetID, id int64) error {
+	args := m.Called(ctx, targetID, id)
+	return args.Error(0)
+}
+
+func newAttachmentRouter(uc domain.AttachmentUsecase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handler.NewAttachmentHandler(uc).RegisterRoutes(r)
+	return r
+}
+
+func newAttachmentUpload(t *testing.T, path, filename, content string, fields map[string]string) *http.Request {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for name, value := range fields {
+		require.NoError(t, mw.WriteField(name, value))
+	}
+	if filename != "" {
+		fw, err := mw.CreateFormFile("file", filename)
+		require.NoError(t, err)
+		_, err = fw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, path, &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestAttachmentHandler_AddAttachment(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		filename   string
+		fields     map[string]string
+		setup      func(m *MockAttachmentUsecase)
+		wantStatus int
+	}{
+		{
+			name:     "success",
+			path:     "/targets/8/attachments",
+			filename: "photo.png",
+			fields:   map[string]string{"sha256": "abc"},
+			setup: func(m *MockAttachmentUsecase) {
+				m.On("AddAttachment", mock.Anything, int64(8), "photo.png", "content", "abc").
+					Return(&domain.Attachment{ID: 1, TargetID: 8, Filename: "photo.png"}, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "invalid id",
+			path:       "/targets/eight/attachments",
+			filename:   "photo.png",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing file",
+			path:       "/targets/8/attachments",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:     "not found",
+			path:     "/targets/9/attachments",
+			filename: "photo.png",
+			setup: func(m *MockAttachmentUsecase) {
+				m.On("AddAttachment", mock.Anything, int64(9), "photo.png", "content", "").Return(nil, domain.ErrTargetNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:     "locked",
+			path:     "/targets/8/attachments",
+			filename: "photo.png",
+			setup: func(m *MockAttachmentUsecase) {
+				m.On("AddAttachment", mock.Anything, int64(8), "photo.png", "content", "").Return(nil, domain.ErrAttachmentsLocked)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:     "too large",
+			path:     "/targets/8/attachments",
+			filename: "photo.png",
+			setup: func(m *MockAttachmentUsecase) {
+				m.On("AddAttachment", mock.Anything, int64(8), "photo.png", "content", "").Return(nil, domain.ErrAttachmentTooLarge)
+			},
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:     "unsupported type",
+			path:     "/targets/8/attachments",
+			filename: "photo.png",
+			setup: func(m *MockAttachmentUsecase) {
+				m.On("AddAttachment", mock.Anything, int64(8), "photo.png", "content", "").Return(nil, domain.ErrAttachmentType)
+			},
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:     "checksum mismatch",
+			path:     "/targets/8/attachments",
+			filename: "photo.png",
+			fields:   map[string]string{"sha256": "abc"},
+			setup: func(m *MockAttachmentUsecase) {
+				m.On("AddAttachment", mock.Anything, int64(8), "photo.png", "content", "abc").Return(nil, domain.ErrChecksumMismatch)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockAttachmentUsecase)
+			if tt.setup != nil {
+				tt.setup(uc)
+			}
+
+			w := httptest.NewRecorder()
+			newAttachmentRouter(uc).ServeHTTP(w, newAttachmentUpload(t, tt.path, tt.filename, "content", tt.fields))
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAttachmentHandler_ListAttachments(t *testing.T) {
+	uc := new(MockAttachmentUsecase)
+	uc.On("ListAttachments", mock.Anything, int64(8)).Return([]domain.Attachment{
+		{ID: 1, TargetID: 8, Filename: "photo.png", Key: "targets/8/secret"},
+	}, nil)
+	uc.On("ListAttachments", mock.Anything, int64(9)).Return(nil, domain.ErrTargetNotFound)
+	router := newAttachmentRouter(uc)
+
+	w := doRequest(router, http.MethodGet, "/targets/8/attachments", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list []map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, "photo.png", list[0]["filename"])
+	assert.NotContains(t, list[0], "key", "storage keys are not exposed")
+
+	assert.Equal(t, http.StatusNotFound, doRequest(router, http.MethodGet, "/targets/9/attachments", "").Code)
+	assert.Equal(t, http.StatusBadRequest, doRequest(router, http.MethodGet, "/targets/x/attachments", "").Code)
+	uc.AssertExpectations(t)
+}
+
+func TestAttachmentHandler_DownloadAttachment(t *testing.T) {
+	uc := new(MockAttachmentUsecase)
+	uc.On("OpenAttachment", mock.Anything, int64(8), int64(2)).Return(&domain.Attachment{
+		ID: 2, TargetID: 8, Filename: "embassy plan.pdf", ContentType: "application/pdf", Size: 8, SHA256: "abc123",
+	}, "%PDF-1.4", nil)
+	uc.On("OpenAttachment", mock.Anything, int64(8), int64(3)).Return(nil, "", domain.ErrAttachmentNotFound)
+	router := newAttachmentRouter(uc)
+
+	w := doRequest(router, http.MethodGet, "/targets/8/attachments/2", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "%PDF-1.4", w.Body.String())
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="embassy plan.pdf"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, `"abc123"`, w.Header().Get("ETag"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+
+	assert.Equal(t, http.StatusNotFound, doRequest(router, http.MethodGet, "/targets/8/attachments/3", "").Code)
+	assert.Equal(t, http.StatusBadRequest, doRequest(router, http.MethodGet, "/targets/8/attachments/x", "").Code)
+	uc.AssertExpectations(t)
+}
+
+func TestAttachmentHandler_DeleteAttachment(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		setup      func(m *MockAttachmentUsecase)
+		wantStatus int
+	}{
+		{
+			name: "success",
+			path: "/targets/8/attachments/2",
+			setup: func(m *MockAttachmentUsecase) {
+				m.On("DeleteAttachment", mock.Anything, int64(8), int64(2)).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "invalid attachment id",
+			path:       "/targets/8/attachments/two",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "not found",
+			path: "/targets/8/attachments/3",
+			setup: func(m *MockAttachmentUsecase) {
+				m.On("DeleteAttachment", mock.Anything, int64(8), int64(3)).Return(domain.ErrAttachmentNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "locked",
+			path: "/targets/8/attachments/2",
+			setup: func(m *MockAttachmentUsecase) {
+				m.On("DeleteAttachment", mock.Anything, int64(8), int64(2)).Return(domain.ErrAttachmentsLocked)
+			},
+			wantStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockAttachmentUsecase)
+			if tt.setup != nil {
+				tt.setup(uc)
+			}
+
+			w := doRequest(newAttachmentRouter(uc), http.MethodDelete, tt.path, "")
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			uc.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,1692 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	handler "go-test-assesment/internal/mission/delivery/http"
+	"go-test-assesment/internal/mission/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockUsecase struct {
+	mock.Mock
+}
+
+func (m *MockUsecase) CreateMission(ctx context.Context, mission *domain.Mission) error {
+	args := m.Called(ctx, mission)
+	return args.Error(0)
+}
+
+func (m *MockUsecase) GetMissionByID(ctx context.Context, id int64) (*domain.Mission, error) {
+	args := m.Called(ctx, id)
+	if obj := args.Get(0); obj != nil {
+		return obj.(*domain.Mission), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUsecase) ListMissions(ctx context.Context, filter domain.MissionFilter) ([]*domain.Mission, error) {
+	args := m.Called(ctx, filter)
+	if obj := args.Get(0); obj != nil {
+		return obj.([]*domain.Mission), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUsecase) UpdateMission(ctx context.Context, mission *domain.Mission) error {
+	args := m.Called(ctx, mission)
+	return args.Error(0)
+}
+
+func (m *MockUsecase) DeleteMission(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUsecase) GetTargetByID(ctx context.Context, id int64) (*domain.Target, error) {
+	args := m.Called(ctx, id)
+	if obj := args.Get(0); obj != nil {
+		return obj.(*domain.Target), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUsecase) ListTargets(ctx context.Context, missionID int64, filter domain.TargetFilter) ([]domain.Target, error) {
+	args := m.Called(ctx, missionID, filter)
+	if obj := args.Get(0); obj != nil {
+		return obj.([]domain.Target), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUsecase) SearchTargets(ctx context.Context, search domain.TargetSearch) (*domain.TargetSearchPage, error) {
+	args := m.Called(ctx, search)
+	if obj := args.Get(0); obj != nil {
+		return obj.(*domain.TargetSearchPage), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// ExportMissions feeds the rows given to Return to fn, then returns the error.
+func (m *MockUsecase) ExportMissions(ctx context.Context, filter domain.TargetFilter, fn func(domain.MissionExportRow) error) error {
+	args := m.Called(ctx, filter)
+	rows, _ := args.Get(0).([]domain.MissionExportRow)
+	for _, r := range rows {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockUsecase) AddTargets(ctx context.Context, missionID int64, targets []domain.Target, mode domain.ImportMode) (*domain.TargetImportReport, error) {
+	args := m.Called(ctx, missionID, targets, mode)
+	if obj := args.Get(0); obj != nil {
+		return obj.(*domain.TargetImportReport), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUsecase) UpdateTarget(ctx context.Context, id int64, update domain.TargetUpdate) (*domain.Target, error) {
+	args := m.Called(ctx, id, update)
+	if obj := args.Get(0); obj != nil {
+		return obj.(*domain.Target), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUsecase) AddTargetNote(ctx context.Context, targetID int64, note domain.TargetNote) (*domain.TargetNote, error) {
+	args := m.Called(ctx, targetID, note)
+	if obj := args.Get(0); obj != nil {
+		return obj.(*domain.TargetNote), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUsecase) ListTargetNotes(ctx context.Context, targetID int64) ([]domain.TargetNote, error) {
+	args := m.Called(ctx, targetID)
+	if obj := args.Get(0); obj != nil {
+		return obj.([]domain.TargetNote), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUsecase) CompleteTarget(ctx context.Context, id int64) (*domain.Target, error) {
+	args := m.Called(ctx, id)
+	if obj := args.Get(0); obj != nil {
+		return obj.(*domain.Target), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUsecase) DeleteTarget(ctx context.Context, targetID int64) error {
+	args := m.Called(ctx, targetID)
+	return args.Error(0)
+}
+
+func (m *MockUsecase) AssignCatToMission(ctx context.Context, missionID, catID int64) error {
+	args := m.Called(ctx, missionID, catID)
+	return args.Error(0)
+}
+
+func (m *MockUsecase) AutoAssign(ctx context.Context, missionID int64) (*domain.AutoAssignment, error) {
+	args := m.Called(ctx, missionID)
+	if obj := args.Get(0); obj != nil {
+		return obj.(*domain.AutoAssignment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUsecase) TransitionMission(ctx context.Context, id int64, t domain.Transition) (*domain.Mission, error) {
+	args := m.Called(ctx, id, t)
+	if obj := args.Get(0); obj != nil {
+		return obj.(*domain.Mission), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUsecase) AutoAssignAll(ctx context.Context, missionIDs []int64) (*domain.AutoAssignReport, error) {
+	args := m.Called(ctx, missionIDs)
+	if obj := args.Get(0); obj != nil {
+		return obj.(*domain.AutoAssignReport), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func newRouter(uc domain.Usecase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handler.NewHandler(uc).RegisterRoutes(r)
+	return r
+}
+
+func doRequest(r http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandler_CreateMission(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		setup      func(m *MockUsecase)
+		wantStatus int
+	}{
+		{
+			name: "success",
+			body: `{"cat_id":3,"completed":false}`,
+			setup: func(m *MockUsecase) {
+				m.On("CreateMission", mock.Anything, mock.MatchedBy(func(ms *domain.Mission) bool {
+					return ms.CatID != nil && *ms.CatID == 3 && !ms.Completed
+				})).Run(func(args mock.Arguments) {
+					args.Get(1).(*domain.Mission).ID = 11
+				}).Return(nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "malformed json",
+			body:       `{"cat_id":`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "wrong field type",
+			body:       `{"cat_id":"three"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "usecase error",
+			body: `{}`,
+			setup: func(m *MockUsecase) {
+				m.On("CreateMission", mock.Anything, mock.AnythingOfType("*domain.Mission")).Return(errors.New("db error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockUsecase)
+			if tt.setup != nil {
+				tt.setup(uc)
+			}
+
+			w := doRequest(newRouter(uc), http.MethodPost, "/missions", tt.body)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus == http.StatusCreated {
+				var resp domain.Mission
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, int64(11), resp.ID)
+			}
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_GetMissionByID(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		setup      func(m *MockUsecase)
+		wantStatus int
+	}{
+		{
+			name: "success",
+			path: "/missions/42",
+			setup: func(m *MockUsecase) {
+				m.On("GetMissionByID", mock.Anything, int64(42)).Return(&domain.Mission{ID: 42}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid id",
+			path:       "/missions/abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "not found",
+			path: "/missions/43",
+			setup: func(m *MockUsecase) {
+				m.On("GetMissionByID", mock.Anything, int64(43)).Return(nil, errors.New("no rows in result set"))
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockUsecase)
+			if tt.setup != nil {
+				tt.setup(uc)
+			}
+
+			w := doRequest(newRouter(uc), http.MethodGet, tt.path, "")
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_ListMissions(t *testing.T) {
+	overdue := true
+	tests := []struct {
+		name       string
+		path       string
+		setup      func(m *MockUsecase)
+		wantStatus int
+		wantCount  int
+	}{
+		{
+			name: "success",
+			path: "/missions",
+			setup: func(m *MockUsecase) {
+				m.On("ListMissions", mock.Anything, domain.MissionFilter{}).Return([]*domain.Mission{{ID: 1}, {ID: 2}}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantCount:  2,
+		},
+		{
+			name: "overdue filter",
+			path: "/missions?overdue=true",
+			setup: func(m *MockUsecase) {
+				m.On("ListMissions", mock.Anything, domain.MissionFilter{Overdue: &overdue}).
+					Return([]*domain.Mission{{ID: 1, Overdue: true}}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantCount:  1,
+		},
+		{
+			name: "state filter",
+			path: "/missions?state=in_progress",
+			setup: func(m *MockUsecase) {
+				m.On("ListMissions", mock.Anything, domain.MissionFilter{State: domain.StateInProgress}).
+					Return([]*domain.Mission{{ID: 1, State: domain.StateInProgress}}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantCount:  1,
+		},
+		{
+			name:       "invalid state filter",
+			path:       "/missions?state=paused",
+			setup:      func(m *MockUsecase) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid overdue filter",
+			path:       "/missions?overdue=soon",
+			setup:      func(m *MockUsecase) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "usecase error",
+			path: "/missions",
+			setup: func(m *MockUsecase) {
+				m.On("ListMissions", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockUsecase)
+			tt.setup(uc)
+
+			w := doRequest(newRouter(uc), http.MethodGet, tt.path, "")
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus == http.StatusOK {
+				var resp []domain.Mission
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Len(t, resp, tt.wantCount)
+			}
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_UpdateMission(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		setup      func(m *MockUsecase)
+		wantStatus int
+	}{
+		{
+			name: "success",
+			path: "/missions/5",
+			body: `{"cat_id":2,"completed":true}`,
+			setup: func(m *MockUsecase) {
+				m.On("UpdateMission", mock.Anything, mock.MatchedBy(func(ms *domain.Mission) bool {
+					return ms.ID == 5 && ms.CatID != nil && *ms.CatID == 2 && ms.Completed
+				})).Return(nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid id",
+			path:       "/missions/x",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "malformed json",
+			path:       "/missions/5",
+			body:       `{`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "closed mission",
+			path: "/missions/5",
+			body: `{"completed":true}`,
+			setup: func(m *MockUsecase) {
+				m.On("UpdateMission", mock.Anything, mock.AnythingOfType("*domain.Mission")).
+					Return(fmt.Errorf("%w: cannot update a completed mission", domain.ErrMissionClosed))
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name: "usecase error",
+			path: "/missions/5",
+			body: `{"completed":true}`,
+			setup: func(m *MockUsecase) {
+				m.On("UpdateMission", mock.Anything, mock.AnythingOfType("*domain.Mission")).
+					Return(errors.New("connection reset"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockUsecase)
+			if tt.setup != nil {
+				tt.setup(uc)
+			}
+
+			w := doRequest(newRouter(uc), http.MethodPut, tt.path, tt.body)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_DeleteMission(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		setup      func(m *MockUsecase)
+		wantStatus int
+	}{
+		{
+			name: "success",
+			path: "/missions/1",
+			setup: func(m *MockUsecase) {
+				m.On("DeleteMission", mock.Anything, int64(1)).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "invalid id",
+			path:       "/missions/1.5",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "assigned mission",
+			path: "/missions/1",
+			setup: func(m *MockUsecase) {
+				m.On("DeleteMission", mock.Anything, int64(1)).Return(domain.ErrMissionAssigned)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name: "not found",
+			path: "/missions/1",
+			setup: func(m *MockUsecase) {
+				m.On("DeleteMission", mock.Anything, int64(1)).Return(domain.ErrMissionNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "usecase error",
+			path: "/missions/1",
+			setup: func(m *MockUsecase) {
+				m.On("DeleteMission", mock.Anything, int64(1)).Return(errors.New("connection reset"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockUsecase)
+			if tt.setup != nil {
+				tt.setup(uc)
+			}
+
+			w := doRequest(newRouter(uc), http.MethodDelete, tt.path, "")
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AssignCatToMission(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		setup      func(m *MockUsecase)
+		wantStatus int
+	}{
+		{
+			name: "success",
+			path: "/missions/1/cat/42",
+			setup: func(m *MockUsecase) {
+				m.On("AssignCatToMission", mock.Anything, int64(1), int64(42)).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "invalid mission id",
+			path:       "/missions/one/cat/42",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid cat id",
+			path:       "/missions/1/cat/tom",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "usecase error",
+			path: "/missions/1/cat/42",
+			setup: func(m *MockUsecase) {
+				m.On("AssignCatToMission", mock.Anything, int64(1), int64(42)).
+					Return(errors.New("mission already assigned to a cat"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name: "cat on another mission",
+			path: "/missions/1/cat/42",
+			setup: func(m *MockUsecase) {
+				m.On("AssignCatToMission", mock.Anything, int64(1), int64(42)).Return(domain.ErrCatUnavailable)
+			},
+			wantStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockUsecase)
+			if tt.setup != nil {
+				tt.setup(uc)
+			}
+
+			w := doRequest(newRouter(uc), http.MethodPost, tt.path, "")
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AddTargets(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		setup      func(m *MockUsecase)
+		wantStatus int
+	}{
+		{
+			name: "success",
+			path: "/missions/3/targets",
+			body: `[{"name":"Boris","country":"UK","notes":"tall"},{"name":"Ivan","country":"PL"}]`,
+			setup: func(m *MockUsecase) {
+				m.On("AddTargets", mock.Anything, int64(3), mock.MatchedBy(func(ts []domain.Target) bool {
+					return len(ts) == 2 &&
+						ts[0].MissionID == 3 && ts[0].Name == "Boris" && ts[0].Notes == "tall" &&
+						ts[1].MissionID == 3 && ts[1].Country == "PL"
+				}), domain.ImportAllOrNothing).Return(&domain.TargetImportReport{
+					Created: []domain.Target{{ID: 1, Name: "Boris"}, {ID: 2, Name: "Ivan"}},
+				}, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name: "best effort with partial failure",
+			path: "/missions/3/targets?mode=best_effort",
+			body: `[{"name":"Boris","country":"UK"},{"name":"Ivan"}]`,
+			setup: func(m *MockUsecase) {
+				m.On("AddTargets", mock.Anything, int64(3), mock.AnythingOfType("[]domain.Target"), domain.ImportBestEffort).
+					Return(&domain.TargetImportReport{
+						Created: []domain.Target{{ID: 1, Name: "Boris"}},
+						Errors:  []domain.TargetImportError{{Index: 1, Name: "Ivan", Error: "target country cannot be empty"}},
+					}, nil)
+			},
+			wantStatus: http.StatusMultiStatus,
+		},
+		{
+			name: "all or nothing rejected",
+			path: "/missions/3/targets",
+			body: `[{"name":"Boris","country":"UK"},{"name":"Ivan"}]`,
+			setup: func(m *MockUsecase) {
+				m.On("AddTargets", mock.Anything, int64(3), mock.AnythingOfType("[]domain.Target"), domain.ImportAllOrNothing).
+					Return(&domain.TargetImportReport{
+						Created: []domain.Target{},
+						Errors:  []domain.TargetImportError{{Index: 1, Name: "Ivan", Error: "target country cannot be empty"}},
+					}, domain.ErrImportRejected)
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "invalid mode",
+			path:       "/missions/3/targets?mode=yolo",
+			body:       `[]`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid mission id",
+			path:       "/missions/abc/targets",
+			body:       `[]`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "malformed json",
+			path:       "/missions/3/targets",
+			body:       `[{"name":"Boris"`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "object instead of array",
+			path:       "/missions/3/targets",
+			body:       `{"name":"Boris","country":"UK"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "mission not found",
+			path: "/missions/3/targets",
+			body: `[{"name":"Boris","country":"UK"}]`,
+			setup: func(m *MockUsecase) {
+				m.On("AddTargets", mock.Anything, int64(3), mock.Anything, mock.Anything).Return(nil, domain.ErrMissionNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "mission completed",
+			path: "/missions/3/targets",
+			body: `[{"name":"Boris","country":"UK"}]`,
+			setup: func(m *MockUsecase) {
+				m.On("AddTargets", mock.Anything, int64(3), mock.Anything, mock.Anything).Return(nil, domain.ErrMissionCompleted)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name: "usecase error",
+			path: "/missions/3/targets",
+			body: `[{"name":"Boris","country":"UK"}]`,
+			setup: func(m *MockUsecase) {
+				m.On("AddTargets", mock.Anything, int64(3), mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockUsecase)
+			if tt.setup != nil {
+				tt.setup(uc)
+			}
+
+			w := doRequest(newRouter(uc), http.MethodPost, tt.path, tt.body)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func newCSVUpload(t *testing.T, path, content string) *http.Request {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fw, err := mw.CreateFormFile("file", "targets.csv")
+	assert.NoError(t, err)
+	_, err = fw.Write([]byte(content))
+	assert.NoError(t, err)
+	assert.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, path, &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestHandler_AddTargetsCSV(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		setup      func(m *MockUsecase)
+		wantStatus int
+	}{
+		{
+			name:    "success",
+			content: "name,country,notes,completed\nBoris,UK,\"tall, bald\",false\nIvan,PL\n",
+			setup: func(m *MockUsecase) {
+				m.On("AddTargets", mock.Anything, int64(3), mock.MatchedBy(func(ts []domain.Target) bool {
+					return len(ts) == 2 &&
+						ts[0].Name == "Boris" && ts[0].Notes == "tall, bald" && ts[0].MissionID == 3 &&
+						ts[1].Name == "Ivan" && ts[1].Country == "PL" && ts[1].Notes == ""
+				}), domain.ImportAllOrNothing).Return(&domain.TargetImportReport{
+					Created: []domain.Target{{ID: 1}, {ID: 2}},
+				}, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "missing country column",
+			content:    "name,notes\nBoris,tall\n",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid completed value",
+			content:    "name,country,completed\nBoris,UK,sometimes\n",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "empty file",
+			content:    "",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockUsecase)
+			if tt.setup != nil {
+				tt.setup(uc)
+			}
+
+			w := httptest.NewRecorder()
+			newRouter(uc).ServeHTTP(w, newCSVUpload(t, "/missions/3/targets/csv", tt.content))
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_ListTargets(t *testing.T) {
+	completed := false
+	tests := []struct {
+		name       string
+		path       string
+		setup      func(m *MockUsecase)
+		wantStatus int
+		wantCount  int
+	}{
+		{
+			name: "no filters",
+			path: "/missions/3/targets",
+			setup: func(m *MockUsecase) {
+				m.On("ListTargets", mock.Anything, int64(3), domain.TargetFilter{}).
+					Return([]domain.Target{{ID: 1}, {ID: 2}}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantCount:  2,
+		},
+		{
+			name: "status filter",
+			path: "/missions/3/targets?status=compromised",
+			setup: func(m *MockUsecase) {
+				m.On("ListTargets", mock.Anything, int64(3), domain.TargetFilter{Status: domain.TargetCompromised}).
+					Return([]domain.Target{{ID: 1, Status: domain.TargetCompromised}}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantCount:  1,
+		},
+		{
+			name:       "invalid status filter",
+			path:       "/missions/3/targets?status=captured",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "completed and country filters",
+			path: "/missions/3/targets?completed=false&country=France",
+			setup: func(m *MockUsecase) {
+				m.On("ListTargets", mock.Anything, int64(3), domain.TargetFilter{Completed: &completed, Country: "France"}).
+					Return([]domain.Target{{ID: 2, Country: "FR"}}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantCount:  1,
+		},
+		{
+			name: "unknown country",
+			path: "/missions/3/targets?country=Atlantis",
+			setup: func(m *MockUsecase) {
+				m.On("ListTargets", mock.Anything, int64(3), domain.TargetFilter{Country: "Atlantis"}).
+					Return(nil, fmt.Errorf("%w: %q", domain.ErrUnknownCountry, "Atlantis"))
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid mission id",
+			path:       "/missions/x/targets",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid completed filter",
+			path:       "/missions/3/targets?completed=maybe",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "mission not found",
+			path: "/missions/4/targets",
+			setup: func(m *MockUsecase) {
+				m.On("ListTargets", mock.Anything, int64(4), domain.TargetFilter{}).Return(nil, domain.ErrMissionNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "usecase error",
+			path: "/missions/3/targets",
+			setup: func(m *MockUsecase) {
+				m.On("ListTargets", mock.Anything, int64(3), domain.TargetFilter{}).Return(nil, errors.New("db error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockUsecase)
+			if tt.setup != nil {
+				tt.setup(uc)
+			}
+
+			w := doRequest(newRouter(uc), http.MethodGet, tt.path, "")
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus == http.StatusOK {
+				var resp []domain.Target
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Len(t, resp, tt.wantCount)
+			}
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_ExportMissions(t *testing.T) {
+	completed := true
+	catID, targetID := int64(5), int64(9)
+	name, country, notes := "Boris", "GB", `tall, "quiet"`
+	status, outcome := domain.TargetEscaped, "left by boat"
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	rows := []domain.MissionExportRow{
+		{MissionID: 1, CatID: &catID, MissionState: domain.StateAssigned, MissionPriority: 2, MissionRequiredExperience: 3,
+			MissionPreferredBreeds: []string{"Bengal", "Siamese"}, MissionCreatedAt: created, TargetID: &targetID, TargetName: &name,
+			TargetCountry: &country, TargetNotes: &notes, TargetStatus: &status, TargetOutcome: &outcome,
+			TargetCompleted: &completed, TargetUpdatedAt: &created},
+		{MissionID: 2, MissionState: domain.StateDraft, MissionCreatedAt: created},
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		uc := new(MockUsecase)
+		uc.On("ExportMissions", mock.Anything, domain.TargetFilter{}).Return(rows, nil)
+
+		w := doRequest(newRouter(uc), http.MethodGet, "/export/missions", "")
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, `attachment; filename="missions.csv"`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "mission_id,cat_id,mission_state,mission_completed,mission_priority,mission_required_experience,"+
+			"mission_preferred_breeds,mission_deadline,mission_created_at,target_id,target_name,"+
+			"target_country,target_notes,target_status,target_outcome,target_completed,target_deadline,target_updated_at\n"+
+			`1,5,assigned,false,2,3,Bengal;Siamese,,2025-03-01T12:00:00Z,9,Boris,GB,"tall, ""quiet""",escaped,left by boat,true,,2025-03-01T12:00:00Z`+"\n"+
+			"2,,draft,false,0,0,,,2025-03-01T12:00:00Z,,,,,,,,,\n", w.Body.String())
+		uc.AssertExpectations(t)
+	})
+
+	t.Run("json with filters", func(t *testing.T) {
+		uc := new(MockUsecase)
+		uc.On("ExportMissions", mock.Anything, domain.TargetFilter{Completed: &completed, Country: "UK"}).
+			Return(rows[:1], nil)
+
+		w := doRequest(newRouter(uc), http.MethodGet, "/export/missions?format=json&completed=true&country=UK", "")
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp []domain.MissionExportRow
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp, 1)
+		assert.Equal(t, "Boris", *resp[0].TargetName)
+		uc.AssertExpectations(t)
+	})
+
+	t.Run("unknown country", func(t *testing.T) {
+		uc := new(MockUsecase)
+		uc.On("ExportMissions", mock.Anything, domain.TargetFilter{Country: "Atlantis"}).
+			Return(nil, fmt.Errorf("%w: %q", domain.ErrUnknownCountry, "Atlantis"))
+
+		w := doRequest(newRouter(uc), http.MethodGet, "/export/missions?country=Atlantis", "")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+		assert.Empty(t, w.Header().Get("Content-Disposition"))
+	})
+
+	t.Run("invalid completed filter", func(t *testing.T) {
+		w := doRequest(newRouter(new(MockUsecase)), http.MethodGet, "/export/missions?completed=maybe", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandler_SearchTargets(t *testing.T) {
+	completed, assigned := false, true
+	catID := int64(5)
+	tests := []struct {
+		name       string
+		path       string
+		setup      func(m *MockUsecase)
+		wantStatus int
+	}{
+		{
+			name: "query with filters",
+			path: "/targets/search?q=embassy&country=France&completed=false&assigned=true&cat_id=5&limit=10&offset=20",
+			setup: func(m *MockUsecase) {
+				m.On("SearchTargets", mock.Anything, domain.TargetSearch{
+					Query: "embassy", Country: "France", Completed: &completed, Assigned: &assigned,
+					CatID: &catID, Limit: 10, Offset: 20,
+				}).Return(&domain.TargetSearchPage{
+					Items: []domain.TargetSearchResult{{
+						Target:    domain.Target{ID: 1, Country: "FR", Notes: "Seen near the embassy"},
+						Rank:      0.1,
+						Highlight: "Seen near the <mark>embassy</mark>",
+					}},
+					Total: 21, Limit: 10, Offset: 20,
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid assigned filter",
+			path:       "/targets/search?assigned=sometimes",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid offset",
+			path:       "/targets/search?offset=x",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "negative offset",
+			path: "/targets/search?offset=-1",
+			setup: func(m *MockUsecase) {
+				m.On("SearchTargets", mock.Anything, domain.TargetSearch{Offset: -1}).Return(nil, domain.ErrInvalidPage)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "query over encrypted notes",
+			path: "/targets/search?q=embassy",
+			setup: func(m *MockUsecase) {
+				m.On("SearchTargets", mock.Anything, domain.TargetSearch{Query: "embassy"}).Return(nil, domain.ErrNotesEncrypted)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockUsecase)
+			if tt.setup != nil {
+				tt.setup(uc)
+			}
+
+			w := doRequest(newRouter(uc), http.MethodGet, tt.path, "")
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus == http.StatusOK {
+				var resp domain.TargetSearchPage
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, 21, resp.Total)
+				assert.Equal(t, "Seen near the <mark>embassy</mark>", resp.Items[0].Highlight)
+				assert.Equal(t, "FR", resp.Items[0].Country)
+				assert.Equal(t, "France", resp.Items[0].CountryName)
+			}
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_RedactListNotes(t *testing.T) {
+	targets := func() []domain.Target {
+		return []domain.Target{{ID: 1, MissionID: 3, Country: "FR", Notes: "Seen near the embassy"}}
+	}
+	uc := new(MockUsecase)
+	uc.On("ListMissions", mock.Anything, domain.MissionFilter{}).Return([]*domain.Mission{{ID: 3, Targets: targets()}}, nil)
+	uc.On("ListTargets", mock.Anything, int64(3), domain.TargetFilter{}).Return(targets(), nil)
+	uc.On("SearchTargets", mock.Anything, domain.TargetSearch{Query: "embassy"}).Return(&domain.TargetSearchPage{
+		Items: []domain.TargetSearchResult{{Target: targets()[0], Highlight: "Seen near the <mark>embassy</mark>"}},
+		Total: 1,
+	}, nil)
+	uc.On("GetTargetByID", mock.Anything, int64(1)).Return(&targets()[0], nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := handler.NewHandler(uc)
+	h.RedactListNotes()
+	h.RegisterRoutes(r)
+
+	w := doRequest(r, http.MethodGet, "/missions", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var missions []domain.Mission
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &missions))
+	assert.Empty(t, missions[0].Targets[0].Notes)
+	assert.True(t, missions[0].Targets[0].NotesRedacted)
+
+	w = doRequest(r, http.MethodGet, "/missions/3/targets", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var listed []domain.Target
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Empty(t, listed[0].Notes)
+	assert.True(t, listed[0].NotesRedacted)
+
+	w = doRequest(r, http.MethodGet, "/targets/search?q=embassy", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var page domain.TargetSearchPage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Empty(t, page.Items[0].Notes)
+	assert.Empty(t, page.Items[0].Highlight)
+	assert.True(t, page.Items[0].NotesRedacted)
+
+	w = doRequest(r, http.MethodGet, "/targets/1", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var target domain.Target
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &target))
+	assert.Equal(t, "Seen near the embassy", target.Notes)
+	assert.False(t, target.NotesRedacted)
+	uc.AssertExpectations(t)
+}
+
+func TestHandler_GetTargetByID(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		setup      func(m *MockUsecase)
+		wantStatus int
+	}{
+		{
+			name: "success",
+			path: "/targets/8",
+			setup: func(m *MockUsecase) {
+				m.On("GetTargetByID", mock.Anything, int64(8)).Return(&domain.Target{ID: 8, MissionID: 3}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid id",
+			path:       "/targets/eight",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "not found",
+			path: "/targets/9",
+			setup: func(m *MockUsecase) {
+				m.On("GetTargetByID", mock.Anything, int64(9)).Return(nil, domain.ErrTargetNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "usecase error",
+			path: "/targets/8",
+			setup: func(m *MockUsecase) {
+				m.On("GetTargetByID", mock.Anything, int64(8)).Return(nil, errors.New("db error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockUsecase)
+			if tt.setup != nil {
+				tt.setup(uc)
+			}
+
+			w := doRequest(newRouter(uc), http.MethodGet, tt.path, "")
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_GetTargetByID_CountryName(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		acceptLanguage string
+		wantName       string
+		wantLanguage   string
+	}{
+		{name: "default", path: "/targets/8", wantName: "Germany", wantLanguage: "en"},
+		{name: "accept language", path: "/targets/8", acceptLanguage: "tlh, fr;q=0.9, en;q=0.8", wantName: "Allemagne", wantLanguage: "fr"},
+		{name: "lang parameter wins", path: "/targets/8?lang=es", acceptLanguage: "fr", wantName: "Alemania", wantLanguage: "es"},
+		{name: "unsupported language", path: "/targets/8?lang=tlh", wantName: "Germany", wantLanguage: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockUsecase)
+			uc.On("GetTargetByID", mock.Anything, int64(8)).Return(&domain.Target{ID: 8, Country: "DE"}, nil)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			w := httptest.NewRecorder()
+			newRouter(uc).ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			var resp domain.Target
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, "DE", resp.Country)
+			assert.Equal(t, tt.wantName, resp.CountryName)
+			assert.Equal(t, tt.wantLanguage, w.Header().Get("Content-Language"))
+		})
+	}
+}
+
+func TestHandler_UpdateTarget(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		setup      func(m *MockUsecase)
+		wantStatus int
+	}{
+		{
+			name: "success",
+			path: "/targets/8",
+			body: `{"notes":"new notes","completed":true}`,
+			setup: func(m *MockUsecase) {
+				m.On("UpdateTarget", mock.Anything, int64(8), mock.MatchedBy(func(u domain.TargetUpdate) bool {
+					return u.Notes != nil && *u.Notes == "new notes" && u.Completed != nil && *u.Completed
+				})).Return(&domain.Target{ID: 8, MissionID: 2, Notes: "new notes", Completed: true}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "status with outcome",
+			path: "/targets/8",
+			body: `{"status":"escaped","outcome":"crossed the border"}`,
+			setup: func(m *MockUsecase) {
+				m.On("UpdateTarget", mock.Anything, int64(8), mock.MatchedBy(func(u domain.TargetUpdate) bool {
+					return u.Status != nil && *u.Status == domain.TargetEscaped &&
+						u.Outcome != nil && *u.Outcome == "crossed the border"
+				})).Return(&domain.Target{ID: 8, MissionID: 2, Status: domain.TargetEscaped, Completed: true}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "final status",
+			path: "/targets/8",
+			body: `{"status":"eliminated"}`,
+			setup: func(m *MockUsecase) {
+				m.On("UpdateTarget", mock.Anything, int64(8), mock.Anything).Return(nil, domain.ErrTargetFinal)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name: "invalid status",
+			path: "/targets/8",
+			body: `{"status":"captured"}`,
+			setup: func(m *MockUsecase) {
+				m.On("UpdateTarget", mock.Anything, int64(8), mock.Anything).Return(nil, domain.ErrInvalidTargetStatus)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "mission_id in body is ignored",
+			path: "/targets/8",
+			body: `{"mission_id":99,"notes":"new notes"}`,
+			setup: func(m *MockUsecase) {
+				m.On("UpdateTarget", mock.Anything, int64(8), mock.MatchedBy(func(u domain.TargetUpdate) bool {
+					return u.Notes != nil && u.Completed == nil
+				})).Return(&domain.Target{ID: 8, MissionID: 2, Notes: "new notes"}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid id",
+			path:       "/targets/eight",
+			body:       `{"notes":"new notes"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "malformed json",
+			path:       "/targets/8",
+			body:       `notes`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "nothing to update",
+			path:       "/targets/8",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "target not found",
+			path: "/targets/8",
+			body: `{"notes":"new notes"}`,
+			setup: func(m *MockUsecase) {
+				m.On("UpdateTarget", mock.Anything, int64(8), mock.Anything).Return(nil, domain.ErrTargetNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "notes locked",
+			path: "/targets/8",
+			body: `{"notes":"new notes"}`,
+			setup: func(m *MockUsecase) {
+				m.On("UpdateTarget", mock.Anything, int64(8), mock.Anything).Return(nil, domain.ErrNotesLocked)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name: "un-complete rejected",
+			path: "/targets/8",
+			body: `{"completed":false}`,
+			setup: func(m *MockUsecase) {
+				m.On("UpdateTarget", mock.Anything, int64(8), mock.Anything).Return(nil, domain.ErrTargetUncomplete)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name: "usecase error",
+			path: "/targets/8",
+			body: `{"notes":"new notes"}`,
+			setup: func(m *MockUsecase) {
+				m.On("UpdateTarget", mock.Anything, int64(8), mock.Anything).Return(nil, errors.New("db error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockUsecase)
+			if tt.setup != nil {
+				tt.setup(uc)
+			}
+
+			w := doRequest(newRouter(uc), http.MethodPut, tt.path, tt.body)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_CompleteTarget(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		setup      func(m *MockUsecase)
+		wantStatus int
+	}{
+		{
+			name: "success",
+			path: "/targets/8/complete",
+			setup: func(m *MockUsecase) {
+				m.On("CompleteTarget", mock.Anything, int64(8)).Return(&domain.Target{ID: 8, Completed: true}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid id",
+			path:       "/targets/eight/complete",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "not found",
+			path: "/targets/9/complete",
+			setup: func(m *MockUsecase) {
+				m.On("CompleteTarget", mock.Anything, int64(9)).Return(nil, domain.ErrTargetNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockUsecase)
+			if tt.setup != nil {
+				tt.setup(uc)
+			}
+
+			w := doRequest(newRouter(uc), http.MethodPost, tt.path, "")
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_DeleteTarget(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		setup      func(m *MockUsecase)
+		wantStatus int
+	}{
+		{
+			name: "success",
+			path: "/targets/8",
+			setup: func(m *MockUsecase) {
+				m.On("DeleteTarget", mock.Anything, int64(8)).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "invalid id",
+			path:       "/targets/eight",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "usecase error",
+			path: "/targets/8",
+			setup: func(m *MockUsecase) {
+				m.On("DeleteTarget", mock.Anything, int64(8)).Return(errors.New("cannot delete a completed target"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockUsecase)
+			if tt.setup != nil {
+				tt.setup(uc)
+			}
+
+			w := doRequest(newRouter(uc), http.MethodDelete, tt.path, "")
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AutoAssign(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		setup      func(m *MockUsecase)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "success",
+			path: "/missions/1/auto-assign",
+			setup: func(m *MockUsecase) {
+				m.On("AutoAssign", mock.Anything, int64(1)).Return(&domain.AutoAssignment{
+					MissionID: 1, Cat: &domain.Candidate{ID: 3, Name: "Max"}, Explanation: "Chose Max", Considered: 1, Eligible: 1,
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"explanation":"Chose Max"`,
+		},
+		{
+			name: "no cat available",
+			path: "/missions/1/auto-assign",
+			setup: func(m *MockUsecase) {
+				m.On("AutoAssign", mock.Anything, int64(1)).Return(&domain.AutoAssignment{
+					MissionID: 1, Explanation: "No cat is available.", Error: domain.ErrNoAvailableCat.Error(),
+				}, domain.ErrNoAvailableCat)
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   `"explanation":"No cat is available."`,
+		},
+		{
+			name: "already assigned",
+			path: "/missions/1/auto-assign",
+			setup: func(m *MockUsecase) {
+				m.On("AutoAssign", mock.Anything, int64(1)).Return(nil, domain.ErrMissionAssigned)
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   domain.ErrMissionAssigned.Error(),
+		},
+		{
+			name: "mission not found",
+			path: "/missions/1/auto-assign",
+			setup: func(m *MockUsecase) {
+				m.On("AutoAssign", mock.Anything, int64(1)).Return(nil, domain.ErrMissionNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "invalid id",
+			path:       "/missions/abc/auto-assign",
+			setup:      func(m *MockUsecase) {},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockUsecase)
+			tt.setup(uc)
+
+			w := doRequest(newRouter(uc), http.MethodPost, tt.path, "")
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_TransitionMission(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		setup      func(m *MockUsecase)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "start",
+			path: "/missions/1/start",
+			setup: func(m *MockUsecase) {
+				m.On("TransitionMission", mock.Anything, int64(1), domain.TransitionStart).
+					Return(&domain.Mission{ID: 1, State: domain.StateInProgress}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"state":"in_progress"`,
+		},
+		{
+			name: "complete",
+			path: "/missions/1/complete",
+			setup: func(m *MockUsecase) {
+				m.On("TransitionMission", mock.Anything, int64(1), domain.TransitionComplete).
+					Return(&domain.Mission{ID: 1, State: domain.StateCompleted, Completed: true}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"completed":true`,
+		},
+		{
+			name: "fail",
+			path: "/missions/1/fail",
+			setup: func(m *MockUsecase) {
+				m.On("TransitionMission", mock.Anything, int64(1), domain.TransitionFail).
+					Return(&domain.Mission{ID: 1, State: domain.StateFailed}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"state":"failed"`,
+		},
+		{
+			name: "abort",
+			path: "/missions/1/abort",
+			setup: func(m *MockUsecase) {
+				m.On("TransitionMission", mock.Anything, int64(1), domain.TransitionAbort).
+					Return(&domain.Mission{ID: 1, State: domain.StateAborted}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"state":"aborted"`,
+		},
+		{
+			name: "targets open",
+			path: "/missions/1/complete",
+			setup: func(m *MockUsecase) {
+				m.On("TransitionMission", mock.Anything, int64(1), domain.TransitionComplete).
+					Return(nil, fmt.Errorf("cannot complete mission: %w", domain.ErrTargetsOpen))
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   domain.ErrTargetsOpen.Error(),
+		},
+		{
+			name: "not allowed",
+			path: "/missions/1/start",
+			setup: func(m *MockUsecase) {
+				m.On("TransitionMission", mock.Anything, int64(1), domain.TransitionStart).
+					Return(nil, domain.ErrInvalidTransition)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name: "mission not found",
+			path: "/missions/1/abort",
+			setup: func(m *MockUsecase) {
+				m.On("TransitionMission", mock.Anything, int64(1), domain.TransitionAbort).
+					Return(nil, domain.ErrMissionNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "invalid id",
+			path:       "/missions/abc/start",
+			setup:      func(m *MockUsecase) {},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockUsecase)
+			tt.setup(uc)
+
+			w := doRequest(newRouter(uc), http.MethodPost, tt.path, "")
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AutoAssignAll(t *testing.T) {
+	report := &domain.AutoAssignReport{Assigned: 1, Results: []domain.AutoAssignment{{MissionID: 2}}}
+	tests := []struct {
+		name       string
+		body       string
+		setup      func(m *MockUsecase)
+		wantStatus int
+	}{
+		{
+			name: "all open missions",
+			setup: func(m *MockUsecase) {
+				m.On("AutoAssignAll", mock.Anything, []int64(nil)).Return(report, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "given missions",
+			body: `{"mission_ids":[2,5]}`,
+			setup: func(m *MockUsecase) {
+				m.On("AutoAssignAll", mock.Anything, []int64{2, 5}).Return(report, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid body",
+			body:       `{"mission_ids":"all"}`,
+			setup:      func(m *MockUsecase) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "usecase error",
+			setup: func(m *MockUsecase) {
+				m.On("AutoAssignAll", mock.Anything, []int64(nil)).Return(nil, errors.New("db error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockUsecase)
+			tt.setup(uc)
+
+			w := doRequest(newRouter(uc), http.MethodPost, "/missions/auto-assign", tt.body)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus == http.StatusOK {
+				var resp domain.AutoAssignReport
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, *report, resp)
+			}
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AddTargetNote(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		setup      func(m *MockUsecase)
+		wantStatus int
+	}{
+		{
+			name: "success",
+			path: "/targets/8/notes",
+			body: `{"author": "Whiskers", "body": "left by the back door"}`,
+			setup: func(m *MockUsecase) {
+				m.On("AddTargetNote", mock.Anything, int64(8), domain.TargetNote{Author: "Whiskers", Body: "left by the back door"}).
+					Return(&domain.TargetNote{ID: 1, TargetID: 8, Author: "Whiskers", Body: "left by the back door"}, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "invalid id",
+			path:       "/targets/eight/notes",
+			body:       `{"author": "Whiskers", "body": "gone"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid body",
+			path:       "/targets/8/notes",
+			body:       `{"author": 1}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "missing author",
+			path: "/targets/8/notes",
+			body: `{"body": "gone"}`,
+			setup: func(m *MockUsecase) {
+				m.On("AddTargetNote", mock.Anything, int64(8), domain.TargetNote{Body: "gone"}).Return(nil, domain.ErrNoteAuthor)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "not found",
+			path: "/targets/9/notes",
+			body: `{"author": "Whiskers", "body": "gone"}`,
+			setup: func(m *MockUsecase) {
+				m.On("AddTargetNote", mock.Anything, int64(9), mock.Anything).Return(nil, domain.ErrTargetNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "locked",
+			path: "/targets/8/notes",
+			body: `{"author": "Whiskers", "body": "gone"}`,
+			setup: func(m *MockUsecase) {
+				m.On("AddTargetNote", mock.Anything, int64(8), mock.Anything).Return(nil, domain.ErrNotesLocked)
+			},
+			wantStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockUsecase)
+			if tt.setup != nil {
+				tt.setup(uc)
+			}
+
+			w := doRequest(newRouter(uc), http.MethodPost, tt.path, tt.body)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_ListTargetNotes(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		setup      func(m *MockUsecase)
+		wantStatus int
+		wantLen    int
+	}{
+		{
+			name: "success",
+			path: "/targets/8/notes",
+			setup: func(m *MockUsecase) {
+				m.On("ListTargetNotes", mock.Anything, int64(8)).Return([]domain.TargetNote{
+					{ID: 1, TargetID: 8, Body: "first"},
+					{ID: 2, TargetID: 8, Author: "Whiskers", Body: "second"},
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantLen:    2,
+		},
+		{
+			name:       "invalid id",
+			path:       "/targets/eight/notes",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "not found",
+			path: "/targets/9/notes",
+			setup: func(m *MockUsecase) {
+				m.On("ListTargetNotes", mock.Anything, int64(9)).Return(nil, domain.ErrTargetNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockUsecase)
+			if tt.setup != nil {
+				tt.setup(uc)
+			}
+
+			w := doRequest(newRouter(uc), http.MethodGet, tt.path, "")
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus == http.StatusOK {
+				var notes []domain.TargetNote
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &notes))
+				assert.Len(t, notes, tt.wantLen)
+			}
+			uc.AssertExpectations(t)
+		})
+	}
+}
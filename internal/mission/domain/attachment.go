@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// Attachment is a file attached to a target. Its content lives in a blob
+// store under Key; SHA256 is the hex checksum of the content and
+// ContentType is sniffed from it rather than taken from the client.
+type Attachment struct {
+	ID          int64     `json:"id"`
+	TargetID    int64     `json:"target_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	Key         string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AttachmentUpload is a file to attach to a target. SHA256, when set, is
+// the hex checksum computed by the client, which the content must match.
+type AttachmentUpload struct {
+	Filename string
+	Content  io.Reader
+	SHA256   string
+}
+
+const MaxFilenameLength = 255
+
+// AttachmentTypes are the content types that can be attached: photos, PDF
+// documents and plain text.
+var AttachmentTypes = []string{
+	"image/jpeg",
+	"image/png",
+	"image/gif",
+	"image/webp",
+	"application/pdf",
+	"text/plain; charset=utf-8",
+}
+
+var (
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrAttachmentsLocked  = errors.New("cannot change attachments because target or mission is completed")
+	ErrAttachmentEmpty    = errors.New("attachment is empty")
+	ErrAttachmentTooLarge = errors.New("attachment is too large")
+	ErrAttachmentType     = errors.New("unsupported attachment type")
+	ErrChecksumMismatch   = errors.New("attachment does not match its checksum")
+	ErrInvalidFilename    = errors.New("invalid attachment filename")
+)
+
+type AttachmentRepository interface {
+	// AddAttachment stores a, filling in its ID and time. It returns
+	// ErrAttachmentsLocked if the target is completed or its mission closed.
+	AddAttachment(ctx context.Context, a *Attachment) error
+	GetAttachment(ctx context.Context, targetID, id int64) (*Attachment, error)
+	// ListAttachments returns the attachments of a target, oldest first.
+	ListAttachments(ctx context.Context, targetID int64) ([]Attachment, error)
+	// DeleteAttachment removes an attachment of a target. It returns
+	// ErrAttachmentsLocked if the target is completed or its mission closed.
+	DeleteAttachment(ctx context.Context, targetID, id int64) error
+	// ListDeletedBlobs returns up to limit storage keys of deleted
+	// attachments, however they were deleted, whose content may still be in
+	// the store.
+	ListDeletedBlobs(ctx context.Context, limit int) ([]string, error)
+	// ForgetDeletedBlobs drops keys from the deleted ones once their content
+	// is gone.
+	ForgetDeletedBlobs(ctx context.Context, keys []string) error
+}
+
+type AttachmentUsecase interface {
+	// AddAttachment stores an upload for a target, as long as its
+	// attachments are not locked.
+	AddAttachment(ctx context.Context, targetID int64, upload AttachmentUpload) (*Attachment, error)
+	ListAttachments(ctx context.Context, targetID int64) ([]Attachment, error)
+	// OpenAttachment returns an attachment of a target with its content,
+	// which the caller must close.
+	OpenAttachment(ctx context.Context, targetID, id int64) (*Attachment, io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, targetID, id int64) error
+}
@@ -2,51 +2,355 @@ package domain
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"strings"
 	"time"
+
+	"go-test-assesment/pkg/money"
+)
+
+var (
+	ErrMissionNotFound  = errors.New("mission not found")
+	ErrTargetNotFound   = errors.New("target not found")
+	ErrNotesLocked      = errors.New("cannot update notes because target or mission is completed")
+	ErrTargetUncomplete = errors.New("cannot mark a completed target as not completed")
+	ErrMissionCompleted = errors.New("cannot add targets to a completed mission")
+	ErrDuplicateTarget  = errors.New("target with this name already exists in the mission")
+	ErrImportRejected   = errors.New("import rejected: one or more targets are invalid")
+	ErrInvalidPage      = errors.New("offset cannot be negative")
+	ErrQueryTooLong     = errors.New("search query is too long")
+	ErrUnknownCountry   = errors.New("unknown country")
+	ErrDeadlinePassed   = errors.New("deadline is in the past")
+	ErrTargetDeadline   = errors.New("target deadline is after the mission deadline")
+	ErrInvalidPriority  = errors.New("priority must be between 0 and 10")
+	ErrInvalidRequired  = errors.New("required experience cannot be negative")
+	ErrMissionAssigned  = errors.New("mission already assigned to a cat")
+	ErrMissionClosed    = errors.New("mission is already closed")
+	ErrNoAvailableCat   = errors.New("no available cat meets the mission requirements")
+	// ErrCatUnavailable is returned when a cat took another mission, or was
+	// deleted, after it was chosen.
+	ErrCatUnavailable = errors.New("cat is no longer available")
+)
+
+// MaxPriority is the highest mission priority; missions default to 0.
+const MaxPriority = 10
+
+type ImportMode string
+
+const (
+	// ImportAllOrNothing creates every target or none of them.
+	ImportAllOrNothing ImportMode = "all_or_nothing"
+	// ImportBestEffort creates the valid targets and reports the rest.
+	ImportBestEffort ImportMode = "best_effort"
 )
 
+// Mission is an operation for one cat. State follows the lifecycle in
+// state.go, with a timestamp for each state the mission entered; Completed
+// is kept for older clients and is true in the completed state only.
+// Overdue and Progress are computed when the mission is read; see
+// MarkOverdue and CountProgress. Priority,
+// RequiredExperience and PreferredBreeds guide auto-assignment: higher
+// priority missions pick first, and only cats with at least
+// RequiredExperience years qualify.
 type Mission struct {
-	ID        int64     `json:"id"`
-	CatID     *int64    `json:"cat_id,omitempty"`
-	Completed bool      `json:"completed"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Targets   []Target  `json:"targets,omitempty"`
+	ID                 int64      `json:"id"`
+	CatID              *int64     `json:"cat_id,omitempty"`
+	State              State      `json:"state"`
+	Completed          bool       `json:"completed"`
+	AssignedAt         *time.Time `json:"assigned_at,omitempty"`
+	StartedAt          *time.Time `json:"started_at,omitempty"`
+	CompletedAt        *time.Time `json:"completed_at,omitempty"`
+	AbortedAt          *time.Time `json:"aborted_at,omitempty"`
+	FailedAt           *time.Time `json:"failed_at,omitempty"`
+	Priority           int        `json:"priority"`
+	RequiredExperience int        `json:"required_experience"`
+	PreferredBreeds    []string   `json:"preferred_breeds"`
+	Deadline           *time.Time `json:"deadline,omitempty"`
+	Overdue            bool       `json:"overdue"`
+	Progress           Progress   `json:"progress"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	Targets            []Target   `json:"targets,omitempty"`
 }
 
+// Target is a mission target. Country is an ISO 3166-1 alpha-2 code;
+// CountryName is only filled in responses, in the client's language.
+// A target's deadline cannot be later than its mission's. Status follows
+// status.go, with Outcome giving the reason for it and a timestamp for each
+// status the target entered; Completed is true in a terminal status.
 type Target struct {
-	ID        int64     `json:"id"`
-	MissionID int64     `json:"mission_id"`
-	Name      string    `json:"name"`
-	Country   string    `json:"country"`
-	Notes     string    `json:"notes"`
-	Completed bool      `json:"completed"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID            int64        `json:"id"`
+	MissionID     int64        `json:"mission_id"`
+	Name          string       `json:"name"`
+	Country       string       `json:"country"`
+	CountryName   string       `json:"country_name,omitempty"`
+	Notes         string       `json:"notes"`
+	NotesRedacted bool         `json:"notes_redacted,omitempty"`
+	Status        TargetStatus `json:"status"`
+	Outcome       string       `json:"outcome,omitempty"`
+	Completed     bool         `json:"completed"`
+	CompromisedAt *time.Time   `json:"compromised_at,omitempty"`
+	EliminatedAt  *time.Time   `json:"eliminated_at,omitempty"`
+	EscapedAt     *time.Time   `json:"escaped_at,omitempty"`
+	Deadline      *time.Time   `json:"deadline,omitempty"`
+	Overdue       bool         `json:"overdue"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+// RedactNotes blanks the notes of t and marks them as redacted.
+func (t *Target) RedactNotes() {
+	t.Notes, t.NotesRedacted = "", true
+}
+
+// MarkOverdue sets Overdue as of now: a target is overdue when it is not
+// completed by its deadline.
+func (t *Target) MarkOverdue(now time.Time) {
+	t.Overdue = !t.Completed && t.Deadline != nil && t.Deadline.Before(now)
+}
+
+// MarkOverdue sets Overdue on m and its targets as of now. A mission is
+// overdue when it is not closed by its deadline or when it has an overdue
+// target; nothing in a closed mission is overdue.
+func (m *Mission) MarkOverdue(now time.Time) {
+	closed := m.Closed()
+	m.Overdue = !closed && m.Deadline != nil && m.Deadline.Before(now)
+	for i := range m.Targets {
+		m.Targets[i].MarkOverdue(now)
+		m.Targets[i].Overdue = m.Targets[i].Overdue && !closed
+		m.Overdue = m.Overdue || m.Targets[i].Overdue
+	}
+}
+
+// ValidateRequirements checks the assignment requirements of m and
+// normalizes its preferred breeds, dropping blanks and duplicates whatever
+// their case.
+func (m *Mission) ValidateRequirements() error {
+	if m.Priority < 0 || m.Priority > MaxPriority {
+		return ErrInvalidPriority
+	}
+	if m.RequiredExperience < 0 {
+		return ErrInvalidRequired
+	}
+	breeds := make([]string, 0, len(m.PreferredBreeds))
+	for _, b := range m.PreferredBreeds {
+		b = strings.TrimSpace(b)
+		if b != "" && !slices.ContainsFunc(breeds, func(s string) bool { return strings.EqualFold(s, b) }) {
+			breeds = append(breeds, b)
+		}
+	}
+	m.PreferredBreeds = breeds
+	return nil
+}
+
+// CheckTargetDeadline reports whether a target deadline fits in m.
+func (m *Mission) CheckTargetDeadline(deadline *time.Time) error {
+	if deadline != nil && m.Deadline != nil && deadline.After(*m.Deadline) {
+		return ErrTargetDeadline
+	}
+	return nil
+}
+
+type TargetImportError struct {
+	Index int    `json:"index"`
+	Name  string `json:"name,omitempty"`
+	Error string `json:"error"`
+}
+
+type TargetImportReport struct {
+	Created []Target            `json:"created"`
+	Errors  []TargetImportError `json:"errors,omitempty"`
+}
+
+// TargetUpdate holds the client-mutable fields of a target; nil fields are
+// left unchanged. Outcome is only taken along with Status.
+type TargetUpdate struct {
+	Notes     *string
+	Status    *TargetStatus
+	Outcome   *string
+	Completed *bool
+	Deadline  *time.Time
+}
+
+// MissionFilter selects missions; Overdue selects missions that are (or are
+// not) overdue at Now, Assigned those that have (or have no) cat.
+type MissionFilter struct {
+	State     State
+	Completed *bool
+	Assigned  *bool
+	Overdue   *bool
+	Now       time.Time
+}
+
+// Candidate is a cat considered for auto-assignment. ActiveMissionID is the
+// uncompleted mission the cat is on, if any.
+type Candidate struct {
+	ID                int64        `json:"id"`
+	Name              string       `json:"name"`
+	Breed             string       `json:"breed"`
+	YearsOfExperience int          `json:"years_of_experience"`
+	Salary            money.Amount `json:"salary" swaggertype:"number"`
+	ActiveMissionID   *int64       `json:"-"`
+}
+
+// AutoAssignment explains how a cat was chosen for a mission: of the
+// Considered cats, Busy were on an active mission and Inexperienced lacked
+// the required experience. Cat is nil and Error set when none qualified.
+type AutoAssignment struct {
+	MissionID     int64      `json:"mission_id"`
+	Cat           *Candidate `json:"cat,omitempty"`
+	Explanation   string     `json:"explanation"`
+	Considered    int        `json:"considered"`
+	Busy          int        `json:"busy"`
+	Inexperienced int        `json:"inexperienced"`
+	Eligible      int        `json:"eligible"`
+	Error         string     `json:"error,omitempty"`
+}
+
+// AutoAssignReport lists the outcome for each mission of a batch, in the
+// order they were assigned.
+type AutoAssignReport struct {
+	Assigned   int              `json:"assigned"`
+	Unassigned int              `json:"unassigned"`
+	Results    []AutoAssignment `json:"results"`
+}
+
+type TargetFilter struct {
+	Status    TargetStatus
+	Completed *bool
+	Country   string
+}
+
+// TargetSearch selects targets across missions. Query is full-text searched
+// in notes; Assigned selects targets whose mission has (or has no) cat.
+type TargetSearch struct {
+	Query     string
+	Country   string
+	Completed *bool
+	Assigned  *bool
+	CatID     *int64
+	MissionID *int64
+	Limit     int
+	Offset    int
+}
+
+// TargetSearchResult is a matched target. Highlight holds the best matching
+// fragments of its notes with matched words wrapped in <mark> tags.
+type TargetSearchResult struct {
+	Target
+	Rank      float64 `json:"rank"`
+	Highlight string  `json:"highlight,omitempty"`
+}
+
+type TargetSearchPage struct {
+	Items  []TargetSearchResult `json:"items"`
+	Total  int                  `json:"total"`
+	Limit  int                  `json:"limit"`
+	Offset int                  `json:"offset"`
+}
+
+// MissionExportRow is a mission joined with one of its targets. Missions
+// without targets appear once with the target fields empty.
+type MissionExportRow struct {
+	MissionID                 int64         `json:"mission_id"`
+	CatID                     *int64        `json:"cat_id"`
+	MissionState              State         `json:"mission_state"`
+	MissionCompleted          bool          `json:"mission_completed"`
+	MissionPriority           int           `json:"mission_priority"`
+	MissionRequiredExperience int           `json:"mission_required_experience"`
+	MissionPreferredBreeds    []string      `json:"mission_preferred_breeds"`
+	MissionDeadline           *time.Time    `json:"mission_deadline"`
+	MissionCreatedAt          time.Time     `json:"mission_created_at"`
+	TargetID                  *int64        `json:"target_id"`
+	TargetName                *string       `json:"target_name"`
+	TargetCountry             *string       `json:"target_country"`
+	TargetNotes               *string       `json:"target_notes"`
+	TargetStatus              *TargetStatus `json:"target_status"`
+	TargetOutcome             *string       `json:"target_outcome"`
+	TargetCompleted           *bool         `json:"target_completed"`
+	TargetDeadline            *time.Time    `json:"target_deadline"`
+	TargetUpdatedAt           *time.Time    `json:"target_updated_at"`
 }
 
 type Repository interface {
+	// CreateMission stores mission, returning ErrCatUnavailable if it is
+	// open and its cat is missing or on another open mission.
 	CreateMission(ctx context.Context, mission *Mission) error
 	GetMissionByID(ctx context.Context, id int64) (*Mission, error)
-	ListMissions(ctx context.Context) ([]*Mission, error)
-	UpdateMission(ctx context.Context, mission *Mission) error
+	ListMissions(ctx context.Context, filter MissionFilter) ([]*Mission, error)
+	// UpdateMission stores mission if it is still in state from, returning
+	// ErrInvalidTransition otherwise, so that it cannot undo a concurrent
+	// transition. A cat new to the open mission must be free, as for
+	// AssignCat, or ErrCatUnavailable is returned.
+	UpdateMission(ctx context.Context, mission *Mission, from State) error
+	// DeleteMission deletes a mission with its targets, returning
+	// ErrMissionAssigned if it has a cat.
 	DeleteMission(ctx context.Context, id int64) error
 	GetTargetByID(ctx context.Context, id int64) (*Target, error)
-	AddTargets(ctx context.Context, targets []Target) error
+	ListTargets(ctx context.Context, missionID int64, filter TargetFilter) ([]Target, error)
+	// ExportMissions calls fn for every mission and target row in mission
+	// and target ID order as rows are read, stopping at the first error fn
+	// returns. A target filter leaves out missions without matching targets.
+	ExportMissions(ctx context.Context, filter TargetFilter, fn func(MissionExportRow) error) error
+	// SearchTargets returns one page of matching targets, best matches first,
+	// and the total number of matches.
+	SearchTargets(ctx context.Context, search TargetSearch) ([]TargetSearchResult, int, error)
+	// AddTargets inserts targets in one transaction, filling in their IDs and
+	// reporting name conflicts by index. With atomic set, any conflict rolls
+	// the whole insert back.
+	AddTargets(ctx context.Context, targets []Target, atomic bool) ([]TargetImportError, error)
+	// UpdateTarget stores target, adding its notes to the journal when they
+	// changed.
 	UpdateTarget(ctx context.Context, target *Target) error
 	DeleteTarget(ctx context.Context, id int64) error
+	// AddTargetNote appends note to the journal of its target and makes it
+	// the target's notes, filling in its ID and time. It returns
+	// ErrNotesLocked if the target is completed or its mission closed.
+	AddTargetNote(ctx context.Context, note *TargetNote) error
+	// ListTargetNotes returns the journal of a target, oldest entry first.
+	ListTargetNotes(ctx context.Context, targetID int64) ([]TargetNote, error)
+
+	// ListCandidates returns every cat with the mission it is on, if any.
+	ListCandidates(ctx context.Context) ([]Candidate, error)
+	// AssignCat stores the cat, state and assignment time of m if it is still
+	// an unassigned draft and the cat is not on another open mission,
+	// returning ErrMissionAssigned or ErrCatUnavailable otherwise. Concurrent
+	// calls for one cat are serialized.
+	AssignCat(ctx context.Context, m *Mission) error
+	// UpdateState stores the state of m and its timestamps if the mission is
+	// still in state from, returning ErrInvalidTransition otherwise.
+	UpdateState(ctx context.Context, m *Mission, from State) error
 }
 
 type Usecase interface {
 	CreateMission(ctx context.Context, mission *Mission) error
 	GetMissionByID(ctx context.Context, id int64) (*Mission, error)
-	ListMissions(ctx context.Context) ([]*Mission, error)
+	ListMissions(ctx context.Context, filter MissionFilter) ([]*Mission, error)
 	UpdateMission(ctx context.Context, mission *Mission) error
 	DeleteMission(ctx context.Context, id int64) error
 
-	AddTargets(ctx context.Context, missionID int64, targets []Target) error
-	UpdateTarget(ctx context.Context, target *Target) error
+	GetTargetByID(ctx context.Context, id int64) (*Target, error)
+	ListTargets(ctx context.Context, missionID int64, filter TargetFilter) ([]Target, error)
+	SearchTargets(ctx context.Context, search TargetSearch) (*TargetSearchPage, error)
+	ExportMissions(ctx context.Context, filter TargetFilter, fn func(MissionExportRow) error) error
+	AddTargets(ctx context.Context, missionID int64, targets []Target, mode ImportMode) (*TargetImportReport, error)
+	UpdateTarget(ctx context.Context, id int64, update TargetUpdate) (*Target, error)
+	CompleteTarget(ctx context.Context, id int64) (*Target, error)
 	DeleteTarget(ctx context.Context, targetID int64) error
+	// AddTargetNote appends a note to the journal of a target, as long as its
+	// notes are not locked.
+	AddTargetNote(ctx context.Context, targetID int64, note TargetNote) (*TargetNote, error)
+	ListTargetNotes(ctx context.Context, targetID int64) ([]TargetNote, error)
 
 	AssignCatToMission(ctx context.Context, missionID, catID int64) error
+	// AutoAssign assigns the best available cat to the mission. When no cat
+	// qualifies it returns ErrNoAvailableCat along with the explanation.
+	AutoAssign(ctx context.Context, missionID int64) (*AutoAssignment, error)
+	// AutoAssignAll auto-assigns the given missions, or every open unassigned
+	// mission when missionIDs is empty, highest priority first.
+	AutoAssignAll(ctx context.Context, missionIDs []int64) (*AutoAssignReport, error)
+	// TransitionMission applies t to the mission and returns it; see
+	// Mission.Apply.
+	TransitionMission(ctx context.Context, id int64, t Transition) (*Mission, error)
 }
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// TargetNote is an entry of a target's notes journal. Entries are only ever
+// appended; the Notes of a target is its latest entry. Author is empty for
+// notes written through the notes field of a target.
+type TargetNote struct {
+	ID        int64     `json:"id"`
+	TargetID  int64     `json:"target_id"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+const (
+	MaxNoteLength   = 10000
+	MaxAuthorLength = 100
+)
+
+var (
+	ErrNoteEmpty    = errors.New("note cannot be empty")
+	ErrNoteTooLong  = fmt.Errorf("note cannot be longer than %d characters", MaxNoteLength)
+	ErrNoteAuthor   = errors.New("note author is required")
+	ErrAuthorLength = fmt.Errorf("note author cannot be longer than %d characters", MaxAuthorLength)
+	// ErrNotesEncrypted is returned when searching notes that are stored
+	// encrypted, which full-text search cannot look into.
+	ErrNotesEncrypted = errors.New("notes are encrypted and cannot be searched")
+)
+
+// Validate trims the author and body of n and checks them.
+func (n *TargetNote) Validate() error {
+	n.Author, n.Body = strings.TrimSpace(n.Author), strings.TrimSpace(n.Body)
+	switch {
+	case n.Author == "":
+		return ErrNoteAuthor
+	case utf8.RuneCountInString(n.Author) > MaxAuthorLength:
+		return ErrAuthorLength
+	case n.Body == "":
+		return ErrNoteEmpty
+	case utf8.RuneCountInString(n.Body) > MaxNoteLength:
+		return ErrNoteTooLong
+	}
+	return nil
+}
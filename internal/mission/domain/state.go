@@ -0,0 +1,196 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// State is a step of the mission lifecycle:
+//
+//	draft --assign--> assigned --start--> in_progress --complete--> completed
+//	                                                  --fail------> failed
+//	draft, assigned, in_progress --abort--> aborted
+//
+// Completed, aborted and failed missions are closed and cannot change.
+type State string
+
+const (
+	StateDraft      State = "draft"
+	StateAssigned   State = "assigned"
+	StateInProgress State = "in_progress"
+	StateCompleted  State = "completed"
+	StateAborted    State = "aborted"
+	StateFailed     State = "failed"
+)
+
+// States lists every state in lifecycle order.
+var States = []State{StateDraft, StateAssigned, StateInProgress, StateCompleted, StateAborted, StateFailed}
+
+func (s State) Valid() bool { return slices.Contains(States, s) }
+
+// Closed reports whether s is a final state.
+func (s State) Closed() bool {
+	return s == StateCompleted || s == StateAborted || s == StateFailed
+}
+
+type Transition string
+
+const (
+	TransitionAssign   Transition = "assign"
+	TransitionStart    Transition = "start"
+	TransitionComplete Transition = "complete"
+	TransitionFail     Transition = "fail"
+	TransitionAbort    Transition = "abort"
+)
+
+var (
+	ErrInvalidState      = errors.New("invalid mission state")
+	ErrInvalidTransition = errors.New("transition is not allowed in the mission's state")
+	ErrNoCat             = errors.New("mission has no cat")
+	ErrNoTargets         = errors.New("mission has no targets")
+	ErrTargetsOpen       = errors.New("mission has targets that are neither eliminated nor escaped")
+)
+
+var transitions = map[Transition]struct {
+	from  []State
+	to    State
+	guard func(m *Mission) error
+}{
+	TransitionAssign: {from: []State{StateDraft}, to: StateAssigned, guard: func(m *Mission) error {
+		if m.CatID == nil {
+			return ErrNoCat
+		}
+		return nil
+	}},
+	TransitionStart: {from: []State{StateAssigned}, to: StateInProgress, guard: func(m *Mission) error {
+		if m.CatID == nil {
+			return ErrNoCat
+		}
+		if len(m.Targets) == 0 {
+			return ErrNoTargets
+		}
+		return nil
+	}},
+	TransitionComplete: {from: []State{StateInProgress}, to: StateCompleted, guard: func(m *Mission) error {
+		for i := range m.Targets {
+			if !m.Targets[i].Terminal() {
+				return ErrTargetsOpen
+			}
+		}
+		return nil
+	}},
+	TransitionFail:  {from: []State{StateInProgress}, to: StateFailed},
+	TransitionAbort: {from: []State{StateDraft, StateAssigned, StateInProgress}, to: StateAborted},
+}
+
+// Transitions lists the transitions that can be requested for a mission.
+var Transitions = []Transition{TransitionAssign, TransitionStart, TransitionComplete, TransitionFail, TransitionAbort}
+
+// CurrentState is the state of m. Missions built without one, such as by
+// older callers, are draft, assigned or completed according to their cat
+// and Completed.
+func (m *Mission) CurrentState() State {
+	switch {
+	case m.State != "":
+		return m.State
+	case m.Completed:
+		return StateCompleted
+	case m.CatID != nil:
+		return StateAssigned
+	default:
+		return StateDraft
+	}
+}
+
+// Closed reports whether m is completed, aborted or failed. The notes of
+// the targets of a closed mission are locked.
+func (m *Mission) Closed() bool { return m.CurrentState().Closed() }
+
+// Apply performs transition t at now if the current state allows it and its
+// guard passes: a mission is assigned once it has a cat, started once it
+// also has targets and completed once all of them are eliminated or escaped.
+func (m *Mission) Apply(t Transition, now time.Time) error {
+	tr, ok := transitions[t]
+	if !ok {
+		return fmt.Errorf("%w: unknown transition %q", ErrInvalidTransition, t)
+	}
+	from := m.CurrentState()
+	if !slices.Contains(tr.from, from) {
+		return fmt.Errorf("%w: cannot %s a mission that is %s", ErrInvalidTransition, t, from)
+	}
+	if tr.guard != nil {
+		if err := tr.guard(m); err != nil {
+			return fmt.Errorf("cannot %s mission: %w", t, err)
+		}
+	}
+	m.setState(tr.to, now)
+	return nil
+}
+
+// InitState sets the state of a new mission at now. Without one it is
+// draft, assigned or, for missions recorded after the fact, completed
+// according to its cat and Completed; a given state must suit the mission as
+// if it had been reached through the transitions.
+func (m *Mission) InitState(now time.Time) error {
+	state := m.State
+	if state == "" {
+		state = m.CurrentState()
+	}
+	if !state.Valid() {
+		return fmt.Errorf("%w %q", ErrInvalidState, state)
+	}
+	if m.Completed && state != StateCompleted {
+		return fmt.Errorf("%w: %s contradicts completed", ErrInvalidState, state)
+	}
+	needsCat := state == StateAssigned || state == StateInProgress || state == StateFailed
+	if m.CatID == nil && needsCat {
+		return fmt.Errorf("%w: %s requires a cat", ErrInvalidState, state)
+	}
+	if m.CatID != nil && state == StateDraft {
+		return fmt.Errorf("%w: %s cannot have a cat", ErrInvalidState, state)
+	}
+	if m.CatID != nil {
+		m.AssignedAt = &now
+	}
+	m.setState(state, now)
+	return nil
+}
+
+// Reassign changes the cat of an open mission at now: without a cat it is a
+// draft again, with another one it is assigned anew. The cat of a mission
+// in progress cannot change.
+func (m *Mission) Reassign(catID *int64, now time.Time) error {
+	state := m.CurrentState()
+	if state.Closed() || state == StateInProgress {
+		return fmt.Errorf("%w: cannot change the cat of a mission that is %s", ErrInvalidTransition, state)
+	}
+	m.CatID = catID
+	if catID == nil {
+		m.setState(StateDraft, now)
+	} else {
+		m.setState(StateAssigned, now)
+	}
+	return nil
+}
+
+// setState moves m to s, stamping the time it entered s.
+func (m *Mission) setState(s State, now time.Time) {
+	m.State = s
+	m.Completed = s == StateCompleted
+	switch s {
+	case StateDraft:
+		m.AssignedAt = nil
+	case StateAssigned:
+		m.AssignedAt = &now
+	case StateInProgress:
+		m.StartedAt = &now
+	case StateCompleted:
+		m.CompletedAt = &now
+	case StateAborted:
+		m.AbortedAt = &now
+	case StateFailed:
+		m.FailedAt = &now
+	}
+}
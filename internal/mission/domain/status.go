@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// TargetStatus is the outcome of a target as reported from the field:
+//
+//	pending --> compromised --> eliminated
+//	        |               `-> escaped
+//	        `-> eliminated, escaped
+//
+// A compromised target noticed the cat but is still being pursued.
+// Eliminated and escaped are terminal: the status cannot change any more,
+// the notes are locked and the target counts as completed.
+type TargetStatus string
+
+const (
+	TargetPending     TargetStatus = "pending"
+	TargetCompromised TargetStatus = "compromised"
+	TargetEliminated  TargetStatus = "eliminated"
+	TargetEscaped     TargetStatus = "escaped"
+)
+
+// TargetStatuses lists every target status, pending first.
+var TargetStatuses = []TargetStatus{TargetPending, TargetCompromised, TargetEliminated, TargetEscaped}
+
+func (s TargetStatus) Valid() bool { return slices.Contains(TargetStatuses, s) }
+
+// Terminal reports whether s is a final status.
+func (s TargetStatus) Terminal() bool {
+	return s == TargetEliminated || s == TargetEscaped
+}
+
+var (
+	ErrInvalidTargetStatus = errors.New("invalid target status")
+	ErrTargetFinal         = errors.New("target status is final")
+	ErrOutcomeStatus       = errors.New("outcome must be given with a status")
+)
+
+// CurrentStatus is the status of t. Targets built without one are pending
+// or, when Completed, eliminated.
+func (t *Target) CurrentStatus() TargetStatus {
+	switch {
+	case t.Status != "":
+		return t.Status
+	case t.Completed:
+		return TargetEliminated
+	default:
+		return TargetPending
+	}
+}
+
+// Terminal reports whether t is eliminated or escaped.
+func (t *Target) Terminal() bool { return t.CurrentStatus().Terminal() }
+
+// InitStatus sets the status of a new target at now, from Completed when it
+// has none.
+func (t *Target) InitStatus(now time.Time) error {
+	status := t.CurrentStatus()
+	if !status.Valid() {
+		return fmt.Errorf("%w %q", ErrInvalidTargetStatus, status)
+	}
+	if t.Completed && !status.Terminal() {
+		return fmt.Errorf("%w: a %s target cannot be completed", ErrInvalidTargetStatus, status)
+	}
+	t.setStatus(status, t.Outcome, now)
+	return nil
+}
+
+// SetStatus reports the outcome of t at now. Setting the current status
+// again only replaces the outcome; a target cannot go back to pending, and
+// a terminal status cannot change.
+func (t *Target) SetStatus(s TargetStatus, outcome string, now time.Time) error {
+	if !s.Valid() {
+		return fmt.Errorf("%w %q", ErrInvalidTargetStatus, s)
+	}
+	current := t.CurrentStatus()
+	if current.Terminal() {
+		return fmt.Errorf("%w: the target is already %s", ErrTargetFinal, current)
+	}
+	if s == current {
+		t.Status, t.Outcome = s, outcome
+		return nil
+	}
+	if s == TargetPending {
+		return fmt.Errorf("%w: a %s target cannot be pending again", ErrInvalidTargetStatus, current)
+	}
+	t.setStatus(s, outcome, now)
+	return nil
+}
+
+// setStatus moves t to s, stamping the time it entered s.
+func (t *Target) setStatus(s TargetStatus, outcome string, now time.Time) {
+	t.Status, t.Outcome = s, outcome
+	t.Completed = s.Terminal()
+	switch s {
+	case TargetCompromised:
+		t.CompromisedAt = &now
+	case TargetEliminated:
+		t.EliminatedAt = &now
+	case TargetEscaped:
+		t.EscapedAt = &now
+	}
+}
+
+// Progress summarizes the targets of a mission: Terminal of Targets are
+// eliminated or escaped, and Percent is that share rounded down.
+type Progress struct {
+	Targets     int `json:"targets"`
+	Terminal    int `json:"terminal"`
+	Eliminated  int `json:"eliminated"`
+	Escaped     int `json:"escaped"`
+	Compromised int `json:"compromised"`
+	Percent     int `json:"percent"`
+}
+
+// CountProgress sets Progress from the targets of m.
+func (m *Mission) CountProgress() {
+	p := Progress{Targets: len(m.Targets)}
+	for i := range m.Targets {
+		switch m.Targets[i].CurrentStatus() {
+		case TargetEliminated:
+			p.Eliminated++
+		case TargetEscaped:
+			p.Escaped++
+		case TargetCompromised:
+			p.Compromised++
+		}
+	}
+	p.Terminal = p.Eliminated + p.Escaped
+	if p.Targets > 0 {
+		p.Percent = p.Terminal * 100 / p.Targets
+	}
+	m.Progress = p
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-test-assesment/internal/mission/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *MissionPostgres) AddAttachment(ctx context.Context, a *domain.Attachment) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO target_attachments (target_id, filename, content_type, size, sha256, storage_key)
+		SELECT t.id, $2, $3, $4, $5, $6 FROM targets t
+		WHERE t.id = $1 AND `+unlockedTarget+`
+		RETURNING id, created_at`,
+		a.TargetID, a.Filename, a.ContentType, a.Size, a.SHA256, a.Key).Scan(&a.ID, &a.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrAttachmentsLocked
+	}
+	return err
+}
+
+func (r *MissionPostgres) GetAttachment(ctx context.Context, targetID, id int64) (*domain.Attachment, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, target_id, filename, content_type, size, sha256, storage_key, created_at
+		FROM target_attachments WHERE id = $1 AND target_id = $2`, id, targetID)
+	if err != nil {
+		return nil, err
+	}
+	a, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByPos[domain.Attachment])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrAttachmentNotFound
+	}
+	return a, err
+}
+
+func (r *MissionPostgres) ListAttachments(ctx context.Context, targetID int64) ([]domain.Attachment, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, target_id, filename, content_type, size, sha256, storage_key, created_at
+		FROM target_attachments WHERE target_id = $1
+		ORDER BY created_at, id`, targetID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[domain.Attachment])
+}
+
+func (r *MissionPostgres) DeleteAttachment(ctx context.Context, targetID, id int64) error {
+	res, err := r.pool.Exec(ctx, `
+		DELETE FROM target_attachments a USING targets t
+		WHERE a.id = $1 AND a.target_id = $2 AND t.id = a.target_id AND `+unlockedTarget, id, targetID)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() > 0 {
+		return nil
+	}
+	// Either the attachment is gone or its target got locked meanwhile.
+	var exists bool
+	err = r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM target_attachments WHERE id = $1 AND target_id = $2)`,
+		id, targetID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return domain.ErrAttachmentsLocked
+	}
+	return domain.ErrAttachmentNotFound
+}
+
+// ListDeletedBlobs reads the keys that a trigger queues as attachments are
+// deleted, including along with their target or mission.
+func (r *MissionPostgres) ListDeletedBlobs(ctx context.Context, limit int) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT storage_key FROM attachment_blob_deletions
+		ORDER BY created_at, storage_key LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func (r *MissionPostgres) ForgetDeletedBlobs(ctx context.Context, keys []string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM attachment_blob_deletions WHERE storage_key = ANY($1)`, keys)
+	return err
+}
@@ -3,33 +3,128 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
+	event "go-test-assesment/internal/event/domain"
 	"go-test-assesment/internal/mission/domain"
+	"go-test-assesment/pkg/envelope"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type MissionPostgres struct {
 	pool *pgxpool.Pool
+	keys *envelope.Keyring
 }
 
 func NewMissionPostgres(pool *pgxpool.Pool) *MissionPostgres {
-	return &MissionPostgres{pool: pool}
+	return NewMissionPostgresWithKeys(pool, nil)
 }
 
+// NewMissionPostgresWithKeys seals target notes with keys as they are
+// written and opens them as they are read, so that they are only stored
+// encrypted. Every stored note records whether it is sealed, so notes stored
+// in plaintext before are still read; nil keys store them in plaintext.
+func NewMissionPostgresWithKeys(pool *pgxpool.Pool, keys *envelope.Keyring) *MissionPostgres {
+	return &MissionPostgres{pool: pool, keys: keys}
+}
+
+// missionColumns are the columns scanned by scanMission, from missions m.
+const missionColumns = `m.id, m.cat_id, m.state, m.completed, m.assigned_at, m.started_at,
+	m.completed_at, m.aborted_at, m.failed_at, m.priority, m.required_experience,
+	m.preferred_breeds, m.deadline, m.created_at, m.updated_at`
+
+func scanMission(row pgx.Row, m *domain.Mission) error {
+	return row.Scan(&m.ID, &m.CatID, &m.State, &m.Completed, &m.AssignedAt, &m.StartedAt,
+		&m.CompletedAt, &m.AbortedAt, &m.FailedAt, &m.Priority, &m.RequiredExperience,
+		&m.PreferredBreeds, &m.Deadline, &m.CreatedAt, &m.UpdatedAt)
+}
+
+// targetColumns are the columns scanned by scanTarget, from targets t.
+const targetColumns = `t.id, t.mission_id, t.name, t.country, t.notes, t.notes_sealed, t.status, t.outcome,
+	t.completed, t.compromised_at, t.eliminated_at, t.escaped_at, t.deadline, t.created_at, t.updated_at`
+
+// scanTarget scans targetColumns into t, followed by extra, and opens its
+// notes.
+func (r *MissionPostgres) scanTarget(row pgx.Row, t *domain.Target, extra ...any) error {
+	var sealed bool
+	err := row.Scan(append([]any{&t.ID, &t.MissionID, &t.Name, &t.Country, &t.Notes, &sealed, &t.Status,
+		&t.Outcome, &t.Completed, &t.CompromisedAt, &t.EliminatedAt, &t.EscapedAt, &t.Deadline, &t.CreatedAt,
+		&t.UpdatedAt}, extra...)...)
+	if err != nil {
+		return err
+	}
+	t.Notes, err = r.openNotes(t.ID, t.Notes, sealed)
+	return err
+}
+
+// openNotes decrypts the stored notes of a target if they are sealed.
+func (r *MissionPostgres) openNotes(targetID int64, stored string, sealed bool) (string, error) {
+	if !sealed {
+		return stored, nil
+	}
+	if r.keys == nil {
+		return "", fmt.Errorf("notes of target %d are encrypted and no keys are loaded", targetID)
+	}
+	notes, err := r.keys.Open(stored)
+	if err != nil {
+		return "", fmt.Errorf("notes of target %d: %w", targetID, err)
+	}
+	return notes, nil
+}
+
+// openMission matches the missions that are not closed.
+const openMission = `m.state IN ('draft', 'assigned', 'in_progress')`
+
+// unlockedTarget matches the targets t whose notes and attachments can still
+// change: those not completed on an open mission.
+const unlockedTarget = `NOT t.completed AND EXISTS (SELECT 1 FROM missions m WHERE m.id = t.mission_id AND ` + openMission + `)`
+
 func (r *MissionPostgres) CreateMission(ctx context.Context, m *domain.Mission) error {
-	query := `
-		INSERT INTO missions (cat_id, completed, created_at, updated_at)
-		VALUES ($1, $2, now(), now())
-		RETURNING id, created_at, updated_at`
-	return r.pool.QueryRow(ctx, query, m.CatID, m.Completed).
-		Scan(&m.ID, &m.CreatedAt, &m.UpdatedAt)
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if m.CatID != nil && !m.Closed() {
+			busy, err := lockCat(ctx, tx, *m.CatID, 0)
+			if err != nil {
+				return err
+			}
+			if busy {
+				return domain.ErrCatUnavailable
+			}
+		}
+		query := `
+			INSERT INTO missions (cat_id, state, completed, assigned_at, completed_at, priority,
+			                      required_experience, preferred_breeds, deadline, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8::text[], '{}'), $9, now(), now())
+			RETURNING id, created_at, updated_at`
+		return tx.QueryRow(ctx, query, m.CatID, m.State, m.Completed, m.AssignedAt, m.CompletedAt, m.Priority,
+			m.RequiredExperience, m.PreferredBreeds, m.Deadline).
+			Scan(&m.ID, &m.CreatedAt, &m.UpdatedAt)
+	})
+}
+
+// lockCat locks cat catID and reports whether it is on an open mission
+// other than missionID. Locking the cat serializes its assignments, so two
+// missions cannot both see it as available. A missing or deleted cat is
+// reported as ErrCatUnavailable.
+func lockCat(ctx context.Context, tx pgx.Tx, catID, missionID int64) (bool, error) {
+	var busy bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM missions m WHERE m.cat_id = c.id AND m.id <> $2 AND `+openMission+`)
+		FROM cats c WHERE c.id = $1 AND c.deleted_at IS NULL FOR UPDATE`, catID, missionID).Scan(&busy)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, domain.ErrCatUnavailable
+	}
+	return busy, err
 }
 
 func (r *MissionPostgres) GetMissionByID(ctx context.Context, id int64) (*domain.Mission, error) {
 	m := &domain.Mission{}
-	query := `SELECT id, cat_id, completed, created_at, updated_at FROM missions WHERE id = $1`
-	err := r.pool.QueryRow(ctx, query, id).
-		Scan(&m.ID, &m.CatID, &m.Completed, &m.CreatedAt, &m.UpdatedAt)
+	err := scanMission(r.pool.QueryRow(ctx, `SELECT `+missionColumns+` FROM missions m WHERE m.id = $1`, id), m)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrMissionNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -44,9 +139,7 @@ func (r *MissionPostgres) GetMissionByID(ctx context.Context, id int64) (*domain
 }
 
 func (r *MissionPostgres) listTargetsByMissionID(ctx context.Context, missionID int64) ([]domain.Target, error) {
-	query := `
-		SELECT id, mission_id, name, country, notes, completed, created_at, updated_at
-		FROM targets WHERE mission_id = $1`
+	query := `SELECT ` + targetColumns + ` FROM targets t WHERE t.mission_id = $1 ORDER BY t.id`
 	rows, err := r.pool.Query(ctx, query, missionID)
 	if err != nil {
 		return nil, err
@@ -56,10 +149,7 @@ func (r *MissionPostgres) listTargetsByMissionID(ctx context.Context, missionID
 	var targets []domain.Target
 	for rows.Next() {
 		var t domain.Target
-		if err := rows.Scan(
-			&t.ID, &t.MissionID, &t.Name, &t.Country,
-			&t.Notes, &t.Completed, &t.CreatedAt, &t.UpdatedAt,
-		); err != nil {
+		if err := r.scanTarget(rows, &t); err != nil {
 			return nil, err
 		}
 		targets = append(targets, t)
@@ -67,9 +157,206 @@ func (r *MissionPostgres) listTargetsByMissionID(ctx context.Context, missionID
 	return targets, nil
 }
 
-func (r *MissionPostgres) ListMissions(ctx context.Context) ([]*domain.Mission, error) {
-	query := `SELECT id, cat_id, completed, created_at, updated_at FROM missions`
-	rows, err := r.pool.Query(ctx, query)
+func (r *MissionPostgres) ListTargets(ctx context.Context, missionID int64, filter domain.TargetFilter) ([]domain.Target, error) {
+	query := `SELECT ` + targetColumns + ` FROM targets t WHERE t.mission_id = $1`
+	args := []any{missionID}
+	query += targetFilterSQL(filter, &args)
+	query += " ORDER BY t.id"
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	targets := []domain.Target{}
+	for rows.Next() {
+		var t domain.Target
+		if err := r.scanTarget(rows, &t); err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
+
+func (r *MissionPostgres) ExportMissions(ctx context.Context, filter domain.TargetFilter, fn func(domain.MissionExportRow) error) error {
+	query := `
+		SELECT m.id, m.cat_id, m.state, m.completed, m.priority, m.required_experience, m.preferred_breeds,
+		       m.deadline, m.created_at, t.id, t.name, t.country, t.notes, t.notes_sealed, t.status, t.outcome,
+		       t.completed, t.deadline, t.updated_at
+		FROM missions m
+		LEFT JOIN targets t ON t.mission_id = m.id
+		WHERE true`
+	var args []any
+	query += targetFilterSQL(filter, &args)
+	query += " ORDER BY m.id, t.id"
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row domain.MissionExportRow
+		var sealed *bool
+		if err := rows.Scan(
+			&row.MissionID, &row.CatID, &row.MissionState, &row.MissionCompleted, &row.MissionPriority,
+			&row.MissionRequiredExperience, &row.MissionPreferredBreeds, &row.MissionDeadline, &row.MissionCreatedAt,
+			&row.TargetID, &row.TargetName, &row.TargetCountry, &row.TargetNotes, &sealed, &row.TargetStatus,
+			&row.TargetOutcome, &row.TargetCompleted, &row.TargetDeadline, &row.TargetUpdatedAt,
+		); err != nil {
+			return err
+		}
+		if row.TargetNotes != nil {
+			notes, err := r.openNotes(*row.TargetID, *row.TargetNotes, *sealed)
+			if err != nil {
+				return err
+			}
+			row.TargetNotes = &notes
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// targetFilterSQL returns the conditions on targets t for filter, appending
+// their parameters to args.
+func targetFilterSQL(filter domain.TargetFilter, args *[]any) string {
+	var sql string
+	if filter.Status != "" {
+		*args = append(*args, filter.Status)
+		sql += fmt.Sprintf(" AND t.status = $%d", len(*args))
+	}
+	if filter.Completed != nil {
+		*args = append(*args, *filter.Completed)
+		sql += fmt.Sprintf(" AND t.completed = $%d", len(*args))
+	}
+	if filter.Country != "" {
+		*args = append(*args, filter.Country)
+		sql += fmt.Sprintf(" AND t.country = $%d", len(*args))
+	}
+	return sql
+}
+
+// notesHeadline highlights the matches of the tsquery tsq in the notes,
+// which are HTML-escaped first so that only the <mark> tags are markup.
+func notesHeadline(tsq string) string {
+	escaped := `replace(replace(replace(t.notes, '&', '&amp;'), '<', '&lt;'), '>', '&gt;')`
+	return "ts_headline('english', " + escaped + ", " + tsq +
+		", 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5')"
+}
+
+func (r *MissionPostgres) SearchTargets(ctx context.Context, search domain.TargetSearch) ([]domain.TargetSearchResult, int, error) {
+	var where []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	rank, highlight := "0::real", "''"
+	if search.Query != "" && r.keys != nil {
+		return nil, 0, domain.ErrNotesEncrypted
+	}
+	if search.Query != "" {
+		// Must match the expression and predicate of
+		// targets_plain_notes_fts_idx to use it. Sealed notes would only
+		// match on their ciphertext.
+		tsq := "websearch_to_tsquery('english', " + arg(search.Query) + ")"
+		where = append(where, "NOT t.notes_sealed", "to_tsvector('english', t.notes) @@ "+tsq)
+		rank = "ts_rank(to_tsvector('english', t.notes), " + tsq + ")"
+		highlight = notesHeadline(tsq)
+	}
+	if search.Country != "" {
+		where = append(where, "t.country = "+arg(search.Country))
+	}
+	if search.Completed != nil {
+		where = append(where, "t.completed = "+arg(*search.Completed))
+	}
+	if search.Assigned != nil {
+		if *search.Assigned {
+			where = append(where, "m.cat_id IS NOT NULL")
+		} else {
+			where = append(where, "m.cat_id IS NULL")
+		}
+	}
+	if search.CatID != nil {
+		where = append(where, "m.cat_id = "+arg(*search.CatID))
+	}
+	if search.MissionID != nil {
+		where = append(where, "t.mission_id = "+arg(*search.MissionID))
+	}
+
+	from := " FROM targets t JOIN missions m ON m.id = t.mission_id"
+	if len(where) > 0 {
+		from += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	// Only the parameters of the conditions go to the count, before the
+	// page ones are added.
+	var total int
+	if err := r.pool.QueryRow(ctx, "SELECT count(*)"+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + targetColumns + `, ` +
+		rank + ` AS rank, ` + highlight + from +
+		` ORDER BY rank DESC, t.id LIMIT ` + arg(search.Limit) + ` OFFSET ` + arg(search.Offset)
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	results := []domain.TargetSearchResult{}
+	for rows.Next() {
+		var res domain.TargetSearchResult
+		t := &res.Target
+		if err := r.scanTarget(rows, t, &res.Rank, &res.Highlight); err != nil {
+			return nil, 0, err
+		}
+		results = append(results, res)
+	}
+	return results, total, rows.Err()
+}
+
+// overdueMission matches the missions that Mission.MarkOverdue marks overdue
+// at the time in parameter $1.
+const overdueMission = openMission + ` AND (COALESCE(m.deadline < $1, false) OR EXISTS (
+	SELECT 1 FROM targets t WHERE t.mission_id = m.id AND NOT t.completed AND t.deadline < $1))`
+
+func (r *MissionPostgres) ListMissions(ctx context.Context, filter domain.MissionFilter) ([]*domain.Mission, error) {
+	query := `SELECT ` + missionColumns + ` FROM missions m WHERE true`
+	var args []any
+	if filter.Overdue != nil {
+		args = append(args, filter.Now)
+		if *filter.Overdue {
+			query += " AND " + overdueMission
+		} else {
+			query += " AND NOT (" + overdueMission + ")"
+		}
+	}
+	if filter.State != "" {
+		args = append(args, filter.State)
+		query += fmt.Sprintf(" AND m.state = $%d", len(args))
+	}
+	if filter.Completed != nil {
+		args = append(args, *filter.Completed)
+		query += fmt.Sprintf(" AND m.completed = $%d", len(args))
+	}
+	if filter.Assigned != nil {
+		if *filter.Assigned {
+			query += " AND m.cat_id IS NOT NULL"
+		} else {
+			query += " AND m.cat_id IS NULL"
+		}
+	}
+	query += " ORDER BY m.id"
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -78,7 +365,7 @@ func (r *MissionPostgres) ListMissions(ctx context.Context) ([]*domain.Mission,
 	var missions []*domain.Mission
 	for rows.Next() {
 		m := &domain.Mission{}
-		if err := rows.Scan(&m.ID, &m.CatID, &m.Completed, &m.CreatedAt, &m.UpdatedAt); err != nil {
+		if err := scanMission(rows, m); err != nil {
 			return nil, err
 		}
 		targets, err := r.listTargetsByMissionID(ctx, m.ID)
@@ -91,79 +378,233 @@ func (r *MissionPostgres) ListMissions(ctx context.Context) ([]*domain.Mission,
 	return missions, nil
 }
 
-func (r *MissionPostgres) UpdateMission(ctx context.Context, m *domain.Mission) error {
-	query := `
-		UPDATE missions
-		SET cat_id = $1, completed = $2, updated_at = now()
-		WHERE id = $3`
-	res, err := r.pool.Exec(ctx, query, m.CatID, m.Completed, m.ID)
-	if err != nil {
+func (r *MissionPostgres) UpdateMission(ctx context.Context, m *domain.Mission, from domain.State) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		// The cat is locked before the mission, as AssignCat does, and only
+		// a cat the mission is not already on has to be free.
+		var busy bool
+		if m.CatID != nil && !m.Closed() {
+			var err error
+			if busy, err = lockCat(ctx, tx, *m.CatID, m.ID); err != nil {
+				return err
+			}
+		}
+		var oldCatID *int64
+		err := tx.QueryRow(ctx, `SELECT cat_id FROM missions WHERE id = $1 FOR UPDATE`, m.ID).Scan(&oldCatID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrMissionNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if busy && (oldCatID == nil || *oldCatID != *m.CatID) {
+			return domain.ErrCatUnavailable
+		}
+
+		query := `
+			UPDATE missions
+			SET cat_id = $1, completed = $2, deadline = $3, priority = $4, required_experience = $5,
+			    preferred_breeds = COALESCE($6::text[], '{}'), state = $7, assigned_at = $8, started_at = $9,
+			    completed_at = $10, aborted_at = $11, failed_at = $12, updated_at = now(),
+			    overdue_notified_at = CASE WHEN deadline IS DISTINCT FROM $3 THEN NULL ELSE overdue_notified_at END
+			WHERE id = $13 AND state = $14
+			RETURNING updated_at`
+		err = tx.QueryRow(ctx, query, m.CatID, m.Completed, m.Deadline, m.Priority, m.RequiredExperience,
+			m.PreferredBreeds, m.CurrentState(), m.AssignedAt, m.StartedAt, m.CompletedAt, m.AbortedAt, m.FailedAt,
+			m.ID, from).Scan(&m.UpdatedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: the mission is no longer %s", domain.ErrInvalidTransition, from)
+		}
 		return err
-	}
-	if res.RowsAffected() == 0 {
-		return errors.New("mission not found")
-	}
-	return nil
+	})
 }
 
+// DeleteMission deletes a mission that has no cat, returning
+// ErrMissionAssigned for one that has.
 func (r *MissionPostgres) DeleteMission(ctx context.Context, id int64) error {
-	var catID int64
-	err := r.pool.QueryRow(ctx, `SELECT cat_id FROM missions WHERE id = $1`, id).Scan(&catID)
+	res, err := r.pool.Exec(ctx, `DELETE FROM missions WHERE id = $1 AND cat_id IS NULL`, id)
 	if err != nil {
 		return err
 	}
-	if catID != 0 {
-		return errors.New("cannot delete mission assigned to a cat")
+	if res.RowsAffected() > 0 {
+		return nil
+	}
+	// Either the mission is gone or it has a cat.
+	var catID *int64
+	err = r.pool.QueryRow(ctx, `SELECT cat_id FROM missions WHERE id = $1`, id).Scan(&catID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrMissionNotFound
 	}
-
-	res, err := r.pool.Exec(ctx, `DELETE FROM missions WHERE id = $1`, id)
 	if err != nil {
 		return err
 	}
-	if res.RowsAffected() == 0 {
-		return errors.New("mission not found")
-	}
-	return nil
+	return domain.ErrMissionAssigned
 }
 
-func (r *MissionPostgres) AddTargets(ctx context.Context, targets []domain.Target) error {
+func (r *MissionPostgres) AddTargets(ctx context.Context, targets []domain.Target, atomic bool) ([]domain.TargetImportError, error) {
+	batch := &pgx.Batch{}
 	for _, t := range targets {
 		if t.MissionID == 0 {
-			return errors.New("target must have mission_id")
+			return nil, errors.New("target must have mission_id")
+		}
+		notes, sealed, err := r.keys.Seal(t.Notes)
+		if err != nil {
+			return nil, err
 		}
-		_, err := r.pool.Exec(ctx,
-			`INSERT INTO targets (mission_id, name, country, notes, completed, created_at, updated_at)
-			 VALUES ($1, $2, $3, $4, $5, now(), now())`,
-			t.MissionID, t.Name, t.Country, t.Notes, t.Completed,
+		batch.Queue(
+			`WITH t AS (
+			     INSERT INTO targets (mission_id, name, country, notes, notes_sealed, status, outcome, completed,
+			                          compromised_at, eliminated_at, escaped_at, deadline, created_at, updated_at)
+			     VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, now(), now())
+			     ON CONFLICT (mission_id, name) DO NOTHING
+			     RETURNING id, notes, notes_sealed, created_at, updated_at
+			 ), note AS (
+			     INSERT INTO target_notes (target_id, author, body, body_sealed, created_at)
+			     SELECT id, '', notes, notes_sealed, created_at FROM t WHERE notes <> ''
+			 )
+			 SELECT id, created_at, updated_at FROM t`,
+			t.MissionID, t.Name, t.Country, notes, sealed, t.CurrentStatus(), t.Outcome, t.Completed,
+			t.CompromisedAt, t.EliminatedAt, t.EscapedAt, t.Deadline,
 		)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	br := tx.SendBatch(ctx, batch)
+	var failed []domain.TargetImportError
+	for i := range targets {
+		t := &targets[i]
+		err := br.QueryRow().Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			failed = append(failed, domain.TargetImportError{
+				Index: i,
+				Name:  t.Name,
+				Error: domain.ErrDuplicateTarget.Error(),
+			})
+			continue
+		}
 		if err != nil {
-			return err
+			br.Close()
+			return nil, err
 		}
 	}
-	return nil
+	if err := br.Close(); err != nil {
+		return nil, err
+	}
+
+	if atomic && len(failed) > 0 {
+		return failed, nil
+	}
+	return failed, tx.Commit(ctx)
 }
 
+// UpdateTarget compares the notes with the stored ones in plaintext, since
+// sealing the same notes twice gives different values. Unchanged notes are
+// left as they are stored.
 func (r *MissionPostgres) UpdateTarget(ctx context.Context, t *domain.Target) error {
+	journal := `
+		INSERT INTO target_notes (target_id, author, body, body_sealed, created_at)
+		VALUES ($1, '', $2, $3, now())`
 	query := `
 		UPDATE targets
-		SET notes = $1, completed = $2, updated_at = now()
-		WHERE id = $3`
-	res, err := r.pool.Exec(ctx, query, t.Notes, t.Completed, t.ID)
+		SET notes = $1, notes_sealed = $2, completed = $3, deadline = $4, status = $5, outcome = $6,
+		    compromised_at = $7, eliminated_at = $8, escaped_at = $9, updated_at = now(),
+		    overdue_notified_at = CASE WHEN deadline IS DISTINCT FROM $4 THEN NULL ELSE overdue_notified_at END
+		WHERE id = $10
+		RETURNING updated_at`
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var stored string
+		var sealed bool
+		err := tx.QueryRow(ctx, `SELECT notes, notes_sealed FROM targets WHERE id = $1 FOR UPDATE`, t.ID).
+			Scan(&stored, &sealed)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrTargetNotFound
+		}
+		if err != nil {
+			return err
+		}
+		current, err := r.openNotes(t.ID, stored, sealed)
+		if err != nil {
+			return err
+		}
+		if current != t.Notes {
+			if stored, sealed, err = r.keys.Seal(t.Notes); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, journal, t.ID, stored, sealed); err != nil {
+				return err
+			}
+		}
+		err = tx.QueryRow(ctx, query, stored, sealed, t.Completed, t.Deadline, t.CurrentStatus(), t.Outcome,
+			t.CompromisedAt, t.EliminatedAt, t.EscapedAt, t.ID).Scan(&t.UpdatedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrTargetNotFound
+		}
+		return err
+	})
+}
+
+func (r *MissionPostgres) AddTargetNote(ctx context.Context, n *domain.TargetNote) error {
+	body, sealed, err := r.keys.Seal(n.Body)
 	if err != nil {
 		return err
 	}
-	if res.RowsAffected() == 0 {
-		return errors.New("target not found")
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		// The lock is checked again here so that no note slips in while the
+		// target is completed or its mission closed.
+		res, err := tx.Exec(ctx, `
+			UPDATE targets t SET notes = $2, notes_sealed = $3, updated_at = now()
+			WHERE t.id = $1 AND `+unlockedTarget,
+			n.TargetID, body, sealed)
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() == 0 {
+			return domain.ErrNotesLocked
+		}
+		return tx.QueryRow(ctx, `
+			INSERT INTO target_notes (target_id, author, body, body_sealed, created_at)
+			VALUES ($1, $2, $3, $4, now())
+			RETURNING id, created_at`, n.TargetID, n.Author, body, sealed).
+			Scan(&n.ID, &n.CreatedAt)
+	})
+}
+
+func (r *MissionPostgres) ListTargetNotes(ctx context.Context, targetID int64) ([]domain.TargetNote, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, target_id, author, body, body_sealed, created_at
+		FROM target_notes WHERE target_id = $1
+		ORDER BY created_at, id`, targetID)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	defer rows.Close()
+
+	notes := []domain.TargetNote{}
+	for rows.Next() {
+		var n domain.TargetNote
+		var sealed bool
+		if err := rows.Scan(&n.ID, &n.TargetID, &n.Author, &n.Body, &sealed, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		if n.Body, err = r.openNotes(n.TargetID, n.Body, sealed); err != nil {
+			return nil, fmt.Errorf("note %d: %w", n.ID, err)
+		}
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
 }
 
 func (r *MissionPostgres) GetTargetByID(ctx context.Context, id int64) (*domain.Target, error) {
 	var target domain.Target
-	query := `SELECT id, mission_id, name, country, notes, completed, created_at, updated_at FROM targets WHERE id = $1`
-	err := r.pool.QueryRow(ctx, query, id).
-		Scan(&target.ID, &target.MissionID, &target.Name, &target.Country, &target.Notes,
-			&target.Completed, &target.CreatedAt, &target.UpdatedAt)
+	err := r.scanTarget(r.pool.QueryRow(ctx, `SELECT `+targetColumns+` FROM targets t WHERE t.id = $1`, id), &target)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrTargetNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -185,7 +626,242 @@ func (r *MissionPostgres) DeleteTarget(ctx context.Context, id int64) error {
 		return err
 	}
 	if res.RowsAffected() == 0 {
-		return errors.New("target not found")
+		return domain.ErrTargetNotFound
 	}
 	return nil
 }
+
+func (r *MissionPostgres) ListCandidates(ctx context.Context) ([]domain.Candidate, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT c.id, c.name, c.breed, c.years_of_experience, c.salary,
+		       (SELECT min(m.id) FROM missions m WHERE m.cat_id = c.id AND `+openMission+`)
+		FROM cats c
+		WHERE c.deleted_at IS NULL
+		ORDER BY c.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []domain.Candidate
+	for rows.Next() {
+		var c domain.Candidate
+		if err := rows.Scan(&c.ID, &c.Name, &c.Breed, &c.YearsOfExperience, &c.Salary, &c.ActiveMissionID); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+func (r *MissionPostgres) AssignCat(ctx context.Context, m *domain.Mission) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		busy, err := lockCat(ctx, tx, *m.CatID, m.ID)
+		if err != nil {
+			return err
+		}
+		if busy {
+			return domain.ErrCatUnavailable
+		}
+
+		res, err := tx.Exec(ctx, `
+			UPDATE missions SET cat_id = $2, state = $3, assigned_at = $4, updated_at = now()
+			WHERE id = $1 AND cat_id IS NULL AND state = 'draft'`, m.ID, m.CatID, m.State, m.AssignedAt)
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() == 0 {
+			return domain.ErrMissionAssigned
+		}
+		return nil
+	})
+}
+
+func (r *MissionPostgres) UpdateState(ctx context.Context, m *domain.Mission, from domain.State) error {
+	query := `
+		UPDATE missions
+		SET state = $2, completed = $3, assigned_at = $4, started_at = $5, completed_at = $6,
+		    aborted_at = $7, failed_at = $8, updated_at = now()
+		WHERE id = $1 AND state = $9
+		RETURNING updated_at`
+	err := r.pool.QueryRow(ctx, query, m.ID, m.State, m.Completed, m.AssignedAt, m.StartedAt,
+		m.CompletedAt, m.AbortedAt, m.FailedAt, from).Scan(&m.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: the mission is no longer %s", domain.ErrInvalidTransition, from)
+	}
+	return err
+}
+
+// RecordOverdue records a mission.overdue or target.overdue event for every
+// mission and target that passed its deadline by now without being
+// completed, once per deadline, and returns the events. Changing a deadline
+// makes it eligible again.
+func (r *MissionPostgres) RecordOverdue(ctx context.Context, now time.Time) ([]event.Event, error) {
+	var events []event.Event
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			WITH due AS (
+				UPDATE missions m SET overdue_notified_at = $1
+				WHERE `+openMission+` AND deadline < $1 AND overdue_notified_at IS NULL
+				RETURNING *
+			)
+			INSERT INTO events (type, mission_id, cat_id, data)
+			SELECT $2::text, id, cat_id, to_jsonb(due) - 'overdue_notified_at' FROM due ORDER BY id
+			RETURNING id, type, mission_id, cat_id, target_id, data, created_at`,
+			now, event.MissionOverdue)
+		if err != nil {
+			return err
+		}
+		if events, err = pgx.CollectRows(rows, pgx.RowToStructByPos[event.Event]); err != nil {
+			return err
+		}
+
+		rows, err = tx.Query(ctx, `
+			WITH due AS (
+				UPDATE targets SET overdue_notified_at = $1
+				WHERE NOT completed AND deadline < $1 AND overdue_notified_at IS NULL
+				  AND mission_id IN (SELECT m.id FROM missions m WHERE `+openMission+`)
+				RETURNING *
+			)
+			INSERT INTO events (type, mission_id, cat_id, target_id, data)
+			SELECT $2::text, due.mission_id, m.cat_id, due.id, to_jsonb(due) - CASE
+			           WHEN due.notes_sealed THEN ARRAY['overdue_notified_at', 'notes']
+			           ELSE ARRAY['overdue_notified_at']
+			       END
+			FROM due JOIN missions m ON m.id = due.mission_id ORDER BY due.id
+			RETURNING id, type, mission_id, cat_id, target_id, data, created_at`,
+			now, event.TargetOverdue)
+		if err != nil {
+			return err
+		}
+		targets, err := pgx.CollectRows(rows, pgx.RowToStructByPos[event.Event])
+		events = append(events, targets...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// NormalizeCountries rewrites every target country with normalize in one
+// transaction. It returns how many targets changed and the values normalize
+// did not recognize, which are left as they are.
+func (r *MissionPostgres) NormalizeCountries(ctx context.Context, normalize func(string) (string, bool)) (int64, []string, error) {
+	var updated int64
+	unmatched := []string{}
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `SELECT DISTINCT country FROM targets ORDER BY country`)
+		if err != nil {
+			return err
+		}
+		countries, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return err
+		}
+
+		for _, c := range countries {
+			code, ok := normalize(c)
+			if !ok {
+				unmatched = append(unmatched, c)
+				continue
+			}
+			if code == c {
+				continue
+			}
+			res, err := tx.Exec(ctx, `UPDATE targets SET country = $1 WHERE country = $2`, code, c)
+			if err != nil {
+				return err
+			}
+			updated += res.RowsAffected()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return updated, unmatched, nil
+}
+
+// EnforceCountryCodes adds the ISO code constraint, checked against every
+// row, which the schema leaves out while any country is free text.
+func (r *MissionPostgres) EnforceCountryCodes(ctx context.Context) error {
+	_, err := r.pool.Exec(ctx, `
+		ALTER TABLE targets DROP CONSTRAINT IF EXISTS targets_country_iso,
+		    ADD CONSTRAINT targets_country_iso CHECK (country ~ '^[A-Z]{2}$')`)
+	return err
+}
+
+// sealedNotes are the columns that hold notes sealed by MissionPostgres,
+// with the columns that record whether they are.
+var sealedNotes = []struct{ table, column, sealed string }{
+	{"targets", "notes", "notes_sealed"},
+	{"target_notes", "body", "body_sealed"},
+}
+
+// ReencryptNotes seals every stored note that is not sealed with the active
+// key yet, including those stored in plaintext, in transactions of up to
+// batchSize rows. Rows are rewritten without touching updated_at, so no
+// events are recorded. It returns how many values changed in each table.
+func (r *MissionPostgres) ReencryptNotes(ctx context.Context, batchSize int) (map[string]int64, error) {
+	if r.keys == nil {
+		return nil, errors.New("notes cannot be re-encrypted without keys")
+	}
+	if batchSize < 1 {
+		return nil, errors.New("batch size must be positive")
+	}
+	active := "enc:v1:" + r.keys.ActiveKey() + ":"
+	counts := make(map[string]int64, len(sealedNotes))
+	for _, c := range sealedNotes {
+		var lastID int64
+		for {
+			var n, changed int
+			err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+				rows, err := tx.Query(ctx, fmt.Sprintf(`
+					SELECT id, %[2]s, %[3]s FROM %[1]s
+					WHERE id > $1 AND %[2]s <> '' AND NOT (%[3]s AND starts_with(%[2]s, $2))
+					ORDER BY id LIMIT $3
+					FOR UPDATE`, c.table, c.column, c.sealed), lastID, active, batchSize)
+				if err != nil {
+					return err
+				}
+				type stored struct {
+					ID     int64
+					Value  string
+					Sealed bool
+				}
+				values, err := pgx.CollectRows(rows, pgx.RowToStructByPos[stored])
+				if err != nil {
+					return err
+				}
+				batch := &pgx.Batch{}
+				for _, v := range values {
+					sealed, rotated, err := r.keys.Rotate(v.Value, v.Sealed)
+					if err != nil {
+						return fmt.Errorf("%s %d: %w", c.table, v.ID, err)
+					}
+					if rotated {
+						batch.Queue(fmt.Sprintf(`UPDATE %s SET %s = $2, %s = true WHERE id = $1`,
+							c.table, c.column, c.sealed), v.ID, sealed)
+					}
+				}
+				if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+					return err
+				}
+				if n = len(values); n > 0 {
+					lastID = values[n-1].ID
+				}
+				changed = batch.Len()
+				return nil
+			})
+			if err != nil {
+				return counts, err
+			}
+			counts[c.table] += int64(changed)
+			if n < batchSize {
+				break
+			}
+		}
+	}
+	return counts, nil
+}
@@ -0,0 +1,268 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"go-test-assesment/db"
+	"go-test-assesment/internal/mission/domain"
+	"go-test-assesment/internal/mission/repository"
+	"go-test-assesment/pkg/country"
+	"go-test-assesment/pkg/envelope"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openDatabase connects to the scratch database at TEST_DATABASE_URL,
+// applies the schema and empties it. Tests are skipped without one.
+func openDatabase(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, url)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	_, err = pool.Exec(ctx, db.Schema)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `TRUNCATE cats, missions, targets, events, webhook_deliveries, attachment_blob_deletions RESTART IDENTITY CASCADE`)
+	require.NoError(t, err)
+	return pool
+}
+
+// createMission stores a draft mission with targets.
+func createMission(t *testing.T, repo *repository.MissionPostgres, targets ...domain.Target) *domain.Mission {
+	t.Helper()
+	ctx := context.Background()
+	m := &domain.Mission{State: domain.StateDraft}
+	require.NoError(t, repo.CreateMission(ctx, m))
+	for i := range targets {
+		targets[i].MissionID = m.ID
+	}
+	failed, err := repo.AddTargets(ctx, targets, true)
+	require.NoError(t, err)
+	require.Empty(t, failed)
+	m.Targets = targets
+	return m
+}
+
+func TestSearchTargets(t *testing.T) {
+	pool := openDatabase(t)
+	repo := repository.NewMissionPostgres(pool)
+	ctx := context.Background()
+
+	m := createMission(t, repo,
+		domain.Target{Name: "Ambassador", Country: "FR", Notes: "seen leaving the <b>embassy</b> at night"},
+		domain.Target{Name: "Guard", Country: "DE", Notes: "guards the embassy gate"},
+		domain.Target{Name: "Driver", Country: "FR", Notes: "drives a black sedan"},
+	)
+
+	t.Run("query with filters", func(t *testing.T) {
+		results, total, err := repo.SearchTargets(ctx, domain.TargetSearch{
+			Query: "embassy", Country: "FR", MissionID: &m.ID, Limit: 10,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		require.Len(t, results, 1)
+		assert.Equal(t, "Ambassador", results[0].Name)
+		assert.Contains(t, results[0].Highlight, "<mark>embassy</mark>")
+		assert.Contains(t, results[0].Highlight, "&lt;b&gt;")
+		assert.NotContains(t, results[0].Highlight, "<b>")
+	})
+
+	t.Run("query paginated", func(t *testing.T) {
+		results, total, err := repo.SearchTargets(ctx, domain.TargetSearch{Query: "embassy", Limit: 1, Offset: 1})
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		assert.Len(t, results, 1)
+	})
+
+	t.Run("filters only", func(t *testing.T) {
+		completed := false
+		results, total, err := repo.SearchTargets(ctx, domain.TargetSearch{
+			Country: "FR", Completed: &completed, Limit: 10,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		assert.Len(t, results, 2)
+	})
+}
+
+func TestNotes_PlaintextLookingSealed(t *testing.T) {
+	pool := openDatabase(t)
+	ctx := context.Background()
+	line, err := envelope.GenerateKey("a")
+	require.NoError(t, err)
+	keys, err := envelope.ParseKeyfile(strings.NewReader(line))
+	require.NoError(t, err)
+
+	const notes = "enc:v1:x:y:z"
+	plain := repository.NewMissionPostgres(pool)
+	m := createMission(t, plain, domain.Target{Name: "Forger", Country: "IT", Notes: notes})
+	id := m.Targets[0].ID
+
+	for name, repo := range map[string]*repository.MissionPostgres{
+		"without keys": plain,
+		"with keys":    repository.NewMissionPostgresWithKeys(pool, keys),
+	} {
+		t.Run(name, func(t *testing.T) {
+			target, err := repo.GetTargetByID(ctx, id)
+			require.NoError(t, err)
+			assert.Equal(t, notes, target.Notes)
+
+			missions, err := repo.ListMissions(ctx, domain.MissionFilter{})
+			require.NoError(t, err)
+			require.Len(t, missions, 1)
+			assert.Equal(t, notes, missions[0].Targets[0].Notes)
+
+			journal, err := repo.ListTargetNotes(ctx, id)
+			require.NoError(t, err)
+			require.Len(t, journal, 1)
+			assert.Equal(t, notes, journal[0].Body)
+		})
+	}
+
+	// Re-encrypting seals the plaintext, which then still reads the same.
+	sealed := repository.NewMissionPostgresWithKeys(pool, keys)
+	counts, err := sealed.ReencryptNotes(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"targets": 1, "target_notes": 1}, counts)
+	target, err := sealed.GetTargetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, notes, target.Notes)
+}
+
+func TestUpdateMission_StateChanged(t *testing.T) {
+	pool := openDatabase(t)
+	repo := repository.NewMissionPostgres(pool)
+	ctx := context.Background()
+	m := createMission(t, repo)
+
+	// The mission was aborted since it was read as assigned.
+	m.State, m.Priority = domain.StateAborted, 0
+	require.NoError(t, repo.UpdateState(ctx, m, domain.StateDraft))
+
+	stale := &domain.Mission{ID: m.ID, State: domain.StateDraft, Priority: 5}
+	err := repo.UpdateMission(ctx, stale, domain.StateDraft)
+	assert.ErrorIs(t, err, domain.ErrInvalidTransition)
+
+	stored, err := repo.GetMissionByID(ctx, m.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StateAborted, stored.State)
+	assert.Equal(t, 0, stored.Priority)
+}
+
+func TestDeleteTarget_QueuesAttachmentBlobs(t *testing.T) {
+	pool := openDatabase(t)
+	repo := repository.NewMissionPostgres(pool)
+	ctx := context.Background()
+	m := createMission(t, repo, domain.Target{Name: "Ghost", Country: "FR"})
+	target := m.Targets[0]
+
+	a := &domain.Attachment{TargetID: target.ID, Filename: "a.txt", ContentType: "text/plain", Size: 5, SHA256: "00", Key: "targets/1/a"}
+	require.NoError(t, repo.AddAttachment(ctx, a))
+	require.NoError(t, repo.DeleteTarget(ctx, target.ID))
+
+	keys, err := repo.ListDeletedBlobs(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{a.Key}, keys)
+
+	require.NoError(t, repo.ForgetDeletedBlobs(ctx, keys))
+	keys, err = repo.ListDeletedBlobs(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestAssignments_CatOnAnotherMission(t *testing.T) {
+	pool := openDatabase(t)
+	repo := repository.NewMissionPostgres(pool)
+	ctx := context.Background()
+	var catID int64
+	err := pool.QueryRow(ctx, `
+		INSERT INTO cats (name, years_of_experience, breed, salary) VALUES ('Tom', 3, 'Bengal', 100)
+		RETURNING id`).Scan(&catID)
+	require.NoError(t, err)
+
+	now := time.Now()
+	first := createMission(t, repo)
+	first.CatID = &catID
+	require.NoError(t, first.Apply(domain.TransitionAssign, now))
+	require.NoError(t, repo.AssignCat(ctx, first))
+
+	second := createMission(t, repo)
+	second.CatID = &catID
+	require.NoError(t, second.Reassign(&catID, now))
+	assert.ErrorIs(t, repo.UpdateMission(ctx, second, domain.StateDraft), domain.ErrCatUnavailable)
+
+	third := &domain.Mission{CatID: &catID}
+	require.NoError(t, third.InitState(now))
+	assert.ErrorIs(t, repo.CreateMission(ctx, third), domain.ErrCatUnavailable)
+
+	// The mission that has the cat can still be updated.
+	first.Priority = 3
+	assert.NoError(t, repo.UpdateMission(ctx, first, domain.StateAssigned))
+}
+
+func TestCountryCheck_LegacyRows(t *testing.T) {
+	pool := openDatabase(t)
+	repo := repository.NewMissionPostgres(pool)
+	ctx := context.Background()
+	m := createMission(t, repo, domain.Target{Name: "Courier", Country: "FR"})
+
+	// A database from before country codes.
+	_, err := pool.Exec(ctx, `ALTER TABLE targets DROP CONSTRAINT targets_country_iso`)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `UPDATE targets SET country = 'france' WHERE id = $1`, m.Targets[0].ID)
+	require.NoError(t, err)
+
+	_, err = pool.Exec(ctx, db.Schema)
+	require.NoError(t, err)
+	target, err := repo.GetTargetByID(ctx, m.Targets[0].ID)
+	require.NoError(t, err)
+	target.Notes = "changed cars in Lyon"
+	require.NoError(t, repo.UpdateTarget(ctx, target), "updating a legacy row")
+
+	updated, unmatched, err := repo.NormalizeCountries(ctx, country.Normalize)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), updated)
+	assert.Empty(t, unmatched)
+	require.NoError(t, repo.EnforceCountryCodes(ctx))
+
+	_, err = pool.Exec(ctx, `UPDATE targets SET country = 'france' WHERE id = $1`, m.Targets[0].ID)
+	assert.ErrorContains(t, err, "targets_country_iso")
+}
+
+func TestDeleteMission(t *testing.T) {
+	pool := openDatabase(t)
+	repo := repository.NewMissionPostgres(pool)
+	ctx := context.Background()
+	m := createMission(t, repo, domain.Target{Name: "Ghost", Country: "FR"})
+	a := &domain.Attachment{TargetID: m.Targets[0].ID, Filename: "a.txt", ContentType: "text/plain", Size: 5, SHA256: "00", Key: "targets/1/a"}
+	require.NoError(t, repo.AddAttachment(ctx, a))
+
+	var catID int64
+	err := pool.QueryRow(ctx, `
+		INSERT INTO cats (name, years_of_experience, breed, salary) VALUES ('Tom', 3, 'Bengal', 100)
+		RETURNING id`).Scan(&catID)
+	require.NoError(t, err)
+	assigned := createMission(t, repo)
+	assigned.CatID = &catID
+	require.NoError(t, assigned.Apply(domain.TransitionAssign, time.Now()))
+	require.NoError(t, repo.AssignCat(ctx, assigned))
+	assert.ErrorIs(t, repo.DeleteMission(ctx, assigned.ID), domain.ErrMissionAssigned)
+
+	require.NoError(t, repo.DeleteMission(ctx, m.ID))
+	assert.ErrorIs(t, repo.DeleteMission(ctx, m.ID), domain.ErrMissionNotFound)
+
+	keys, err := repo.ListDeletedBlobs(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{a.Key}, keys)
+}
@@ -0,0 +1,61 @@
+package scanner
+
+import (
+	"context"
+	"log"
+	"time"
+
+	event "go-test-assesment/internal/event/domain"
+)
+
+// Repository records overdue events for missions and targets whose deadline
+// passed without them being completed, at most once per deadline.
+type Repository interface {
+	RecordOverdue(ctx context.Context, now time.Time) ([]event.Event, error)
+}
+
+// Scanner periodically looks for missions and targets that became overdue.
+// The events are written to the events table, which announces them to
+// listeners and queues webhook deliveries; publisher is only needed when the
+// feed is kept in memory and may be nil otherwise.
+type Scanner struct {
+	repo      Repository
+	publisher event.Publisher
+	interval  time.Duration
+	now       func() time.Time
+}
+
+func NewScanner(repo Repository, publisher event.Publisher, interval time.Duration) *Scanner {
+	return &Scanner{repo: repo, publisher: publisher, interval: interval, now: time.Now}
+}
+
+// Run scans once per interval until ctx is done.
+func (s *Scanner) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Scan(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("[ERROR] overdue scanner: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan records the missions and targets that are overdue now and returns
+// how many events were emitted.
+func (s *Scanner) Scan(ctx context.Context) (int, error) {
+	events, err := s.repo.RecordOverdue(ctx, s.now())
+	if err != nil {
+		return 0, err
+	}
+	if s.publisher != nil {
+		for _, e := range events {
+			s.publisher.Publish(ctx, e)
+		}
+	}
+	return len(events), nil
+}
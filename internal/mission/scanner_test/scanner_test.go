@@ -0,0 +1,82 @@
+package scanner_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	event "go-test-assesment/internal/event/domain"
+	"go-test-assesment/internal/mission/scanner"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	events []event.Event
+	err    error
+	calls  []time.Time
+}
+
+func (r *fakeRepo) RecordOverdue(_ context.Context, now time.Time) ([]event.Event, error) {
+	r.calls = append(r.calls, now)
+	return r.events, r.err
+}
+
+type recordingPublisher struct {
+	events []event.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e event.Event) {
+	p.events = append(p.events, e)
+}
+
+func TestScanner_Scan(t *testing.T) {
+	targetID := int64(4)
+	repo := &fakeRepo{events: []event.Event{
+		{ID: 1, Type: event.MissionOverdue, MissionID: 2},
+		{ID: 2, Type: event.TargetOverdue, MissionID: 3, TargetID: &targetID},
+	}}
+	pub := &recordingPublisher{}
+
+	n, err := scanner.NewScanner(repo, pub, time.Minute).Scan(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, repo.events, pub.events)
+	require.Len(t, repo.calls, 1)
+	assert.WithinDuration(t, time.Now(), repo.calls[0], time.Second)
+}
+
+func TestScanner_ScanWithoutPublisher(t *testing.T) {
+	repo := &fakeRepo{events: []event.Event{{ID: 1, Type: event.MissionOverdue, MissionID: 2}}}
+
+	n, err := scanner.NewScanner(repo, nil, time.Minute).Scan(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestScanner_ScanError(t *testing.T) {
+	repo := &fakeRepo{err: errors.New("db down")}
+	pub := &recordingPublisher{}
+
+	_, err := scanner.NewScanner(repo, pub, time.Minute).Scan(context.Background())
+	assert.EqualError(t, err, "db down")
+	assert.Empty(t, pub.events)
+}
+
+func TestScanner_RunStopsWithContext(t *testing.T) {
+	repo := &fakeRepo{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		scanner.NewScanner(repo, nil, time.Hour).Run(ctx)
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+}
@@ -0,0 +1,216 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"go-test-assesment/internal/mission/domain"
+	"go-test-assesment/pkg/blob"
+)
+
+type AttachmentUsecase struct {
+	missionRepo    domain.Repository
+	attachmentRepo domain.AttachmentRepository
+	store          blob.Store
+	maxSize        int64
+}
+
+// NewAttachmentUsecase creates an attachment usecase that keeps contents in
+// store and accepts files of up to maxSize bytes.
+func NewAttachmentUsecase(mr domain.Repository, ar domain.AttachmentRepository, store blob.Store, maxSize int64) *AttachmentUsecase {
+	return &AttachmentUsecase{missionRepo: mr, attachmentRepo: ar, store: store, maxSize: maxSize}
+}
+
+// AddAttachment reads the whole upload before storing it so that its size,
+// type and checksum are known up front.
+func (uc *AttachmentUsecase) AddAttachment(ctx context.Context, targetID int64, upload domain.AttachmentUpload) (*domain.Attachment, error) {
+	filename, err := attachmentFilename(upload.Filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkUnlocked(ctx, targetID); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(upload.Content, uc.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(data) == 0:
+		return nil, domain.ErrAttachmentEmpty
+	case int64(len(data)) > uc.maxSize:
+		return nil, fmt.Errorf("%w: the limit is %d bytes", domain.ErrAttachmentTooLarge, uc.maxSize)
+	}
+	contentType := http.DetectContentType(data)
+	if !slices.Contains(domain.AttachmentTypes, contentType) {
+		return nil, fmt.Errorf("%w %q", domain.ErrAttachmentType, contentType)
+	}
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	if upload.SHA256 != "" && !strings.EqualFold(upload.SHA256, checksum) {
+		return nil, domain.ErrChecksumMismatch
+	}
+
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	a := &domain.Attachment{
+		TargetID:    targetID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		SHA256:      checksum,
+		Key:         fmt.Sprintf("targets/%d/%s", targetID, hex.EncodeToString(key)),
+	}
+	if err := uc.store.Put(ctx, a.Key, bytes.NewReader(data), a.Size, a.ContentType); err != nil {
+		return nil, err
+	}
+	if err := uc.attachmentRepo.AddAttachment(ctx, a); err != nil {
+		uc.deleteBlob(ctx, a)
+		return nil, err
+	}
+	return a, nil
+}
+
+func (uc *AttachmentUsecase) ListAttachments(ctx context.Context, targetID int64) ([]domain.Attachment, error) {
+	if _, err := uc.missionRepo.GetTargetByID(ctx, targetID); err != nil {
+		return nil, err
+	}
+	return uc.attachmentRepo.ListAttachments(ctx, targetID)
+}
+
+func (uc *AttachmentUsecase) OpenAttachment(ctx context.Context, targetID, id int64) (*domain.Attachment, io.ReadCloser, error) {
+	a, err := uc.attachmentRepo.GetAttachment(ctx, targetID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := uc.store.Get(ctx, a.Key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("attachment %d: %w", a.ID, err)
+	}
+	return a, content, nil
+}
+
+// DeleteAttachment removes the record before the content, so that a failure
+// in between leaves a blob for SweepDeletedBlobs rather than a broken
+// attachment.
+func (uc *AttachmentUsecase) DeleteAttachment(ctx context.Context, targetID, id int64) error {
+	a, err := uc.attachmentRepo.GetAttachment(ctx, targetID, id)
+	if err != nil {
+		return err
+	}
+	if err := uc.checkUnlocked(ctx, targetID); err != nil {
+		return err
+	}
+	if err := uc.attachmentRepo.DeleteAttachment(ctx, targetID, id); err != nil {
+		return err
+	}
+	uc.deleteBlob(ctx, a)
+	return nil
+}
+
+// blobSweepBatch is how many deleted blobs SweepDeletedBlobs handles at a
+// time.
+const blobSweepBatch = 100
+
+// SweepDeletedBlobs removes the contents of deleted attachments from the
+// store, which covers attachments deleted along with their target or
+// mission and by purge, and returns how many it removed. Blobs that fail to
+// be deleted are tried again on the next sweep.
+func (uc *AttachmentUsecase) SweepDeletedBlobs(ctx context.Context) (int, error) {
+	var removed int
+	for {
+		keys, err := uc.attachmentRepo.ListDeletedBlobs(ctx, blobSweepBatch)
+		if err != nil {
+			return removed, err
+		}
+		var gone []string
+		for _, key := range keys {
+			if err := uc.store.Delete(ctx, key); err != nil {
+				log.Printf("deleting blob %s: %v", key, err)
+				continue
+			}
+			gone = append(gone, key)
+		}
+		if len(gone) > 0 {
+			if err := uc.attachmentRepo.ForgetDeletedBlobs(ctx, gone); err != nil {
+				return removed, err
+			}
+		}
+		removed += len(gone)
+		if len(keys) < blobSweepBatch || len(gone) == 0 {
+			return removed, nil
+		}
+	}
+}
+
+// SweepBlobs runs SweepDeletedBlobs every interval until ctx is done.
+func (uc *AttachmentUsecase) SweepBlobs(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := uc.SweepDeletedBlobs(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("[ERROR] attachment blob sweep: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkUnlocked returns ErrAttachmentsLocked if the target is eliminated or
+// escaped or its mission is closed.
+func (uc *AttachmentUsecase) checkUnlocked(ctx context.Context, targetID int64) error {
+	target, err := uc.missionRepo.GetTargetByID(ctx, targetID)
+	if err != nil {
+		return err
+	}
+	mission, err := uc.missionRepo.GetMissionByID(ctx, target.MissionID)
+	if err != nil {
+		return err
+	}
+	if target.Terminal() || mission.Closed() {
+		return domain.ErrAttachmentsLocked
+	}
+	return nil
+}
+
+// deleteBlob removes the content of a, even if ctx is canceled; failures
+// are logged.
+func (uc *AttachmentUsecase) deleteBlob(ctx context.Context, a *domain.Attachment) {
+	if err := uc.store.Delete(context.WithoutCancel(ctx), a.Key); err != nil {
+		log.Printf("attachment %d: deleting blob %s: %v", a.ID, a.Key, err)
+	}
+}
+
+// attachmentFilename keeps the base name of an uploaded file, without any
+// directories a client may have sent.
+func attachmentFilename(name string) (string, error) {
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, `\`, "/")))
+	switch {
+	case name == "" || name == "." || name == "/" || name == "..":
+		return "", fmt.Errorf("%w: a name is required", domain.ErrInvalidFilename)
+	case !utf8.ValidString(name) || strings.ContainsFunc(name, unicode.IsControl):
+		return "", fmt.Errorf("%w %q", domain.ErrInvalidFilename, name)
+	case utf8.RuneCountInString(name) > domain.MaxFilenameLength:
+		return "", fmt.Errorf("%w: longer than %d characters", domain.ErrInvalidFilename, domain.MaxFilenameLength)
+	}
+	return name, nil
+}
@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	event "go-test-assesment/internal/event/domain"
+	"go-test-assesment/internal/mission/domain"
+)
+
+func (uc *MissionUsecase) AutoAssign(ctx context.Context, missionID int64) (*domain.AutoAssignment, error) {
+	m, err := uc.missionRepo.GetMissionByID(ctx, missionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAssignable(m); err != nil {
+		return nil, err
+	}
+	candidates, err := uc.missionRepo.ListCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return uc.autoAssign(ctx, m, candidates)
+}
+
+func (uc *MissionUsecase) AutoAssignAll(ctx context.Context, missionIDs []int64) (*domain.AutoAssignReport, error) {
+	report := &domain.AutoAssignReport{Results: []domain.AutoAssignment{}}
+	var missions, skipped []*domain.Mission
+	var skippedErrs []error
+	if len(missionIDs) == 0 {
+		no := false
+		var err error
+		filter := domain.MissionFilter{State: domain.StateDraft, Assigned: &no}
+		if missions, err = uc.missionRepo.ListMissions(ctx, filter); err != nil {
+			return nil, err
+		}
+	}
+	seen := make(map[int64]bool, len(missionIDs))
+	for _, id := range missionIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		m, err := uc.missionRepo.GetMissionByID(ctx, id)
+		if err == nil {
+			err = checkAssignable(m)
+		} else if errors.Is(err, domain.ErrMissionNotFound) {
+			m = &domain.Mission{ID: id}
+		} else {
+			return nil, err
+		}
+		if err != nil {
+			skipped = append(skipped, m)
+			skippedErrs = append(skippedErrs, err)
+			continue
+		}
+		missions = append(missions, m)
+	}
+	if len(missions) == 0 && len(skipped) == 0 {
+		return report, nil
+	}
+
+	candidates, err := uc.missionRepo.ListCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(missions, byUrgency)
+	for _, m := range missions {
+		a, err := uc.autoAssign(ctx, m, candidates)
+		switch {
+		case err == nil:
+			report.Assigned++
+		case errors.Is(err, domain.ErrNoAvailableCat):
+			report.Unassigned++
+		case errors.Is(err, domain.ErrMissionAssigned):
+			// Assigned by someone else since it was listed.
+			report.Unassigned++
+			a = &domain.AutoAssignment{MissionID: m.ID, Error: err.Error()}
+		default:
+			return nil, err
+		}
+		report.Results = append(report.Results, *a)
+	}
+	for i, m := range skipped {
+		report.Unassigned++
+		report.Results = append(report.Results, domain.AutoAssignment{MissionID: m.ID, Error: skippedErrs[i].Error()})
+	}
+	return report, nil
+}
+
+func checkAssignable(m *domain.Mission) error {
+	if m.Closed() {
+		return domain.ErrMissionClosed
+	}
+	if m.CatID != nil {
+		return domain.ErrMissionAssigned
+	}
+	return nil
+}
+
+// byUrgency orders missions by priority, highest first, then by deadline,
+// earliest first and missions without one last, then by ID.
+func byUrgency(a, b *domain.Mission) int {
+	if c := cmp.Compare(b.Priority, a.Priority); c != 0 {
+		return c
+	}
+	switch {
+	case a.Deadline != nil && b.Deadline != nil:
+		if c := a.Deadline.Compare(*b.Deadline); c != 0 {
+			return c
+		}
+	case a.Deadline != nil:
+		return -1
+	case b.Deadline != nil:
+		return 1
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// autoAssign assigns the best cat among candidates to m and marks it busy
+// in candidates, so that a batch can reuse them. A cat that turns out to
+// have been taken in the meantime is marked busy and the next best one is
+// tried.
+func (uc *MissionUsecase) autoAssign(ctx context.Context, m *domain.Mission, candidates []domain.Candidate) (*domain.AutoAssignment, error) {
+	for {
+		a, best := choose(m, candidates)
+		if best < 0 {
+			return a, domain.ErrNoAvailableCat
+		}
+		cat := &candidates[best]
+		assigned := *m
+		assigned.State = m.CurrentState()
+		assigned.CatID = &cat.ID
+		if err := assigned.Apply(domain.TransitionAssign, uc.now()); err != nil {
+			return nil, err
+		}
+		err := uc.missionRepo.AssignCat(ctx, &assigned)
+		if errors.Is(err, domain.ErrCatUnavailable) {
+			cat.ActiveMissionID = new(int64)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		cat.ActiveMissionID = &m.ID
+		*m = assigned
+		uc.publishMission(ctx, event.MissionAssigned, m)
+		return a, nil
+	}
+}
+
+// choose picks the cat for m among candidates and explains the choice. Cats
+// on an active mission or with less experience than required are left out;
+// of the rest, cats of a preferred breed come first, then the lowest salary.
+// It returns the index of the chosen cat, or -1 if none qualifies.
+func choose(m *domain.Mission, candidates []domain.Candidate) (*domain.AutoAssignment, int) {
+	a := &domain.AutoAssignment{MissionID: m.ID, Considered: len(candidates)}
+	var eligible []int
+	for i, c := range candidates {
+		switch {
+		case c.ActiveMissionID != nil:
+			a.Busy++
+		case c.YearsOfExperience < m.RequiredExperience:
+			a.Inexperienced++
+		default:
+			eligible = append(eligible, i)
+		}
+	}
+	a.Eligible = len(eligible)
+
+	excluded := fmt.Sprintf("Of %d cats, %d %s on an active mission and %d had fewer than %d years of experience.",
+		a.Considered, a.Busy, plural(a.Busy, "was", "were"), a.Inexperienced, m.RequiredExperience)
+	if len(eligible) == 0 {
+		a.Error = domain.ErrNoAvailableCat.Error()
+		a.Explanation = "No cat is available. " + excluded
+		return a, -1
+	}
+
+	preferred := func(i int) bool {
+		return slices.ContainsFunc(m.PreferredBreeds, func(b string) bool {
+			return strings.EqualFold(b, candidates[i].Breed)
+		})
+	}
+	slices.SortStableFunc(eligible, func(i, j int) int {
+		if pi, pj := preferred(i), preferred(j); pi != pj {
+			if pi {
+				return -1
+			}
+			return 1
+		}
+		if c := cmp.Compare(candidates[i].Salary.Cents(), candidates[j].Salary.Cents()); c != 0 {
+			return c
+		}
+		return cmp.Compare(candidates[i].ID, candidates[j].ID)
+	})
+	best := eligible[0]
+	cat := candidates[best]
+	a.Cat = &cat
+
+	pool := fmt.Sprintf("the %d available %s", len(eligible), plural(len(eligible), "cat", "cats"))
+	if len(m.PreferredBreeds) > 0 {
+		breeds := strings.Join(m.PreferredBreeds, ", ")
+		if preferred(best) {
+			n := 0
+			for _, i := range eligible {
+				if preferred(i) {
+					n++
+				}
+			}
+			pool = fmt.Sprintf("the %d available %s of a preferred breed (%s)", n, plural(n, "cat", "cats"), breeds)
+		} else {
+			pool += fmt.Sprintf(", none of a preferred breed (%s)", breeds)
+		}
+	}
+	a.Explanation = fmt.Sprintf("Chose %s (#%d), a %s with %d years of experience (%d required), "+
+		"with the lowest salary (%s) of %s. %s",
+		cat.Name, cat.ID, cat.Breed, cat.YearsOfExperience, m.RequiredExperience, cat.Salary, pool, excluded)
+	return a, best
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	event "go-test-assesment/internal/event/domain"
+	"go-test-assesment/internal/mission/domain"
+)
+
+func (uc *MissionUsecase) publishMission(ctx context.Context, t event.Type, m *domain.Mission) {
+	if uc.events == nil {
+		return
+	}
+	data, _ := json.Marshal(m)
+	uc.events.Publish(ctx, event.Event{
+		Type:      t,
+		MissionID: m.ID,
+		CatID:     m.CatID,
+		Data:      data,
+	})
+}
+
+func (uc *MissionUsecase) publishTarget(ctx context.Context, m *domain.Mission, t *domain.Target) {
+	if uc.events == nil {
+		return
+	}
+	data, _ := json.Marshal(t)
+	targetID := t.ID
+	uc.events.Publish(ctx, event.Event{
+		Type:      event.TargetUpdated,
+		MissionID: m.ID,
+		CatID:     m.CatID,
+		TargetID:  &targetID,
+		Data:      data,
+	})
+}
@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"context"
+
+	"go-test-assesment/internal/mission/domain"
+)
+
+func (uc *MissionUsecase) AddTargetNote(ctx context.Context, targetID int64, note domain.TargetNote) (*domain.TargetNote, error) {
+	if err := note.Validate(); err != nil {
+		return nil, err
+	}
+	target, err := uc.missionRepo.GetTargetByID(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	mission, err := uc.missionRepo.GetMissionByID(ctx, target.MissionID)
+	if err != nil {
+		return nil, err
+	}
+	if target.Terminal() || mission.Closed() {
+		return nil, domain.ErrNotesLocked
+	}
+	note.TargetID = targetID
+	if err := uc.missionRepo.AddTargetNote(ctx, &note); err != nil {
+		return nil, err
+	}
+	target.Notes, target.UpdatedAt = note.Body, note.CreatedAt
+	target.MarkOverdue(uc.now())
+	uc.publishTarget(ctx, mission, target)
+	return &note, nil
+}
+
+func (uc *MissionUsecase) ListTargetNotes(ctx context.Context, targetID int64) ([]domain.TargetNote, error) {
+	if _, err := uc.missionRepo.GetTargetByID(ctx, targetID); err != nil {
+		return nil, err
+	}
+	return uc.missionRepo.ListTargetNotes(ctx, targetID)
+}
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	event "go-test-assesment/internal/event/domain"
+	"go-test-assesment/internal/mission/domain"
+)
+
+// transitionEvents are the events published for the transitions that can be
+// requested directly; cats are assigned through AssignCatToMission and
+// AutoAssign.
+var transitionEvents = map[domain.Transition]event.Type{
+	domain.TransitionStart:    event.MissionStarted,
+	domain.TransitionComplete: event.MissionCompleted,
+	domain.TransitionFail:     event.MissionFailed,
+	domain.TransitionAbort:    event.MissionAborted,
+}
+
+func (uc *MissionUsecase) TransitionMission(ctx context.Context, id int64, t domain.Transition) (*domain.Mission, error) {
+	eventType, ok := transitionEvents[t]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q cannot be requested", domain.ErrInvalidTransition, t)
+	}
+	m, err := uc.missionRepo.GetMissionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	from := m.CurrentState()
+	if err := m.Apply(t, uc.now()); err != nil {
+		return nil, err
+	}
+	if err := uc.missionRepo.UpdateState(ctx, m, from); err != nil {
+		return nil, err
+	}
+	m.MarkOverdue(uc.now())
+	m.CountProgress()
+	uc.publishMission(ctx, eventType, m)
+	return m, nil
+}
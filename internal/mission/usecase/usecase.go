@@ -3,27 +3,65 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	event "go-test-assesment/internal/event/domain"
 	"go-test-assesment/internal/mission/domain"
+	"go-test-assesment/pkg/country"
+	"sort"
+	"strings"
+	"time"
 )
 
 type MissionUsecase struct {
 	missionRepo domain.Repository
+	events      event.Publisher
+	now         func() time.Time
 }
 
-func NewMissionUsecase(mr domain.Repository) *MissionUsecase {
-	return &MissionUsecase{missionRepo: mr}
+// NewMissionUsecase creates a mission usecase. Lifecycle events are sent to
+// publisher; a nil publisher disables them.
+func NewMissionUsecase(mr domain.Repository, publisher event.Publisher) *MissionUsecase {
+	return &MissionUsecase{missionRepo: mr, events: publisher, now: time.Now}
 }
 
 func (uc *MissionUsecase) CreateMission(ctx context.Context, m *domain.Mission) error {
-	return uc.missionRepo.CreateMission(ctx, m)
+	if err := m.ValidateRequirements(); err != nil {
+		return err
+	}
+	if m.Deadline != nil && !m.Deadline.After(uc.now()) {
+		return domain.ErrDeadlinePassed
+	}
+	if err := m.InitState(uc.now()); err != nil {
+		return err
+	}
+	if err := uc.missionRepo.CreateMission(ctx, m); err != nil {
+		return err
+	}
+	uc.publishMission(ctx, event.MissionCreated, m)
+	return nil
 }
 
 func (uc *MissionUsecase) GetMissionByID(ctx context.Context, id int64) (*domain.Mission, error) {
-	return uc.missionRepo.GetMissionByID(ctx, id)
+	m, err := uc.missionRepo.GetMissionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	m.MarkOverdue(uc.now())
+	m.CountProgress()
+	return m, nil
 }
 
-func (uc *MissionUsecase) ListMissions(ctx context.Context) ([]*domain.Mission, error) {
-	return uc.missionRepo.ListMissions(ctx)
+func (uc *MissionUsecase) ListMissions(ctx context.Context, filter domain.MissionFilter) ([]*domain.Mission, error) {
+	filter.Now = uc.now()
+	missions, err := uc.missionRepo.ListMissions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range missions {
+		m.MarkOverdue(filter.Now)
+		m.CountProgress()
+	}
+	return missions, nil
 }
 
 func (uc *MissionUsecase) UpdateMission(ctx context.Context, m *domain.Mission) error {
@@ -31,10 +69,61 @@ func (uc *MissionUsecase) UpdateMission(ctx context.Context, m *domain.Mission)
 	if err != nil {
 		return err
 	}
-	if existing.Completed {
-		return errors.New("cannot update a completed mission")
+	if state := existing.CurrentState(); state.Closed() {
+		article := "a"
+		if state == domain.StateAborted {
+			article = "an"
+		}
+		return fmt.Errorf("%w: cannot update %s %s mission", domain.ErrMissionClosed, article, state)
+	}
+	if err := m.ValidateRequirements(); err != nil {
+		return err
+	}
+	if err := uc.checkMissionDeadline(existing, m.Deadline); err != nil {
+		return err
+	}
+	if err := uc.applyUpdateState(existing, m); err != nil {
+		return err
+	}
+	if err := uc.missionRepo.UpdateMission(ctx, m, existing.CurrentState()); err != nil {
+		return err
+	}
+	m.MarkOverdue(uc.now())
+	m.CountProgress()
+	if m.CatID != nil && (existing.CatID == nil || *existing.CatID != *m.CatID) {
+		uc.publishMission(ctx, event.MissionAssigned, m)
 	}
-	return uc.missionRepo.UpdateMission(ctx, m)
+	if m.Completed {
+		uc.publishMission(ctx, event.MissionCompleted, m)
+	}
+	return nil
+}
+
+// applyUpdateState carries the lifecycle and targets of existing over to its
+// update m: a changed cat reassigns the mission and completed completes it
+// through TransitionComplete, with its guards.
+func (uc *MissionUsecase) applyUpdateState(existing, m *domain.Mission) error {
+	completed := m.Completed
+	m.State, m.Completed = existing.CurrentState(), existing.Completed
+	m.AssignedAt, m.StartedAt = existing.AssignedAt, existing.StartedAt
+	m.Targets = existing.Targets
+	now := uc.now()
+	if !sameID(existing.CatID, m.CatID) {
+		if err := m.Reassign(m.CatID, now); err != nil {
+			return err
+		}
+	}
+	if completed {
+		return m.Apply(domain.TransitionComplete, now)
+	}
+	return nil
+}
+
+func sameID(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
 }
 
 func (uc *MissionUsecase) DeleteMission(ctx context.Context, id int64) error {
@@ -43,37 +132,280 @@ func (uc *MissionUsecase) DeleteMission(ctx context.Context, id int64) error {
 		return err
 	}
 	if m.CatID != nil && *m.CatID != 0 {
-		return errors.New("mission cannot be deleted because it is assigned to a cat")
+		return fmt.Errorf("%w: it cannot be deleted", domain.ErrMissionAssigned)
 	}
 	return uc.missionRepo.DeleteMission(ctx, id)
 }
 
-func (uc *MissionUsecase) AddTargets(ctx context.Context, missionID int64, targets []domain.Target) error {
-	mission, err := uc.missionRepo.GetMissionByID(ctx, missionID)
+func (uc *MissionUsecase) GetTargetByID(ctx context.Context, id int64) (*domain.Target, error) {
+	t, err := uc.missionRepo.GetTargetByID(ctx, id)
 	if err != nil {
+		return nil, err
+	}
+	t.MarkOverdue(uc.now())
+	return t, nil
+}
+
+func (uc *MissionUsecase) ListTargets(ctx context.Context, missionID int64, filter domain.TargetFilter) ([]domain.Target, error) {
+	if _, err := uc.missionRepo.GetMissionByID(ctx, missionID); err != nil {
+		return nil, err
+	}
+	var err error
+	if filter.Country, err = normalizeCountryFilter(filter.Country); err != nil {
+		return nil, err
+	}
+	targets, err := uc.missionRepo.ListTargets(ctx, missionID, filter)
+	if err != nil {
+		return nil, err
+	}
+	now := uc.now()
+	for i := range targets {
+		targets[i].MarkOverdue(now)
+	}
+	return targets, nil
+}
+
+// ExportMissions streams missions joined with their targets to fn; filter
+// applies to the targets as in ListTargets.
+func (uc *MissionUsecase) ExportMissions(ctx context.Context, filter domain.TargetFilter, fn func(domain.MissionExportRow) error) error {
+	var err error
+	if filter.Country, err = normalizeCountryFilter(filter.Country); err != nil {
 		return err
 	}
-	if mission.Completed {
-		return errors.New("cannot add targets to a completed mission")
+	return uc.missionRepo.ExportMissions(ctx, filter, fn)
+}
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxQueryLength     = 200
+)
+
+// SearchTargets searches targets across all missions. A limit outside
+// 1..100 falls back to the default of 20.
+func (uc *MissionUsecase) SearchTargets(ctx context.Context, search domain.TargetSearch) (*domain.TargetSearchPage, error) {
+	search.Query = strings.TrimSpace(search.Query)
+	if len(search.Query) > maxQueryLength {
+		return nil, domain.ErrQueryTooLong
+	}
+	if search.Offset < 0 {
+		return nil, domain.ErrInvalidPage
+	}
+	var err error
+	if search.Country, err = normalizeCountryFilter(search.Country); err != nil {
+		return nil, err
+	}
+	if search.Limit <= 0 || search.Limit > maxSearchLimit {
+		search.Limit = defaultSearchLimit
+	}
+
+	items, total, err := uc.missionRepo.SearchTargets(ctx, search)
+	if err != nil {
+		return nil, err
 	}
-	return uc.missionRepo.AddTargets(ctx, targets)
+	now := uc.now()
+	for i := range items {
+		items[i].MarkOverdue(now)
+	}
+	return &domain.TargetSearchPage{Items: items, Total: total, Limit: search.Limit, Offset: search.Offset}, nil
 }
 
-func (uc *MissionUsecase) UpdateTarget(ctx context.Context, t *domain.Target) error {
-	existingTarget, err := uc.missionRepo.GetTargetByID(ctx, t.ID)
+func (uc *MissionUsecase) AddTargets(ctx context.Context, missionID int64, targets []domain.Target, mode domain.ImportMode) (*domain.TargetImportReport, error) {
+	mission, err := uc.missionRepo.GetMissionByID(ctx, missionID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if mission.Closed() {
+		return nil, domain.ErrMissionCompleted
+	}
+
+	report := &domain.TargetImportReport{Created: []domain.Target{}}
+	valid := make([]domain.Target, 0, len(targets))
+	indices := make([]int, 0, len(targets))
+	seen := make(map[string]bool, len(targets))
+	for i, t := range targets {
+		t.MissionID = missionID
+		if err := validateTarget(&t); err != nil {
+			report.Errors = append(report.Errors, domain.TargetImportError{Index: i, Name: t.Name, Error: err.Error()})
+			continue
+		}
+		if err := t.InitStatus(uc.now()); err != nil {
+			report.Errors = append(report.Errors, domain.TargetImportError{Index: i, Name: t.Name, Error: err.Error()})
+			continue
+		}
+		if err := uc.checkTargetDeadline(mission, nil, t.Deadline); err != nil {
+			report.Errors = append(report.Errors, domain.TargetImportError{Index: i, Name: t.Name, Error: err.Error()})
+			continue
+		}
+		if seen[t.Name] {
+			report.Errors = append(report.Errors, domain.TargetImportError{Index: i, Name: t.Name, Error: domain.ErrDuplicateTarget.Error()})
+			continue
+		}
+		seen[t.Name] = true
+		valid = append(valid, t)
+		indices = append(indices, i)
+	}
+
+	atomic := mode != domain.ImportBestEffort
+	if atomic && len(report.Errors) > 0 {
+		return report, domain.ErrImportRejected
+	}
+
+	if len(valid) > 0 {
+		failed, err := uc.missionRepo.AddTargets(ctx, valid, atomic)
+		if err != nil {
+			return nil, err
+		}
+		rejected := make(map[int]bool, len(failed))
+		for _, f := range failed {
+			rejected[f.Index] = true
+			f.Index = indices[f.Index]
+			report.Errors = append(report.Errors, f)
+		}
+		sort.Slice(report.Errors, func(i, j int) bool {
+			return report.Errors[i].Index < report.Errors[j].Index
+		})
+		if atomic && len(failed) > 0 {
+			return report, domain.ErrImportRejected
+		}
+		now := uc.now()
+		for i, t := range valid {
+			if !rejected[i] {
+				t.MarkOverdue(now)
+				report.Created = append(report.Created, t)
+			}
+		}
+	}
+	return report, nil
+}
+
+// checkMissionDeadline checks a new deadline for m: a changed deadline
+// cannot be in the past, and the targets of m must still fit in it.
+func (uc *MissionUsecase) checkMissionDeadline(m *domain.Mission, deadline *time.Time) error {
+	if sameTime(m.Deadline, deadline) {
+		return nil
+	}
+	if deadline != nil && !deadline.After(uc.now()) {
+		return domain.ErrDeadlinePassed
+	}
+	updated := domain.Mission{Deadline: deadline}
+	for _, t := range m.Targets {
+		if err := updated.CheckTargetDeadline(t.Deadline); err != nil {
+			return fmt.Errorf("%w: target %q is due %s", err, t.Name, t.Deadline.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// checkTargetDeadline checks the deadline of a target of m, changed from
+// old; an unchanged deadline passes even if it is already over.
+func (uc *MissionUsecase) checkTargetDeadline(m *domain.Mission, old, deadline *time.Time) error {
+	if deadline == nil || sameTime(old, deadline) {
+		return nil
+	}
+	if !deadline.After(uc.now()) {
+		return domain.ErrDeadlinePassed
+	}
+	return m.CheckTargetDeadline(deadline)
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// validateTarget checks t and replaces its country with the ISO code.
+func validateTarget(t *domain.Target) error {
+	if strings.TrimSpace(t.Name) == "" {
+		return errors.New("target name cannot be empty")
+	}
+	if strings.TrimSpace(t.Country) == "" {
+		return errors.New("target country cannot be empty")
 	}
-	mission, err := uc.missionRepo.GetMissionByID(ctx, t.MissionID)
+	code, ok := country.Normalize(t.Country)
+	if !ok {
+		return fmt.Errorf("%w: %q", domain.ErrUnknownCountry, t.Country)
+	}
+	t.Country = code
+	return nil
+}
+
+func normalizeCountryFilter(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	code, ok := country.Normalize(s)
+	if !ok {
+		return "", fmt.Errorf("%w: %q", domain.ErrUnknownCountry, s)
+	}
+	return code, nil
+}
+
+func (uc *MissionUsecase) UpdateTarget(ctx context.Context, id int64, upd domain.TargetUpdate) (*domain.Target, error) {
+	target, err := uc.missionRepo.GetTargetByID(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if existingTarget.Completed || mission.Completed {
-		if t.Notes != existingTarget.Notes {
-			return errors.New("cannot update notes because target or mission is completed")
+	mission, err := uc.missionRepo.GetMissionByID(ctx, target.MissionID)
+	if err != nil {
+		return nil, err
+	}
+	if upd.Notes != nil && *upd.Notes != target.Notes {
+		if target.Terminal() || mission.Closed() {
+			return nil, domain.ErrNotesLocked
 		}
+		target.Notes = *upd.Notes
 	}
-	return uc.missionRepo.UpdateTarget(ctx, t)
+	if err := uc.applyTargetStatus(target, upd); err != nil {
+		return nil, err
+	}
+	if upd.Deadline != nil {
+		if err := uc.checkTargetDeadline(mission, target.Deadline, upd.Deadline); err != nil {
+			return nil, err
+		}
+		target.Deadline = upd.Deadline
+	}
+	if err := uc.missionRepo.UpdateTarget(ctx, target); err != nil {
+		return nil, err
+	}
+	target.MarkOverdue(uc.now())
+	uc.publishTarget(ctx, mission, target)
+	return target, nil
+}
+
+// applyTargetStatus reports the status of target from upd. Completing a
+// target that is not terminal yet eliminates it; completing a terminal one
+// changes nothing.
+func (uc *MissionUsecase) applyTargetStatus(target *domain.Target, upd domain.TargetUpdate) error {
+	if upd.Outcome != nil && upd.Status == nil {
+		return domain.ErrOutcomeStatus
+	}
+	if upd.Status != nil {
+		if upd.Completed != nil && *upd.Completed != upd.Status.Terminal() {
+			return fmt.Errorf("%w: completed contradicts %s", domain.ErrInvalidTargetStatus, *upd.Status)
+		}
+		var outcome string
+		if upd.Outcome != nil {
+			outcome = strings.TrimSpace(*upd.Outcome)
+		}
+		return target.SetStatus(*upd.Status, outcome, uc.now())
+	}
+	if upd.Completed != nil {
+		if target.Terminal() && !*upd.Completed {
+			return domain.ErrTargetUncomplete
+		}
+		if !target.Terminal() && *upd.Completed {
+			return target.SetStatus(domain.TargetEliminated, "", uc.now())
+		}
+	}
+	return nil
+}
+
+func (uc *MissionUsecase) CompleteTarget(ctx context.Context, id int64) (*domain.Target, error) {
+	completed := true
+	return uc.UpdateTarget(ctx, id, domain.TargetUpdate{Completed: &completed})
 }
 
 func (uc *MissionUsecase) DeleteTarget(ctx context.Context, id int64) error {
@@ -93,8 +425,16 @@ func (uc *MissionUsecase) AssignCatToMission(ctx context.Context, missionID, cat
 		return err
 	}
 	if mission.CatID != nil && *mission.CatID != 0 {
-		return errors.New("mission already assigned to a cat")
+		return domain.ErrMissionAssigned
 	}
+	mission.State = mission.CurrentState()
 	mission.CatID = &catID
-	return uc.missionRepo.UpdateMission(ctx, mission)
+	if err := mission.Apply(domain.TransitionAssign, uc.now()); err != nil {
+		return err
+	}
+	if err := uc.missionRepo.AssignCat(ctx, mission); err != nil {
+		return err
+	}
+	uc.publishMission(ctx, event.MissionAssigned, mission)
+	return nil
 }
@@ -0,0 +1,293 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go-test-assesment/internal/mission/domain"
+	"go-test-assesment/internal/mission/usecase"
+	"go-test-assesment/pkg/blob"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAttachments keeps attachments in memory. With locked set it refuses
+// changes like the database does for a target that got locked meanwhile.
+// Like the database it queues the keys of deleted attachments in deleted.
+type fakeAttachments struct {
+	attachments map[int64]domain.Attachment
+	locked      bool
+	deleted     []string
+}
+
+func (f *fakeAttachments) AddAttachment(_ context.Context, a *domain.Attachment) error {
+	if f.locked {
+		return domain.ErrAttachmentsLocked
+	}
+	a.ID, a.CreatedAt = int64(len(f.attachments)+1), time.Now()
+	f.attachments[a.ID] = *a
+	return nil
+}
+
+func (f *fakeAttachments) GetAttachment(_ context.Context, targetID, id int64) (*domain.Attachment, error) {
+	a, ok := f.attachments[id]
+	if !ok || a.TargetID != targetID {
+		return nil, domain.ErrAttachmentNotFound
+	}
+	return &a, nil
+}
+
+func (f *fakeAttachments) ListAttachments(_ context.Context, targetID int64) ([]domain.Attachment, error) {
+	var list []domain.Attachment
+	for id := int64(1); id <= int64(len(f.attachments)); id++ {
+		if a, ok := f.attachments[id]; ok && a.TargetID == targetID {
+			list = append(list, a)
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeAttachments) DeleteAttachment(_ context.Context, targetID, id int64) error {
+	if f.locked {
+		return domain.ErrAttachmentsLocked
+	}
+	f.deleted = append(f.deleted, f.attachments[id].Key)
+	delete(f.attachments, id)
+	return nil
+}
+
+func (f *fakeAttachments) ListDeletedBlobs(_ context.Context, limit int) ([]string, error) {
+	return f.deleted[:min(limit, len(f.deleted))], nil
+}
+
+func (f *fakeAttachments) ForgetDeletedBlobs(_ context.Context, keys []string) error {
+	f.deleted = slices.DeleteFunc(f.deleted, func(key string) bool { return slices.Contains(keys, key) })
+	return nil
+}
+
+const pngHeader = "\x89PNG\r\n\x1a\n"
+
+func newAttachmentUsecase(t *testing.T, target domain.Target, mission domain.Mission) (*usecase.AttachmentUsecase, *fakeAttachments, *blob.DiskStore) {
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetTargetByID", mock.Anything, target.ID).Return(&target, nil)
+	mockRepo.On("GetTargetByID", mock.Anything, mock.Anything).Return(nil, domain.ErrTargetNotFound)
+	mockRepo.On("GetMissionByID", mock.Anything, mission.ID).Return(&mission, nil)
+	attachments := &fakeAttachments{attachments: map[int64]domain.Attachment{}}
+	store, err := blob.NewDiskStore(t.TempDir())
+	require.NoError(t, err)
+	return usecase.NewAttachmentUsecase(mockRepo, attachments, store, 64), attachments, store
+}
+
+func checksum(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestAttachmentUsecase_AddAttachment(t *testing.T) {
+	photo := pngHeader + "pixels"
+
+	tests := []struct {
+		name      string
+		target    domain.Target
+		mission   domain.Mission
+		upload    domain.AttachmentUpload
+		locked    bool
+		wantErr   error
+		wantName  string
+		wantType  string
+		wantStore bool
+	}{
+		{
+			name:     "photo",
+			upload:   domain.AttachmentUpload{Filename: `C:\photos\embassy.png`, Content: strings.NewReader(photo)},
+			wantName: "embassy.png",
+			wantType: "image/png",
+		},
+		{
+			name:     "text with checksum",
+			upload:   domain.AttachmentUpload{Filename: "report.txt", Content: strings.NewReader("all quiet"), SHA256: strings.ToUpper(checksum("all quiet"))},
+			wantName: "report.txt",
+			wantType: "text/plain; charset=utf-8",
+		},
+		{
+			name:    "checksum mismatch",
+			upload:  domain.AttachmentUpload{Filename: "report.txt", Content: strings.NewReader("all quiet"), SHA256: checksum("all loud")},
+			wantErr: domain.ErrChecksumMismatch,
+		},
+		{
+			name:    "sniffed type is not allowed",
+			upload:  domain.AttachmentUpload{Filename: "photo.png", Content: strings.NewReader("PK\x03\x04zipped")},
+			wantErr: domain.ErrAttachmentType,
+		},
+		{
+			name:    "too large",
+			upload:  domain.AttachmentUpload{Filename: "big.txt", Content: strings.NewReader(strings.Repeat("x", 65))},
+			wantErr: domain.ErrAttachmentTooLarge,
+		},
+		{
+			name:    "empty",
+			upload:  domain.AttachmentUpload{Filename: "empty.txt", Content: strings.NewReader("")},
+			wantErr: domain.ErrAttachmentEmpty,
+		},
+		{
+			name:    "no filename",
+			upload:  domain.AttachmentUpload{Filename: " ", Content: strings.NewReader(photo)},
+			wantErr: domain.ErrInvalidFilename,
+		},
+		{
+			name:    "control characters in filename",
+			upload:  domain.AttachmentUpload{Filename: "a\nb.png", Content: strings.NewReader(photo)},
+			wantErr: domain.ErrInvalidFilename,
+		},
+		{
+			name:    "terminal target",
+			target:  domain.Target{Status: domain.TargetEliminated, Completed: true},
+			upload:  domain.AttachmentUpload{Filename: "photo.png", Content: strings.NewReader(photo)},
+			wantErr: domain.ErrAttachmentsLocked,
+		},
+		{
+			name:    "closed mission",
+			mission: domain.Mission{State: domain.StateFailed},
+			upload:  domain.AttachmentUpload{Filename: "photo.png", Content: strings.NewReader(photo)},
+			wantErr: domain.ErrAttachmentsLocked,
+		},
+		{
+			name:    "locked while uploading",
+			upload:  domain.AttachmentUpload{Filename: "photo.png", Content: strings.NewReader(photo)},
+			locked:  true,
+			wantErr: domain.ErrAttachmentsLocked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.target.ID, tt.target.MissionID, tt.mission.ID = 3, 1, 1
+			if tt.mission.State == "" {
+				tt.mission.State = domain.StateInProgress
+			}
+			uc, attachments, store := newAttachmentUsecase(t, tt.target, tt.mission)
+			attachments.locked = tt.locked
+
+			a, err := uc.AddAttachment(context.Background(), 3, tt.upload)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, attachments.attachments)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantName, a.Filename)
+			assert.Equal(t, tt.wantType, a.ContentType)
+			assert.Regexp(t, `^targets/3/[0-9a-f]{32}$`, a.Key)
+
+			r, err := store.Get(context.Background(), a.Key)
+			require.NoError(t, err)
+			defer r.Close()
+			content, err := io.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(content)), a.Size)
+			assert.Equal(t, checksum(string(content)), a.SHA256)
+		})
+	}
+}
+
+func TestAttachmentUsecase_LockedUploadLeavesNoBlob(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetTargetByID", mock.Anything, int64(3)).Return(&domain.Target{ID: 3, MissionID: 1}, nil)
+	mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1, State: domain.StateInProgress}, nil)
+	dir := t.TempDir()
+	store, err := blob.NewDiskStore(dir)
+	require.NoError(t, err)
+	uc := usecase.NewAttachmentUsecase(mockRepo, &fakeAttachments{locked: true}, store, 64)
+
+	_, err = uc.AddAttachment(context.Background(), 3, domain.AttachmentUpload{Filename: "a.txt", Content: strings.NewReader("intel")})
+	require.ErrorIs(t, err, domain.ErrAttachmentsLocked)
+
+	var files []string
+	require.NoError(t, filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files = append(files, path)
+		}
+		return err
+	}))
+	assert.Empty(t, files)
+}
+
+func TestAttachmentUsecase_OpenAndDelete(t *testing.T) {
+	target := domain.Target{ID: 3, MissionID: 1}
+	uc, attachments, store := newAttachmentUsecase(t, target, domain.Mission{ID: 1, State: domain.StateAssigned})
+	ctx := context.Background()
+
+	a, err := uc.AddAttachment(ctx, 3, domain.AttachmentUpload{Filename: "a.txt", Content: strings.NewReader("intel")})
+	require.NoError(t, err)
+
+	list, err := uc.ListAttachments(ctx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Attachment{*a}, list)
+	_, err = uc.ListAttachments(ctx, 4)
+	assert.ErrorIs(t, err, domain.ErrTargetNotFound)
+
+	got, content, err := uc.OpenAttachment(ctx, 3, a.ID)
+	require.NoError(t, err)
+	data, err := io.ReadAll(content)
+	content.Close()
+	require.NoError(t, err)
+	assert.Equal(t, a, got)
+	assert.Equal(t, "intel", string(data))
+
+	_, _, err = uc.OpenAttachment(ctx, 4, a.ID)
+	assert.ErrorIs(t, err, domain.ErrAttachmentNotFound, "attachment of another target")
+
+	attachments.locked = true
+	assert.ErrorIs(t, uc.DeleteAttachment(ctx, 3, a.ID), domain.ErrAttachmentsLocked)
+	attachments.locked = false
+
+	require.NoError(t, uc.DeleteAttachment(ctx, 3, a.ID))
+	_, err = store.Get(ctx, a.Key)
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+	assert.ErrorIs(t, uc.DeleteAttachment(ctx, 3, a.ID), domain.ErrAttachmentNotFound)
+}
+
+func TestAttachmentUsecase_DeleteLocked(t *testing.T) {
+	target := domain.Target{ID: 3, MissionID: 1, Status: domain.TargetEscaped, Completed: true}
+	uc, attachments, _ := newAttachmentUsecase(t, target, domain.Mission{ID: 1, State: domain.StateInProgress})
+	attachments.attachments[1] = domain.Attachment{ID: 1, TargetID: 3, Key: "targets/3/a"}
+
+	assert.ErrorIs(t, uc.DeleteAttachment(context.Background(), 3, 1), domain.ErrAttachmentsLocked)
+	assert.Len(t, attachments.attachments, 1)
+}
+
+func TestAttachmentUsecase_SweepDeletedBlobs(t *testing.T) {
+	uc, attachments, store := newAttachmentUsecase(t, domain.Target{ID: 3, MissionID: 1}, domain.Mission{ID: 1})
+	ctx := context.Background()
+
+	// Attachments deleted along with their target leave their keys queued
+	// and their contents in the store.
+	for i := range 150 {
+		key := fmt.Sprintf("targets/3/%03d", i)
+		require.NoError(t, store.Put(ctx, key, strings.NewReader("intel"), 5, "text/plain"))
+		attachments.deleted = append(attachments.deleted, key)
+	}
+	attachments.deleted = append(attachments.deleted, "targets/3/gone")
+
+	removed, err := uc.SweepDeletedBlobs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 151, removed)
+	assert.Empty(t, attachments.deleted)
+	_, err = store.Get(ctx, "targets/3/149")
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+
+	removed, err = uc.SweepDeletedBlobs(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+}
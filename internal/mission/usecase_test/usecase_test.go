@@ -2,13 +2,18 @@ package usecase_test
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
+	event "go-test-assesment/internal/event/domain"
 	"go-test-assesment/internal/mission/domain"
 	"go-test-assesment/internal/mission/usecase"
+	"go-test-assesment/pkg/money"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type MockRepository struct {
@@ -28,16 +33,16 @@ func (m *MockRepository) GetMissionByID(ctx context.Context, id int64) (*domain.
 	return nil, args.Error(1)
 }
 
-func (m *MockRepository) ListMissions(ctx context.Context) ([]*domain.Mission, error) {
-	args := m.Called(ctx)
+func (m *MockRepository) ListMissions(ctx context.Context, filter domain.MissionFilter) ([]*domain.Mission, error) {
+	args := m.Called(ctx, filter)
 	if obj := args.Get(0); obj != nil {
 		return obj.([]*domain.Mission), args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
-func (m *MockRepository) UpdateMission(ctx context.Context, mission *domain.Mission) error {
-	args := m.Called(ctx, mission)
+func (m *MockRepository) UpdateMission(ctx context.Context, mission *domain.Mission, from domain.State) error {
+	args := m.Called(ctx, mission, from)
 	return args.Error(0)
 }
 
@@ -46,9 +51,12 @@ func (m *MockRepository) DeleteMission(ctx context.Context, id int64) error {
 	return args.Error(0)
 }
 
-func (m *MockRepository) AddTargets(ctx context.Context, targets []domain.Target) error {
-	args := m.Called(ctx, targets)
-	return args.Error(0)
+func (m *MockRepository) AddTargets(ctx context.Context, targets []domain.Target, atomic bool) ([]domain.TargetImportError, error) {
+	args := m.Called(ctx, targets, atomic)
+	if obj := args.Get(0); obj != nil {
+		return obj.([]domain.TargetImportError), args.Error(1)
+	}
+	return nil, args.Error(1)
 }
 
 func (m *MockRepository) UpdateTarget(ctx context.Context, target *domain.Target) error {
@@ -64,16 +72,90 @@ func (m *MockRepository) GetTargetByID(ctx context.Context, id int64) (*domain.T
 	return nil, args.Error(1)
 }
 
+func (m *MockRepository) AddTargetNote(ctx context.Context, note *domain.TargetNote) error {
+	args := m.Called(ctx, note)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListTargetNotes(ctx context.Context, targetID int64) ([]domain.TargetNote, error) {
+	args := m.Called(ctx, targetID)
+	if obj := args.Get(0); obj != nil {
+		return obj.([]domain.TargetNote), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockRepository) ListTargets(ctx context.Context, missionID int64, filter domain.TargetFilter) ([]domain.Target, error) {
+	args := m.Called(ctx, missionID, filter)
+	if obj := args.Get(0); obj != nil {
+		return obj.([]domain.Target), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockRepository) SearchTargets(ctx context.Context, search domain.TargetSearch) ([]domain.TargetSearchResult, int, error) {
+	args := m.Called(ctx, search)
+	if obj := args.Get(0); obj != nil {
+		return obj.([]domain.TargetSearchResult), args.Int(1), args.Error(2)
+	}
+	return nil, args.Int(1), args.Error(2)
+}
+
+func (m *MockRepository) ExportMissions(ctx context.Context, filter domain.TargetFilter, fn func(domain.MissionExportRow) error) error {
+	args := m.Called(ctx, filter)
+	rows, _ := args.Get(0).([]domain.MissionExportRow)
+	for _, r := range rows {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
 func (m *MockRepository) DeleteTarget(ctx context.Context, id int64) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockRepository) ListCandidates(ctx context.Context) ([]domain.Candidate, error) {
+	args := m.Called(ctx)
+	if obj := args.Get(0); obj != nil {
+		return obj.([]domain.Candidate), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockRepository) AssignCat(ctx context.Context, mission *domain.Mission) error {
+	args := m.Called(ctx, mission.ID, *mission.CatID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UpdateState(ctx context.Context, mission *domain.Mission, from domain.State) error {
+	args := m.Called(ctx, mission, from)
+	return args.Error(0)
+}
+
+type recordingPublisher struct {
+	events []event.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e event.Event) {
+	p.events = append(p.events, e)
+}
+
+func (p *recordingPublisher) types() []event.Type {
+	var types []event.Type
+	for _, e := range p.events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
 func TestMissionUsecase_CreateMission(t *testing.T) {
 	mockRepo := new(MockRepository)
 	mockRepo.On("CreateMission", mock.Anything, mock.AnythingOfType("*domain.Mission")).Return(nil)
 
-	uc := usecase.NewMissionUsecase(mockRepo)
+	uc := usecase.NewMissionUsecase(mockRepo, nil)
 
 	err := uc.CreateMission(context.Background(), &domain.Mission{})
 	assert.NoError(t, err)
@@ -85,7 +167,7 @@ func TestMissionUsecase_GetMissionByID(t *testing.T) {
 	expectedMission := &domain.Mission{ID: 42}
 	mockRepo.On("GetMissionByID", mock.Anything, int64(42)).Return(expectedMission, nil)
 
-	uc := usecase.NewMissionUsecase(mockRepo)
+	uc := usecase.NewMissionUsecase(mockRepo, nil)
 
 	mission, err := uc.GetMissionByID(context.Background(), 42)
 	assert.NoError(t, err)
@@ -96,11 +178,11 @@ func TestMissionUsecase_GetMissionByID(t *testing.T) {
 func TestMissionUsecase_ListMissions(t *testing.T) {
 	mockRepo := new(MockRepository)
 	missions := []*domain.Mission{{ID: 1}, {ID: 2}}
-	mockRepo.On("ListMissions", mock.Anything).Return(missions, nil)
+	mockRepo.On("ListMissions", mock.Anything, mock.Anything).Return(missions, nil)
 
-	uc := usecase.NewMissionUsecase(mockRepo)
+	uc := usecase.NewMissionUsecase(mockRepo, nil)
 
-	result, err := uc.ListMissions(context.Background())
+	result, err := uc.ListMissions(context.Background(), domain.MissionFilter{})
 	assert.NoError(t, err)
 	assert.Equal(t, missions, result)
 	mockRepo.AssertExpectations(t)
@@ -110,9 +192,9 @@ func TestMissionUsecase_UpdateMission(t *testing.T) {
 	mockRepo := new(MockRepository)
 
 	mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1, Completed: false}, nil)
-	mockRepo.On("UpdateMission", mock.Anything, mock.AnythingOfType("*domain.Mission")).Return(nil)
+	mockRepo.On("UpdateMission", mock.Anything, mock.AnythingOfType("*domain.Mission"), mock.Anything).Return(nil)
 
-	uc := usecase.NewMissionUsecase(mockRepo)
+	uc := usecase.NewMissionUsecase(mockRepo, nil)
 	err := uc.UpdateMission(context.Background(), &domain.Mission{ID: 1})
 	assert.NoError(t, err)
 
@@ -120,7 +202,7 @@ func TestMissionUsecase_UpdateMission(t *testing.T) {
 	mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1, Completed: true}, nil)
 
 	err = uc.UpdateMission(context.Background(), &domain.Mission{ID: 1})
-	assert.EqualError(t, err, "cannot update a completed mission")
+	assert.ErrorIs(t, err, domain.ErrMissionClosed)
 
 	mockRepo.AssertExpectations(t)
 }
@@ -131,7 +213,7 @@ func TestMissionUsecase_DeleteMission(t *testing.T) {
 	mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1, CatID: nil}, nil)
 	mockRepo.On("DeleteMission", mock.Anything, int64(1)).Return(nil)
 
-	uc := usecase.NewMissionUsecase(mockRepo)
+	uc := usecase.NewMissionUsecase(mockRepo, nil)
 	err := uc.DeleteMission(context.Background(), 1)
 	assert.NoError(t, err)
 
@@ -140,7 +222,7 @@ func TestMissionUsecase_DeleteMission(t *testing.T) {
 	mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1, CatID: &catID}, nil)
 
 	err = uc.DeleteMission(context.Background(), 1)
-	assert.EqualError(t, err, "mission cannot be deleted because it is assigned to a cat")
+	assert.ErrorIs(t, err, domain.ErrMissionAssigned)
 
 	mockRepo.AssertExpectations(t)
 }
@@ -149,53 +231,260 @@ func TestMissionUsecase_AddTargets(t *testing.T) {
 	mockRepo := new(MockRepository)
 
 	mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1, Completed: false}, nil)
-	mockRepo.On("AddTargets", mock.Anything, mock.AnythingOfType("[]domain.Target")).Return(nil)
+	mockRepo.On("AddTargets", mock.Anything, mock.AnythingOfType("[]domain.Target"), true).
+		Run(func(args mock.Arguments) {
+			targets := args.Get(1).([]domain.Target)
+			for i := range targets {
+				targets[i].ID = int64(i + 100)
+			}
+		}).Return(nil, nil)
 
-	uc := usecase.NewMissionUsecase(mockRepo)
-	err := uc.AddTargets(context.Background(), 1, []domain.Target{{Name: "Target1"}})
+	uc := usecase.NewMissionUsecase(mockRepo, nil)
+	report, err := uc.AddTargets(context.Background(), 1, []domain.Target{{Name: "Target1", Country: "UK"}}, domain.ImportAllOrNothing)
 	assert.NoError(t, err)
+	assert.Len(t, report.Created, 1)
+	assert.Equal(t, int64(100), report.Created[0].ID)
+	assert.Equal(t, int64(1), report.Created[0].MissionID)
+	assert.Empty(t, report.Errors)
 
 	mockRepo.ExpectedCalls = nil
 	mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1, Completed: true}, nil)
 
-	err = uc.AddTargets(context.Background(), 1, []domain.Target{{Name: "Target1"}})
+	_, err = uc.AddTargets(context.Background(), 1, []domain.Target{{Name: "Target1", Country: "UK"}}, domain.ImportAllOrNothing)
 	assert.EqualError(t, err, "cannot add targets to a completed mission")
 
 	mockRepo.AssertExpectations(t)
 }
 
-func TestMissionUsecase_UpdateTarget(t *testing.T) {
+func TestMissionUsecase_AddTargets_NormalizesCountry(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1}, nil)
+	mockRepo.On("AddTargets", mock.Anything, mock.MatchedBy(func(ts []domain.Target) bool {
+		return len(ts) == 2 && ts[0].Country == "US" && ts[1].Country == "CI"
+	}), false).Return(nil, nil)
+
+	uc := usecase.NewMissionUsecase(mockRepo, nil)
+	report, err := uc.AddTargets(context.Background(), 1, []domain.Target{
+		{Name: "Boris", Country: "United States of America"},
+		{Name: "Ivan", Country: "Atlantis"},
+		{Name: "Olga", Country: "cote d'ivoire"},
+	}, domain.ImportBestEffort)
+	require.NoError(t, err)
+	assert.Equal(t, "US", report.Created[0].Country)
+	assert.Equal(t, "CI", report.Created[1].Country)
+	require.Len(t, report.Errors, 1)
+	assert.Equal(t, 1, report.Errors[0].Index)
+	assert.Contains(t, report.Errors[0].Error, domain.ErrUnknownCountry.Error())
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestMissionUsecase_AddTargets_Modes(t *testing.T) {
+	input := []domain.Target{
+		{Name: "Boris", Country: "UK"},
+		{Name: "Ivan"},
+		{Name: "Boris", Country: "FR"},
+		{Name: "Olga", Country: "PL"},
+	}
+
+	tests := []struct {
+		name        string
+		mode        domain.ImportMode
+		repoFailed  []domain.TargetImportError
+		callsRepo   bool
+		wantErr     error
+		wantCreated []string
+		wantErrIdx  []int
+	}{
+		{
+			name:       "all or nothing rejects invalid items before touching the database",
+			mode:       domain.ImportAllOrNothing,
+			wantErr:    domain.ErrImportRejected,
+			wantErrIdx: []int{1, 2},
+		},
+		{
+			name:        "best effort creates valid items",
+			mode:        domain.ImportBestEffort,
+			callsRepo:   true,
+			wantCreated: []string{"Boris", "Olga"},
+			wantErrIdx:  []int{1, 2},
+		},
+		{
+			name:      "best effort reports database conflicts with original indices",
+			mode:      domain.ImportBestEffort,
+			callsRepo: true,
+			repoFailed: []domain.TargetImportError{
+				{Index: 1, Name: "Olga", Error: domain.ErrDuplicateTarget.Error()},
+			},
+			wantCreated: []string{"Boris"},
+			wantErrIdx:  []int{1, 2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1}, nil)
+			if tt.callsRepo {
+				mockRepo.On("AddTargets", mock.Anything, mock.MatchedBy(func(ts []domain.Target) bool {
+					return len(ts) == 2 && ts[0].Name == "Boris" && ts[1].Name == "Olga"
+				}), false).Return(tt.repoFailed, nil)
+			}
+
+			uc := usecase.NewMissionUsecase(mockRepo, nil)
+			report, err := uc.AddTargets(context.Background(), 1, input, tt.mode)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			var created []string
+			for _, c := range report.Created {
+				created = append(created, c.Name)
+			}
+			assert.Equal(t, tt.wantCreated, created)
+
+			var errIdx []int
+			for _, e := range report.Errors {
+				errIdx = append(errIdx, e.Index)
+			}
+			assert.Equal(t, tt.wantErrIdx, errIdx)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMissionUsecase_AddTargets_AtomicConflict(t *testing.T) {
 	mockRepo := new(MockRepository)
+	mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1}, nil)
+	mockRepo.On("AddTargets", mock.Anything, mock.AnythingOfType("[]domain.Target"), true).
+		Return([]domain.TargetImportError{{Index: 0, Name: "Boris", Error: domain.ErrDuplicateTarget.Error()}}, nil)
+
+	uc := usecase.NewMissionUsecase(mockRepo, nil)
+	report, err := uc.AddTargets(context.Background(), 1, []domain.Target{
+		{Name: "Boris", Country: "UK"},
+		{Name: "Olga", Country: "PL"},
+	}, domain.ImportAllOrNothing)
+
+	assert.ErrorIs(t, err, domain.ErrImportRejected)
+	assert.Empty(t, report.Created)
+	assert.Len(t, report.Errors, 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestMissionUsecase_UpdateTarget(t *testing.T) {
+	newNotes := "new notes"
+	sameNotes := "old notes"
+	completed := true
+	notCompleted := false
 
-	existingTarget := &domain.Target{
-		ID:        1,
-		MissionID: 10,
-		Notes:     "old notes",
-		Completed: false,
+	tests := []struct {
+		name          string
+		target        domain.Target
+		mission       domain.Mission
+		update        domain.TargetUpdate
+		wantErr       error
+		wantNotes     string
+		wantCompleted bool
+	}{
+		{
+			name:      "update notes",
+			target:    domain.Target{ID: 1, MissionID: 10, Notes: "old notes"},
+			mission:   domain.Mission{ID: 10},
+			update:    domain.TargetUpdate{Notes: &newNotes},
+			wantNotes: "new notes",
+		},
+		{
+			name:          "complete target",
+			target:        domain.Target{ID: 1, MissionID: 10, Notes: "old notes"},
+			mission:       domain.Mission{ID: 10},
+			update:        domain.TargetUpdate{Completed: &completed},
+			wantNotes:     "old notes",
+			wantCompleted: true,
+		},
+		{
+			name:    "notes locked by completed target",
+			target:  domain.Target{ID: 1, MissionID: 10, Notes: "old notes", Completed: true},
+			mission: domain.Mission{ID: 10},
+			update:  domain.TargetUpdate{Notes: &newNotes},
+			wantErr: domain.ErrNotesLocked,
+		},
+		{
+			name:    "notes locked by completed mission",
+			target:  domain.Target{ID: 1, MissionID: 10, Notes: "old notes"},
+			mission: domain.Mission{ID: 10, Completed: true},
+			update:  domain.TargetUpdate{Notes: &newNotes},
+			wantErr: domain.ErrNotesLocked,
+		},
+		{
+			name:          "unchanged notes allowed on completed target",
+			target:        domain.Target{ID: 1, MissionID: 10, Notes: "old notes", Completed: true},
+			mission:       domain.Mission{ID: 10},
+			update:        domain.TargetUpdate{Notes: &sameNotes, Completed: &completed},
+			wantNotes:     "old notes",
+			wantCompleted: true,
+		},
+		{
+			name:    "cannot un-complete target",
+			target:  domain.Target{ID: 1, MissionID: 10, Completed: true},
+			mission: domain.Mission{ID: 10},
+			update:  domain.TargetUpdate{Completed: &notCompleted},
+			wantErr: domain.ErrTargetUncomplete,
+		},
 	}
 
-	mission := &domain.Mission{
-		ID:        10,
-		Completed: false,
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			stored := tt.target
+			mission := tt.mission
+			mockRepo.On("GetTargetByID", mock.Anything, int64(1)).Return(&stored, nil)
+			// The mission is always resolved from the stored target, never from client input.
+			mockRepo.On("GetMissionByID", mock.Anything, tt.target.MissionID).Return(&mission, nil)
+			if tt.wantErr == nil {
+				mockRepo.On("UpdateTarget", mock.Anything, mock.AnythingOfType("*domain.Target")).Return(nil)
+			}
+
+			uc := usecase.NewMissionUsecase(mockRepo, nil)
+			result, err := uc.UpdateTarget(context.Background(), 1, tt.update)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantNotes, result.Notes)
+				assert.Equal(t, tt.wantCompleted, result.Completed)
+				assert.Equal(t, tt.target.MissionID, result.MissionID)
+			}
+			mockRepo.AssertExpectations(t)
+		})
 	}
+}
 
-	mockRepo.On("GetTargetByID", mock.Anything, int64(1)).Return(existingTarget, nil)
-	mockRepo.On("GetMissionByID", mock.Anything, int64(10)).Return(mission, nil)
+func TestMissionUsecase_CompleteTarget(t *testing.T) {
+	mockRepo := new(MockRepository)
 
+	mockRepo.On("GetTargetByID", mock.Anything, int64(1)).Return(&domain.Target{ID: 1, MissionID: 10}, nil)
+	mockRepo.On("GetMissionByID", mock.Anything, int64(10)).Return(&domain.Mission{ID: 10}, nil)
 	mockRepo.On("UpdateTarget", mock.Anything, mock.MatchedBy(func(t *domain.Target) bool {
-		fmt.Printf("UpdateTarget called with: %+v\n", t)
-		return true
+		return t.ID == 1 && t.Completed
 	})).Return(nil)
 
-	uc := usecase.NewMissionUsecase(mockRepo)
-
-	err := uc.UpdateTarget(context.Background(), &domain.Target{
-		ID:        1,
-		MissionID: 10,
-		Notes:     "new notes",
-		Completed: false,
-	})
+	uc := usecase.NewMissionUsecase(mockRepo, nil)
+	target, err := uc.CompleteTarget(context.Background(), 1)
 	assert.NoError(t, err)
+	assert.True(t, target.Completed)
+
+	mockRepo.ExpectedCalls = nil
+	mockRepo.On("GetTargetByID", mock.Anything, int64(2)).Return(nil, domain.ErrTargetNotFound)
+
+	_, err = uc.CompleteTarget(context.Background(), 2)
+	assert.ErrorIs(t, err, domain.ErrTargetNotFound)
+
+	mockRepo.AssertExpectations(t)
 }
 
 func TestMissionUsecase_DeleteTarget(t *testing.T) {
@@ -204,7 +493,7 @@ func TestMissionUsecase_DeleteTarget(t *testing.T) {
 	mockRepo.On("GetTargetByID", mock.Anything, int64(1)).Return(&domain.Target{ID: 1, Completed: false}, nil)
 	mockRepo.On("DeleteTarget", mock.Anything, int64(1)).Return(nil)
 
-	uc := usecase.NewMissionUsecase(mockRepo)
+	uc := usecase.NewMissionUsecase(mockRepo, nil)
 	err := uc.DeleteTarget(context.Background(), 1)
 	assert.NoError(t, err)
 
@@ -221,12 +510,20 @@ func TestMissionUsecase_AssignCatToMission(t *testing.T) {
 	mockRepo := new(MockRepository)
 
 	mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1, CatID: nil}, nil)
-	mockRepo.On("UpdateMission", mock.Anything, mock.AnythingOfType("*domain.Mission")).Return(nil)
+	mockRepo.On("AssignCat", mock.Anything, int64(1), int64(42)).Return(nil)
 
-	uc := usecase.NewMissionUsecase(mockRepo)
+	uc := usecase.NewMissionUsecase(mockRepo, nil)
 	err := uc.AssignCatToMission(context.Background(), 1, 42)
 	assert.NoError(t, err)
 
+	// The cat took another mission meanwhile.
+	mockRepo.ExpectedCalls = nil
+	mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1, CatID: nil}, nil)
+	mockRepo.On("AssignCat", mock.Anything, int64(1), int64(42)).Return(domain.ErrCatUnavailable)
+
+	err = uc.AssignCatToMission(context.Background(), 1, 42)
+	assert.ErrorIs(t, err, domain.ErrCatUnavailable)
+
 	mockRepo.ExpectedCalls = nil
 	catID := int64(5)
 	mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1, CatID: &catID}, nil)
@@ -236,3 +533,901 @@ func TestMissionUsecase_AssignCatToMission(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestMissionUsecase_GetTargetByID(t *testing.T) {
+	mockRepo := new(MockRepository)
+	expected := &domain.Target{ID: 3, MissionID: 1, Name: "Boris"}
+	mockRepo.On("GetTargetByID", mock.Anything, int64(3)).Return(expected, nil)
+
+	uc := usecase.NewMissionUsecase(mockRepo, nil)
+
+	target, err := uc.GetTargetByID(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, target)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestMissionUsecase_ListTargets(t *testing.T) {
+	mockRepo := new(MockRepository)
+	completed := true
+	filter := domain.TargetFilter{Completed: &completed, Country: "UK"}
+	targets := []domain.Target{{ID: 1, MissionID: 1, Country: "GB", Completed: true}}
+
+	mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1}, nil)
+	mockRepo.On("ListTargets", mock.Anything, int64(1), domain.TargetFilter{Completed: &completed, Country: "GB"}).
+		Return(targets, nil)
+
+	uc := usecase.NewMissionUsecase(mockRepo, nil)
+	result, err := uc.ListTargets(context.Background(), 1, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, targets, result)
+
+	_, err = uc.ListTargets(context.Background(), 1, domain.TargetFilter{Country: "Atlantis"})
+	assert.ErrorIs(t, err, domain.ErrUnknownCountry)
+
+	mockRepo.ExpectedCalls = nil
+	mockRepo.On("GetMissionByID", mock.Anything, int64(2)).Return(nil, domain.ErrMissionNotFound)
+
+	_, err = uc.ListTargets(context.Background(), 2, filter)
+	assert.ErrorIs(t, err, domain.ErrMissionNotFound)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestMissionUsecase_SearchTargets(t *testing.T) {
+	mockRepo := new(MockRepository)
+	items := []domain.TargetSearchResult{{Target: domain.Target{ID: 1}}}
+
+	mockRepo.On("SearchTargets", mock.Anything, domain.TargetSearch{Query: "embassy", Limit: 20, Offset: 40}).
+		Return(items, 41, nil)
+
+	uc := usecase.NewMissionUsecase(mockRepo, nil)
+	page, err := uc.SearchTargets(context.Background(), domain.TargetSearch{Query: "  embassy ", Limit: 500, Offset: 40})
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.TargetSearchPage{Items: items, Total: 41, Limit: 20, Offset: 40}, page)
+
+	_, err = uc.SearchTargets(context.Background(), domain.TargetSearch{Offset: -1})
+	assert.ErrorIs(t, err, domain.ErrInvalidPage)
+
+	_, err = uc.SearchTargets(context.Background(), domain.TargetSearch{Query: strings.Repeat("x", 201)})
+	assert.ErrorIs(t, err, domain.ErrQueryTooLong)
+
+	mockRepo.On("SearchTargets", mock.Anything, domain.TargetSearch{Country: "US", Limit: 20}).
+		Return([]domain.TargetSearchResult{}, 0, nil)
+	_, err = uc.SearchTargets(context.Background(), domain.TargetSearch{Country: "united states"})
+	assert.NoError(t, err)
+
+	_, err = uc.SearchTargets(context.Background(), domain.TargetSearch{Country: "Atlantis"})
+	assert.ErrorIs(t, err, domain.ErrUnknownCountry)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestMissionUsecase_ExportMissions(t *testing.T) {
+	mockRepo := new(MockRepository)
+	rows := []domain.MissionExportRow{{MissionID: 1}, {MissionID: 2}}
+	mockRepo.On("ExportMissions", mock.Anything, domain.TargetFilter{Country: "NL"}).Return(rows, nil)
+
+	uc := usecase.NewMissionUsecase(mockRepo, nil)
+	var got []int64
+	err := uc.ExportMissions(context.Background(), domain.TargetFilter{Country: "Holland"}, func(r domain.MissionExportRow) error {
+		got = append(got, r.MissionID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, got)
+
+	err = uc.ExportMissions(context.Background(), domain.TargetFilter{Country: "Atlantis"}, nil)
+	assert.ErrorIs(t, err, domain.ErrUnknownCountry)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestMissionUsecase_PublishesEvents(t *testing.T) {
+	catID := int64(7)
+	notes := "spotted"
+
+	tests := []struct {
+		name      string
+		setup     func(m *MockRepository)
+		run       func(uc *usecase.MissionUsecase) error
+		wantErr   error
+		wantTypes []event.Type
+	}{
+		{
+			name: "create",
+			setup: func(m *MockRepository) {
+				m.On("CreateMission", mock.Anything, mock.AnythingOfType("*domain.Mission")).Return(nil)
+			},
+			run: func(uc *usecase.MissionUsecase) error {
+				return uc.CreateMission(context.Background(), &domain.Mission{})
+			},
+			wantTypes: []event.Type{event.MissionCreated},
+		},
+		{
+			name: "assign",
+			setup: func(m *MockRepository) {
+				m.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1}, nil)
+				m.On("AssignCat", mock.Anything, int64(1), catID).Return(nil)
+			},
+			run: func(uc *usecase.MissionUsecase) error {
+				return uc.AssignCatToMission(context.Background(), 1, catID)
+			},
+			wantTypes: []event.Type{event.MissionAssigned},
+		},
+		{
+			name: "update assigns",
+			setup: func(m *MockRepository) {
+				m.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1}, nil)
+				m.On("UpdateMission", mock.Anything, mock.AnythingOfType("*domain.Mission"), mock.Anything).Return(nil)
+			},
+			run: func(uc *usecase.MissionUsecase) error {
+				return uc.UpdateMission(context.Background(), &domain.Mission{ID: 1, CatID: &catID})
+			},
+			wantTypes: []event.Type{event.MissionAssigned},
+		},
+		{
+			name: "update completes",
+			setup: func(m *MockRepository) {
+				m.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{
+					ID: 1, CatID: &catID, State: domain.StateInProgress,
+					Targets: []domain.Target{{Status: domain.TargetEliminated, Completed: true}},
+				}, nil)
+				m.On("UpdateMission", mock.Anything, mock.AnythingOfType("*domain.Mission"), mock.Anything).Return(nil)
+			},
+			run: func(uc *usecase.MissionUsecase) error {
+				return uc.UpdateMission(context.Background(), &domain.Mission{ID: 1, CatID: &catID, Completed: true})
+			},
+			wantTypes: []event.Type{event.MissionCompleted},
+		},
+		{
+			name: "update without changes",
+			setup: func(m *MockRepository) {
+				m.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1, CatID: &catID}, nil)
+				m.On("UpdateMission", mock.Anything, mock.AnythingOfType("*domain.Mission"), mock.Anything).Return(nil)
+			},
+			run: func(uc *usecase.MissionUsecase) error {
+				return uc.UpdateMission(context.Background(), &domain.Mission{ID: 1, CatID: &catID})
+			},
+		},
+		{
+			name: "target update",
+			setup: func(m *MockRepository) {
+				m.On("GetTargetByID", mock.Anything, int64(3)).Return(&domain.Target{ID: 3, MissionID: 1}, nil)
+				m.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1, CatID: &catID}, nil)
+				m.On("UpdateTarget", mock.Anything, mock.AnythingOfType("*domain.Target")).Return(nil)
+			},
+			run: func(uc *usecase.MissionUsecase) error {
+				_, err := uc.UpdateTarget(context.Background(), 3, domain.TargetUpdate{Notes: &notes})
+				return err
+			},
+			wantTypes: []event.Type{event.TargetUpdated},
+		},
+		{
+			name: "failed update publishes nothing",
+			setup: func(m *MockRepository) {
+				m.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1}, nil)
+				m.On("AssignCat", mock.Anything, int64(1), catID).Return(domain.ErrCatUnavailable)
+			},
+			run: func(uc *usecase.MissionUsecase) error {
+				return uc.AssignCatToMission(context.Background(), 1, catID)
+			},
+			wantErr: domain.ErrCatUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			tt.setup(mockRepo)
+			pub := &recordingPublisher{}
+
+			uc := usecase.NewMissionUsecase(mockRepo, pub)
+			err := tt.run(uc)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantTypes, pub.types())
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMissionUsecase_TargetEventCarriesMissionAndCat(t *testing.T) {
+	catID := int64(7)
+	completed := true
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetTargetByID", mock.Anything, int64(3)).Return(&domain.Target{ID: 3, MissionID: 1}, nil)
+	mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1, CatID: &catID}, nil)
+	mockRepo.On("UpdateTarget", mock.Anything, mock.AnythingOfType("*domain.Target")).Return(nil)
+	pub := &recordingPublisher{}
+
+	uc := usecase.NewMissionUsecase(mockRepo, pub)
+	_, err := uc.UpdateTarget(context.Background(), 3, domain.TargetUpdate{Completed: &completed})
+	assert.NoError(t, err)
+
+	assert.Len(t, pub.events, 1)
+	e := pub.events[0]
+	assert.Equal(t, int64(1), e.MissionID)
+	assert.Equal(t, &catID, e.CatID)
+	assert.Equal(t, int64(3), *e.TargetID)
+	var data map[string]any
+	require.NoError(t, json.Unmarshal(e.Data, &data))
+	assert.NotEmpty(t, data["eliminated_at"])
+	delete(data, "eliminated_at")
+	assert.Equal(t, map[string]any{
+		"id": 3.0, "mission_id": 1.0, "name": "", "country": "", "notes": "", "status": "eliminated",
+		"completed": true, "overdue": false, "created_at": "0001-01-01T00:00:00Z", "updated_at": "0001-01-01T00:00:00Z",
+	}, data)
+}
+
+func TestMissionUsecase_Deadlines(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	soon := time.Now().Add(time.Hour)
+	later := time.Now().Add(48 * time.Hour)
+
+	t.Run("create rejects a past deadline", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		uc := usecase.NewMissionUsecase(mockRepo, nil)
+		err := uc.CreateMission(context.Background(), &domain.Mission{Deadline: &past})
+		assert.ErrorIs(t, err, domain.ErrDeadlinePassed)
+		mockRepo.AssertNotCalled(t, "CreateMission", mock.Anything, mock.Anything)
+	})
+
+	t.Run("target deadline after mission deadline", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1, Deadline: &soon}, nil)
+		uc := usecase.NewMissionUsecase(mockRepo, nil)
+
+		report, err := uc.AddTargets(context.Background(), 1,
+			[]domain.Target{{Name: "Boris", Country: "GB", Deadline: &later}}, domain.ImportBestEffort)
+		require.NoError(t, err)
+		require.Len(t, report.Errors, 1)
+		assert.Equal(t, domain.ErrTargetDeadline.Error(), report.Errors[0].Error)
+	})
+
+	t.Run("mission deadline cannot move before a target's", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{
+			ID: 1, Deadline: &later, Targets: []domain.Target{{Name: "Boris", Deadline: &later}},
+		}, nil)
+		uc := usecase.NewMissionUsecase(mockRepo, nil)
+
+		err := uc.UpdateMission(context.Background(), &domain.Mission{ID: 1, Deadline: &soon})
+		assert.ErrorIs(t, err, domain.ErrTargetDeadline)
+		mockRepo.AssertNotCalled(t, "UpdateMission", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unchanged past deadline is kept", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1, Deadline: &past}, nil)
+		mockRepo.On("UpdateMission", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		uc := usecase.NewMissionUsecase(mockRepo, nil)
+
+		m := &domain.Mission{ID: 1, Deadline: &past}
+		require.NoError(t, uc.UpdateMission(context.Background(), m))
+		assert.True(t, m.Overdue)
+	})
+}
+
+func TestMissionUsecase_Overdue(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	mockRepo := new(MockRepository)
+	overdue := true
+	mockRepo.On("ListMissions", mock.Anything, mock.MatchedBy(func(f domain.MissionFilter) bool {
+		return f.Overdue != nil && *f.Overdue && !f.Now.IsZero()
+	})).Return([]*domain.Mission{
+		{ID: 1, Deadline: &past},
+		{ID: 2, Deadline: &future, Targets: []domain.Target{{ID: 5, Deadline: &past}}},
+		{ID: 3, Deadline: &past, Completed: true},
+		{ID: 4, Targets: []domain.Target{{ID: 6, Deadline: &past, Completed: true}}},
+	}, nil)
+	uc := usecase.NewMissionUsecase(mockRepo, nil)
+
+	missions, err := uc.ListMissions(context.Background(), domain.MissionFilter{Overdue: &overdue})
+	require.NoError(t, err)
+	assert.True(t, missions[0].Overdue)
+	assert.True(t, missions[1].Overdue)
+	assert.True(t, missions[1].Targets[0].Overdue)
+	assert.False(t, missions[2].Overdue)
+	assert.False(t, missions[3].Overdue)
+	assert.False(t, missions[3].Targets[0].Overdue)
+}
+
+func TestMissionUsecase_AutoAssign(t *testing.T) {
+	busyOn := int64(9)
+	candidates := func() []domain.Candidate {
+		return []domain.Candidate{
+			{ID: 1, Name: "Tom", Breed: "Siamese", YearsOfExperience: 8, Salary: money.MustParse("900"), ActiveMissionID: &busyOn},
+			{ID: 2, Name: "Kit", Breed: "Bengal", YearsOfExperience: 1, Salary: money.MustParse("500")},
+			{ID: 3, Name: "Max", Breed: "Bengal", YearsOfExperience: 4, Salary: money.MustParse("1500")},
+			{ID: 4, Name: "Leo", Breed: "Persian", YearsOfExperience: 6, Salary: money.MustParse("1200")},
+			{ID: 5, Name: "Ash", Breed: "Persian", YearsOfExperience: 3, Salary: money.MustParse("1200")},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mission domain.Mission
+		wantCat int64
+		wantErr error
+	}{
+		{name: "lowest salary", mission: domain.Mission{ID: 1, RequiredExperience: 3}, wantCat: 4},
+		{name: "preferred breed first", mission: domain.Mission{ID: 1, RequiredExperience: 3, PreferredBreeds: []string{"bengal"}}, wantCat: 3},
+		{name: "preferred breed unavailable", mission: domain.Mission{ID: 1, PreferredBreeds: []string{"Siamese"}}, wantCat: 2},
+		{name: "nobody experienced enough", mission: domain.Mission{ID: 1, RequiredExperience: 10}, wantErr: domain.ErrNoAvailableCat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			m := tt.mission
+			mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&m, nil)
+			mockRepo.On("ListCandidates", mock.Anything).Return(candidates(), nil)
+			if tt.wantErr == nil {
+				mockRepo.On("AssignCat", mock.Anything, int64(1), tt.wantCat).Return(nil)
+			}
+
+			a, err := usecase.NewMissionUsecase(mockRepo, nil).AutoAssign(context.Background(), 1)
+			require.NotNil(t, a)
+			assert.Equal(t, 5, a.Considered)
+			assert.Equal(t, 1, a.Busy)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, a.Cat)
+				assert.Equal(t, tt.wantErr.Error(), a.Error)
+				assert.Contains(t, a.Explanation, "No cat is available")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCat, a.Cat.ID)
+			assert.Contains(t, a.Explanation, a.Cat.Name)
+			assert.Equal(t, tt.wantCat, *m.CatID)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("explanation", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1, RequiredExperience: 3, PreferredBreeds: []string{"Bengal"}}, nil)
+		mockRepo.On("ListCandidates", mock.Anything).Return(candidates(), nil)
+		mockRepo.On("AssignCat", mock.Anything, int64(1), int64(3)).Return(nil)
+
+		a, err := usecase.NewMissionUsecase(mockRepo, nil).AutoAssign(context.Background(), 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Chose Max (#3), a Bengal with 4 years of experience (3 required), with the lowest salary (1500.00) "+
+			"of the 1 available cat of a preferred breed (Bengal). "+
+			"Of 5 cats, 1 was on an active mission and 1 had fewer than 3 years of experience.", a.Explanation)
+	})
+
+	t.Run("cat taken in the meantime", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1, RequiredExperience: 3}, nil)
+		mockRepo.On("ListCandidates", mock.Anything).Return(candidates(), nil)
+		mockRepo.On("AssignCat", mock.Anything, int64(1), int64(4)).Return(domain.ErrCatUnavailable)
+		mockRepo.On("AssignCat", mock.Anything, int64(1), int64(5)).Return(nil)
+
+		a, err := usecase.NewMissionUsecase(mockRepo, nil).AutoAssign(context.Background(), 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), a.Cat.ID)
+		assert.Equal(t, 2, a.Busy)
+	})
+
+	t.Run("already assigned", func(t *testing.T) {
+		catID := int64(2)
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1, CatID: &catID}, nil)
+
+		_, err := usecase.NewMissionUsecase(mockRepo, nil).AutoAssign(context.Background(), 1)
+		assert.ErrorIs(t, err, domain.ErrMissionAssigned)
+	})
+}
+
+func TestMissionUsecase_AutoAssignAll(t *testing.T) {
+	soon := time.Now().Add(time.Hour)
+	mockRepo := new(MockRepository)
+	mockRepo.On("ListMissions", mock.Anything, mock.MatchedBy(func(f domain.MissionFilter) bool {
+		return f.State == domain.StateDraft && f.Assigned != nil && !*f.Assigned
+	})).Return([]*domain.Mission{
+		{ID: 1},
+		{ID: 2, Priority: 5},
+		{ID: 3, Priority: 5, Deadline: &soon},
+	}, nil)
+	mockRepo.On("ListCandidates", mock.Anything).Return([]domain.Candidate{
+		{ID: 10, Name: "Tom", Salary: money.MustParse("100")},
+		{ID: 11, Name: "Kit", Salary: money.MustParse("200")},
+	}, nil)
+	mockRepo.On("AssignCat", mock.Anything, int64(3), int64(10)).Return(nil).Once()
+	mockRepo.On("AssignCat", mock.Anything, int64(2), int64(11)).Return(nil).Once()
+	pub := &recordingPublisher{}
+
+	report, err := usecase.NewMissionUsecase(mockRepo, pub).AutoAssignAll(context.Background(), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, report.Assigned)
+	assert.Equal(t, 1, report.Unassigned)
+	require.Len(t, report.Results, 3)
+	assert.Equal(t, []int64{3, 2, 1}, []int64{report.Results[0].MissionID, report.Results[1].MissionID, report.Results[2].MissionID})
+	assert.Equal(t, domain.ErrNoAvailableCat.Error(), report.Results[2].Error)
+	assert.Equal(t, []event.Type{event.MissionAssigned, event.MissionAssigned}, pub.types())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestMissionUsecase_AutoAssignAll_GivenMissions(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1}, nil)
+	mockRepo.On("GetMissionByID", mock.Anything, int64(2)).Return(nil, domain.ErrMissionNotFound)
+	mockRepo.On("GetMissionByID", mock.Anything, int64(3)).Return(&domain.Mission{ID: 3, Completed: true}, nil)
+	mockRepo.On("ListCandidates", mock.Anything).Return([]domain.Candidate{{ID: 10}}, nil)
+	mockRepo.On("AssignCat", mock.Anything, int64(1), int64(10)).Return(nil)
+
+	report, err := usecase.NewMissionUsecase(mockRepo, nil).AutoAssignAll(context.Background(), []int64{1, 2, 3, 1})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Assigned)
+	assert.Equal(t, 2, report.Unassigned)
+	require.Len(t, report.Results, 3)
+	assert.Equal(t, int64(10), report.Results[0].Cat.ID)
+	assert.Equal(t, domain.ErrMissionNotFound.Error(), report.Results[1].Error)
+	assert.Equal(t, domain.ErrMissionClosed.Error(), report.Results[2].Error)
+}
+
+func TestMissionUsecase_CreateMission_Validation(t *testing.T) {
+	mockRepo := new(MockRepository)
+	uc := usecase.NewMissionUsecase(mockRepo, nil)
+
+	assert.ErrorIs(t, uc.CreateMission(context.Background(), &domain.Mission{Priority: 11}), domain.ErrInvalidPriority)
+	assert.ErrorIs(t, uc.CreateMission(context.Background(), &domain.Mission{RequiredExperience: -1}), domain.ErrInvalidRequired)
+
+	mockRepo.On("CreateMission", mock.Anything, mock.Anything).Return(nil)
+	m := &domain.Mission{PreferredBreeds: []string{" Bengal ", "", "bengal", "Siamese"}}
+	require.NoError(t, uc.CreateMission(context.Background(), m))
+	assert.Equal(t, []string{"Bengal", "Siamese"}, m.PreferredBreeds)
+}
+
+func TestMissionUsecase_TransitionMission(t *testing.T) {
+	catID := int64(7)
+	open := []domain.Target{{ID: 1, Completed: true}, {ID: 2}}
+	done := []domain.Target{{ID: 1, Completed: true}, {ID: 2, Completed: true}}
+
+	tests := []struct {
+		name       string
+		mission    *domain.Mission
+		transition domain.Transition
+		updateErr  error
+		wantErr    error
+		wantState  domain.State
+		wantEvent  event.Type
+	}{
+		{
+			name:       "start",
+			mission:    &domain.Mission{ID: 1, CatID: &catID, State: domain.StateAssigned, Targets: open},
+			transition: domain.TransitionStart,
+			wantState:  domain.StateInProgress,
+			wantEvent:  event.MissionStarted,
+		},
+		{
+			name:       "start without targets",
+			mission:    &domain.Mission{ID: 1, CatID: &catID, State: domain.StateAssigned},
+			transition: domain.TransitionStart,
+			wantErr:    domain.ErrNoTargets,
+		},
+		{
+			name:       "start a draft",
+			mission:    &domain.Mission{ID: 1, State: domain.StateDraft, Targets: open},
+			transition: domain.TransitionStart,
+			wantErr:    domain.ErrInvalidTransition,
+		},
+		{
+			name:       "complete",
+			mission:    &domain.Mission{ID: 1, CatID: &catID, State: domain.StateInProgress, Targets: done},
+			transition: domain.TransitionComplete,
+			wantState:  domain.StateCompleted,
+			wantEvent:  event.MissionCompleted,
+		},
+		{
+			name:       "complete with open targets",
+			mission:    &domain.Mission{ID: 1, CatID: &catID, State: domain.StateInProgress, Targets: open},
+			transition: domain.TransitionComplete,
+			wantErr:    domain.ErrTargetsOpen,
+		},
+		{
+			name:       "fail",
+			mission:    &domain.Mission{ID: 1, CatID: &catID, State: domain.StateInProgress, Targets: open},
+			transition: domain.TransitionFail,
+			wantState:  domain.StateFailed,
+			wantEvent:  event.MissionFailed,
+		},
+		{
+			name:       "abort a draft",
+			mission:    &domain.Mission{ID: 1},
+			transition: domain.TransitionAbort,
+			wantState:  domain.StateAborted,
+			wantEvent:  event.MissionAborted,
+		},
+		{
+			name:       "abort a completed mission",
+			mission:    &domain.Mission{ID: 1, CatID: &catID, Completed: true},
+			transition: domain.TransitionAbort,
+			wantErr:    domain.ErrInvalidTransition,
+		},
+		{
+			name:       "assign is not requested directly",
+			mission:    &domain.Mission{ID: 1},
+			transition: domain.TransitionAssign,
+			wantErr:    domain.ErrInvalidTransition,
+		},
+		{
+			name:       "changed concurrently",
+			mission:    &domain.Mission{ID: 1, CatID: &catID, State: domain.StateAssigned, Targets: open},
+			transition: domain.TransitionStart,
+			updateErr:  domain.ErrInvalidTransition,
+			wantErr:    domain.ErrInvalidTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			from := tt.mission.CurrentState()
+			mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(tt.mission, nil).Maybe()
+			mockRepo.On("UpdateState", mock.Anything, mock.AnythingOfType("*domain.Mission"), from).Return(tt.updateErr).Maybe()
+			pub := &recordingPublisher{}
+
+			uc := usecase.NewMissionUsecase(mockRepo, pub)
+			m, err := uc.TransitionMission(context.Background(), 1, tt.transition)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, pub.types())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantState, m.State)
+			assert.Equal(t, tt.wantState == domain.StateCompleted, m.Completed)
+			assert.Equal(t, []event.Type{tt.wantEvent}, pub.types())
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMissionUsecase_UpdateMission_State(t *testing.T) {
+	catID, otherCat := int64(7), int64(8)
+
+	t.Run("closed mission", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetMissionByID", mock.Anything, int64(1)).
+			Return(&domain.Mission{ID: 1, State: domain.StateAborted}, nil)
+
+		uc := usecase.NewMissionUsecase(mockRepo, nil)
+		err := uc.UpdateMission(context.Background(), &domain.Mission{ID: 1})
+		assert.ErrorIs(t, err, domain.ErrMissionClosed)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("reassign in progress", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetMissionByID", mock.Anything, int64(1)).
+			Return(&domain.Mission{ID: 1, CatID: &catID, State: domain.StateInProgress}, nil)
+
+		uc := usecase.NewMissionUsecase(mockRepo, nil)
+		err := uc.UpdateMission(context.Background(), &domain.Mission{ID: 1, CatID: &otherCat})
+		assert.ErrorIs(t, err, domain.ErrInvalidTransition)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unassign", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetMissionByID", mock.Anything, int64(1)).
+			Return(&domain.Mission{ID: 1, CatID: &catID, State: domain.StateAssigned}, nil)
+		mockRepo.On("UpdateMission", mock.Anything, mock.MatchedBy(func(m *domain.Mission) bool {
+			return m.State == domain.StateDraft && m.AssignedAt == nil
+		}), domain.StateAssigned).Return(nil)
+
+		uc := usecase.NewMissionUsecase(mockRepo, nil)
+		assert.NoError(t, uc.UpdateMission(context.Background(), &domain.Mission{ID: 1}))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("completed completes a mission in progress", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{
+			ID: 1, CatID: &catID, State: domain.StateInProgress,
+			Targets: []domain.Target{{Status: domain.TargetEscaped, Completed: true}},
+		}, nil)
+		mockRepo.On("UpdateMission", mock.Anything, mock.MatchedBy(func(m *domain.Mission) bool {
+			return m.State == domain.StateCompleted && m.Completed && m.CompletedAt != nil
+		}), domain.StateInProgress).Return(nil)
+
+		uc := usecase.NewMissionUsecase(mockRepo, nil)
+		assert.NoError(t, uc.UpdateMission(context.Background(), &domain.Mission{ID: 1, CatID: &catID, Completed: true}))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("completed keeps the guards of complete", func(t *testing.T) {
+		for name, existing := range map[string]*domain.Mission{
+			"draft": {ID: 1},
+			"open targets": {ID: 1, CatID: &catID, State: domain.StateInProgress,
+				Targets: []domain.Target{{Status: domain.TargetPending}}},
+		} {
+			mockRepo := new(MockRepository)
+			mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(existing, nil)
+
+			uc := usecase.NewMissionUsecase(mockRepo, nil)
+			err := uc.UpdateMission(context.Background(), &domain.Mission{ID: 1, CatID: existing.CatID, Completed: true})
+			assert.Error(t, err, name)
+			mockRepo.AssertNotCalled(t, "UpdateMission", mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("concurrent transition", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetMissionByID", mock.Anything, int64(1)).
+			Return(&domain.Mission{ID: 1, CatID: &catID, State: domain.StateAssigned}, nil)
+		mockRepo.On("UpdateMission", mock.Anything, mock.AnythingOfType("*domain.Mission"), domain.StateAssigned).
+			Return(domain.ErrInvalidTransition)
+
+		uc := usecase.NewMissionUsecase(mockRepo, nil)
+		err := uc.UpdateMission(context.Background(), &domain.Mission{ID: 1, CatID: &catID, Priority: 3})
+		assert.ErrorIs(t, err, domain.ErrInvalidTransition)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestMissionUsecase_TargetStatus(t *testing.T) {
+	status := func(s domain.TargetStatus) *domain.TargetStatus { return &s }
+	text := func(s string) *string { return &s }
+	yes, no := true, false
+
+	tests := []struct {
+		name        string
+		target      domain.Target
+		update      domain.TargetUpdate
+		wantErr     error
+		wantStatus  domain.TargetStatus
+		wantOutcome string
+		wantDone    bool
+	}{
+		{
+			name:        "compromised",
+			target:      domain.Target{ID: 3, MissionID: 1},
+			update:      domain.TargetUpdate{Status: status(domain.TargetCompromised), Outcome: text(" spotted the cat ")},
+			wantStatus:  domain.TargetCompromised,
+			wantOutcome: "spotted the cat",
+		},
+		{
+			name:        "escaped after being compromised",
+			target:      domain.Target{ID: 3, MissionID: 1, Status: domain.TargetCompromised},
+			update:      domain.TargetUpdate{Status: status(domain.TargetEscaped), Outcome: text("crossed the border")},
+			wantStatus:  domain.TargetEscaped,
+			wantOutcome: "crossed the border",
+			wantDone:    true,
+		},
+		{
+			name:       "completed eliminates",
+			target:     domain.Target{ID: 3, MissionID: 1, Status: domain.TargetCompromised},
+			update:     domain.TargetUpdate{Completed: &yes},
+			wantStatus: domain.TargetEliminated,
+			wantDone:   true,
+		},
+		{
+			name:        "completing an escaped target changes nothing",
+			target:      domain.Target{ID: 3, MissionID: 1, Status: domain.TargetEscaped, Outcome: "gone", Completed: true},
+			update:      domain.TargetUpdate{Completed: &yes},
+			wantStatus:  domain.TargetEscaped,
+			wantOutcome: "gone",
+			wantDone:    true,
+		},
+		{
+			name:    "terminal status is final",
+			target:  domain.Target{ID: 3, MissionID: 1, Status: domain.TargetEliminated, Completed: true},
+			update:  domain.TargetUpdate{Status: status(domain.TargetEscaped)},
+			wantErr: domain.ErrTargetFinal,
+		},
+		{
+			name:    "back to pending",
+			target:  domain.Target{ID: 3, MissionID: 1, Status: domain.TargetCompromised},
+			update:  domain.TargetUpdate{Status: status(domain.TargetPending)},
+			wantErr: domain.ErrInvalidTargetStatus,
+		},
+		{
+			name:    "unknown status",
+			target:  domain.Target{ID: 3, MissionID: 1},
+			update:  domain.TargetUpdate{Status: status("captured")},
+			wantErr: domain.ErrInvalidTargetStatus,
+		},
+		{
+			name:    "completed contradicts status",
+			target:  domain.Target{ID: 3, MissionID: 1},
+			update:  domain.TargetUpdate{Status: status(domain.TargetCompromised), Completed: &yes},
+			wantErr: domain.ErrInvalidTargetStatus,
+		},
+		{
+			name:    "outcome without status",
+			target:  domain.Target{ID: 3, MissionID: 1},
+			update:  domain.TargetUpdate{Outcome: text("lost track")},
+			wantErr: domain.ErrOutcomeStatus,
+		},
+		{
+			name:    "escaped cannot be uncompleted",
+			target:  domain.Target{ID: 3, MissionID: 1, Status: domain.TargetEscaped, Completed: true},
+			update:  domain.TargetUpdate{Completed: &no},
+			wantErr: domain.ErrTargetUncomplete,
+		},
+		{
+			name:    "escaped locks notes",
+			target:  domain.Target{ID: 3, MissionID: 1, Status: domain.TargetEscaped, Completed: true},
+			update:  domain.TargetUpdate{Notes: text("one more thing")},
+			wantErr: domain.ErrNotesLocked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			target := tt.target
+			mockRepo.On("GetTargetByID", mock.Anything, int64(3)).Return(&target, nil)
+			mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1}, nil)
+			mockRepo.On("UpdateTarget", mock.Anything, mock.AnythingOfType("*domain.Target")).Return(nil).Maybe()
+
+			uc := usecase.NewMissionUsecase(mockRepo, nil)
+			got, err := uc.UpdateTarget(context.Background(), 3, tt.update)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockRepo.AssertNotCalled(t, "UpdateTarget", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, got.Status)
+			assert.Equal(t, tt.wantOutcome, got.Outcome)
+			assert.Equal(t, tt.wantDone, got.Completed)
+			if tt.target.CurrentStatus() == tt.wantStatus {
+				return
+			}
+			switch tt.wantStatus {
+			case domain.TargetCompromised:
+				assert.NotNil(t, got.CompromisedAt)
+			case domain.TargetEliminated:
+				assert.NotNil(t, got.EliminatedAt)
+			case domain.TargetEscaped:
+				assert.NotNil(t, got.EscapedAt)
+			}
+		})
+	}
+}
+
+func TestMissionUsecase_MissionProgress(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{ID: 1, Targets: []domain.Target{
+		{ID: 1, Status: domain.TargetEliminated, Completed: true},
+		{ID: 2, Status: domain.TargetEscaped, Completed: true},
+		{ID: 3, Status: domain.TargetCompromised},
+		{ID: 4, Completed: true},
+		{ID: 5},
+		{ID: 6, Status: domain.TargetPending},
+	}}, nil)
+
+	uc := usecase.NewMissionUsecase(mockRepo, nil)
+	m, err := uc.GetMissionByID(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Progress{Targets: 6, Terminal: 3, Eliminated: 2, Escaped: 1, Compromised: 1, Percent: 50}, m.Progress)
+}
+
+func TestMissionUsecase_CompleteMission_EscapedTargets(t *testing.T) {
+	catID := int64(7)
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&domain.Mission{
+		ID: 1, CatID: &catID, State: domain.StateInProgress, Targets: []domain.Target{
+			{ID: 1, Status: domain.TargetEliminated, Completed: true},
+			{ID: 2, Status: domain.TargetEscaped, Completed: true},
+		},
+	}, nil)
+	mockRepo.On("UpdateState", mock.Anything, mock.AnythingOfType("*domain.Mission"), domain.StateInProgress).Return(nil)
+
+	uc := usecase.NewMissionUsecase(mockRepo, nil)
+	m, err := uc.TransitionMission(context.Background(), 1, domain.TransitionComplete)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StateCompleted, m.State)
+	assert.Equal(t, 100, m.Progress.Percent)
+}
+
+func TestMissionUsecase_AddTargetNote(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  domain.Target
+		mission domain.Mission
+		note    domain.TargetNote
+		wantErr error
+	}{
+		{
+			name:    "success",
+			target:  domain.Target{ID: 3, MissionID: 1, Status: domain.TargetCompromised},
+			mission: domain.Mission{ID: 1, State: domain.StateInProgress},
+			note:    domain.TargetNote{Author: " Whiskers ", Body: " left by the back door "},
+		},
+		{
+			name:    "missing author",
+			note:    domain.TargetNote{Body: "left by the back door"},
+			wantErr: domain.ErrNoteAuthor,
+		},
+		{
+			name:    "empty body",
+			note:    domain.TargetNote{Author: "Whiskers", Body: "  "},
+			wantErr: domain.ErrNoteEmpty,
+		},
+		{
+			name:    "too long",
+			note:    domain.TargetNote{Author: "Whiskers", Body: strings.Repeat("x", domain.MaxNoteLength+1)},
+			wantErr: domain.ErrNoteTooLong,
+		},
+		{
+			name:    "terminal target",
+			target:  domain.Target{ID: 3, MissionID: 1, Status: domain.TargetEscaped, Completed: true},
+			mission: domain.Mission{ID: 1, State: domain.StateInProgress},
+			note:    domain.TargetNote{Author: "Whiskers", Body: "gone"},
+			wantErr: domain.ErrNotesLocked,
+		},
+		{
+			name:    "closed mission",
+			target:  domain.Target{ID: 3, MissionID: 1},
+			mission: domain.Mission{ID: 1, State: domain.StateAborted},
+			note:    domain.TargetNote{Author: "Whiskers", Body: "gone"},
+			wantErr: domain.ErrNotesLocked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+			mockRepo := new(MockRepository)
+			if tt.target.ID != 0 {
+				mockRepo.On("GetTargetByID", mock.Anything, int64(3)).Return(&tt.target, nil)
+				mockRepo.On("GetMissionByID", mock.Anything, int64(1)).Return(&tt.mission, nil)
+			}
+			if tt.wantErr == nil {
+				mockRepo.On("AddTargetNote", mock.Anything, mock.AnythingOfType("*domain.TargetNote")).
+					Run(func(args mock.Arguments) {
+						note := args.Get(1).(*domain.TargetNote)
+						note.ID, note.CreatedAt = 10, created
+					}).Return(nil)
+			}
+			pub := &recordingPublisher{}
+
+			uc := usecase.NewMissionUsecase(mockRepo, pub)
+			note, err := uc.AddTargetNote(context.Background(), 3, tt.note)
+
+			mockRepo.AssertExpectations(t)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, pub.events)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, &domain.TargetNote{
+				ID: 10, TargetID: 3, Author: "Whiskers", Body: "left by the back door", CreatedAt: created,
+			}, note)
+			require.Len(t, pub.events, 1)
+			var data map[string]any
+			require.NoError(t, json.Unmarshal(pub.events[0].Data, &data))
+			assert.Equal(t, "left by the back door", data["notes"])
+		})
+	}
+}
+
+func TestMissionUsecase_ListTargetNotes(t *testing.T) {
+	notes := []domain.TargetNote{{ID: 1, TargetID: 3, Body: "first"}, {ID: 2, TargetID: 3, Author: "Whiskers", Body: "second"}}
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetTargetByID", mock.Anything, int64(3)).Return(&domain.Target{ID: 3}, nil)
+	mockRepo.On("GetTargetByID", mock.Anything, int64(4)).Return(nil, domain.ErrTargetNotFound)
+	mockRepo.On("ListTargetNotes", mock.Anything, int64(3)).Return(notes, nil)
+
+	uc := usecase.NewMissionUsecase(mockRepo, nil)
+	got, err := uc.ListTargetNotes(context.Background(), 3)
+	require.NoError(t, err)
+	assert.Equal(t, notes, got)
+
+	_, err = uc.ListTargetNotes(context.Background(), 4)
+	assert.ErrorIs(t, err, domain.ErrTargetNotFound)
+	mockRepo.AssertExpectations(t)
+}
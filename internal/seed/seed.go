@@ -0,0 +1,136 @@
+// Package seed generates sample cats, missions and targets for local
+// development and integration tests. The same Config always produces the
+// same data.
+package seed
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"go-test-assesment/internal/cat"
+	catDomain "go-test-assesment/internal/cat/domain"
+	importer "go-test-assesment/internal/importer/domain"
+	mission "go-test-assesment/internal/mission/domain"
+	"go-test-assesment/pkg/money"
+)
+
+const (
+	DefaultCats     = 20
+	DefaultMissions = 30
+	maxTargets      = 3
+)
+
+type Config struct {
+	// Seed selects the data set; runs with the same seed and counts
+	// generate the same data.
+	Seed     uint64
+	Cats     int
+	Missions int
+}
+
+// Dataset is generated data not yet stored. Missions refer to their cat by
+// index into Cats, as the cats have no IDs before they are stored.
+type Dataset struct {
+	Cats     []*catDomain.Cat
+	Missions []Mission
+}
+
+type Mission struct {
+	*mission.Mission
+	// Cat is the index of the assigned cat in Dataset.Cats, or -1.
+	Cat int
+}
+
+// Generate builds a data set. Breeds come from the embedded breed list and
+// countries are ISO codes, so everything passes validation without network
+// access. About a fifth of the missions are unassigned and, of the
+// assigned ones, a third are completed along with all their targets, as are
+// those whose cat is already on an open mission.
+func Generate(cfg Config) *Dataset {
+	rng := rand.New(rand.NewPCG(cfg.Seed, cfg.Seed^0x5eed))
+	ds := &Dataset{}
+	breeds := cat.Breeds()
+
+	for range cfg.Cats {
+		years := rng.IntN(16)
+		// Pay grows with experience, in steps of 50.
+		salary := 1200 + years*250 + rng.IntN(20)*50
+		ds.Cats = append(ds.Cats, &catDomain.Cat{
+			Name:              catName(rng),
+			YearsOfExperience: years,
+			Breed:             pick(rng, breeds),
+			Salary:            money.FromCents(int64(salary) * 100),
+		})
+	}
+
+	busy := make([]bool, len(ds.Cats))
+	for range cfg.Missions {
+		m := Mission{Mission: &mission.Mission{}, Cat: -1}
+		if len(ds.Cats) > 0 && rng.IntN(5) > 0 {
+			m.Cat = rng.IntN(len(ds.Cats))
+			m.Completed = rng.IntN(3) == 0 || busy[m.Cat]
+			busy[m.Cat] = busy[m.Cat] || !m.Completed
+		}
+
+		seen := map[string]bool{}
+		for n := 1 + rng.IntN(maxTargets); len(m.Targets) < n; {
+			name := targetName(rng)
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			place := pick(rng, places)
+			m.Targets = append(m.Targets, mission.Target{
+				Name:      name,
+				Country:   place.country,
+				Notes:     note(rng, place),
+				Completed: m.Completed || (m.Cat >= 0 && rng.IntN(3) == 0),
+			})
+		}
+		ds.Missions = append(ds.Missions, m)
+	}
+	return ds
+}
+
+// Load stores ds through an import repository in batches of batchSize,
+// cats first so that missions can be assigned to them.
+func Load(ctx context.Context, repo importer.Repository, ds *Dataset, batchSize int) error {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	for start := 0; start < len(ds.Cats); start += batchSize {
+		if err := repo.StoreCats(ctx, ds.Cats[start:min(start+batchSize, len(ds.Cats))]); err != nil {
+			return fmt.Errorf("storing cats: %w", err)
+		}
+	}
+
+	now := time.Now()
+	missions := make([]*mission.Mission, len(ds.Missions))
+	for i, m := range ds.Missions {
+		if m.Cat >= 0 {
+			id := ds.Cats[m.Cat].ID
+			m.CatID = &id
+		}
+		if err := m.InitState(now); err != nil {
+			return fmt.Errorf("mission %d: %w", i, err)
+		}
+		for j := range m.Targets {
+			if err := m.Targets[j].InitStatus(now); err != nil {
+				return fmt.Errorf("mission %d: target %q: %w", i, m.Targets[j].Name, err)
+			}
+		}
+		missions[i] = m.Mission
+	}
+	for start := 0; start < len(missions); start += batchSize {
+		if err := repo.StoreMissions(ctx, missions[start:min(start+batchSize, len(missions))]); err != nil {
+			return fmt.Errorf("storing missions: %w", err)
+		}
+	}
+	return nil
+}
+
+func pick[T any](rng *rand.Rand, from []T) T {
+	return from[rng.IntN(len(from))]
+}
@@ -0,0 +1,94 @@
+package seed
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+var (
+	catNames = []string{
+		"Shadow", "Whiskers", "Mittens", "Smokey", "Tiger", "Luna", "Oliver", "Bella",
+		"Simba", "Nala", "Felix", "Cleo", "Jasper", "Misty", "Ginger", "Pepper",
+		"Oscar", "Willow", "Salem", "Hazel", "Milo", "Saffron", "Boris", "Natasha",
+	}
+	callsigns = []string{"Velvet", "Ghost", "Ember", "Frost", "Echo", "Onyx", "Cinder", "Nightfall"}
+
+	adjectives = []string{
+		"Silent", "Crimson", "Grey", "Velvet", "Iron", "Hollow", "Golden", "Pale",
+		"Broken", "Midnight", "Quiet", "Scarlet", "Copper", "Winter", "Last", "Blind",
+	}
+	nouns = []string{
+		"Baron", "Heron", "Courier", "Jeweller", "Architect", "Countess", "Chemist", "Falcon",
+		"Banker", "Cartographer", "Widow", "Admiral", "Pianist", "Broker", "Sparrow", "Doctor",
+	}
+
+	// places are where targets are found: countries with a busy
+	// intelligence history and cities in them.
+	places = []place{
+		{"AT", []string{"Vienna", "Salzburg"}},
+		{"CH", []string{"Geneva", "Zurich", "Bern"}},
+		{"DE", []string{"Berlin", "Munich", "Hamburg"}},
+		{"FR", []string{"Paris", "Marseille", "Nice"}},
+		{"GB", []string{"London", "Edinburgh", "Manchester"}},
+		{"IT", []string{"Rome", "Milan", "Venice"}},
+		{"ES", []string{"Madrid", "Barcelona"}},
+		{"PT", []string{"Lisbon", "Porto"}},
+		{"NL", []string{"Amsterdam", "Rotterdam"}},
+		{"CZ", []string{"Prague"}},
+		{"HU", []string{"Budapest"}},
+		{"PL", []string{"Warsaw", "Kraków"}},
+		{"TR", []string{"Istanbul", "Ankara"}},
+		{"EG", []string{"Cairo", "Alexandria"}},
+		{"MA", []string{"Tangier", "Casablanca"}},
+		{"AE", []string{"Dubai", "Abu Dhabi"}},
+		{"JP", []string{"Tokyo", "Osaka"}},
+		{"HK", []string{"Hong Kong"}},
+		{"SG", []string{"Singapore"}},
+		{"TH", []string{"Bangkok"}},
+		{"US", []string{"Washington", "New York", "Miami"}},
+		{"CA", []string{"Ottawa", "Montreal"}},
+		{"MX", []string{"Mexico City"}},
+		{"AR", []string{"Buenos Aires"}},
+		{"BR", []string{"Rio de Janeiro", "São Paulo"}},
+		{"ZA", []string{"Cape Town", "Johannesburg"}},
+		{"AU", []string{"Sydney", "Canberra"}},
+	}
+
+	weekdays  = []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+	haunts    = []string{"the opera house", "the central station", "a harbour café", "the casino", "the old market", "an embassy reception", "a bookshop near the university", "the racecourse"}
+	habits    = []string{"changes hotels every third night", "never uses the same taxi twice", "keeps a ledger in a hollow book", "carries two passports", "feeds the pigeons at noon", "only drinks tea with lemon", "wears gloves indoors", "pays for everything in cash"}
+	templates = []func(rng *rand.Rand, city string) string{
+		func(rng *rand.Rand, city string) string {
+			return fmt.Sprintf("Seen at %s in %s on %s evenings.", pick(rng, haunts), city, pick(rng, weekdays))
+		},
+		func(rng *rand.Rand, city string) string {
+			return fmt.Sprintf("Based in %s; %s.", city, pick(rng, habits))
+		},
+		func(rng *rand.Rand, city string) string {
+			return fmt.Sprintf("Meets a contact near %s in %s every %s. Known to be armed.", pick(rng, haunts), city, pick(rng, weekdays))
+		},
+		func(rng *rand.Rand, city string) string {
+			return fmt.Sprintf("Last reported in %s. Informant says the target %s.", city, pick(rng, habits))
+		},
+	}
+)
+
+type place struct {
+	country string
+	cities  []string
+}
+
+func catName(rng *rand.Rand) string {
+	if rng.IntN(4) == 0 {
+		return fmt.Sprintf("%s \"%s\"", pick(rng, catNames), pick(rng, callsigns))
+	}
+	return pick(rng, catNames)
+}
+
+func targetName(rng *rand.Rand) string {
+	return "The " + pick(rng, adjectives) + " " + pick(rng, nouns)
+}
+
+func note(rng *rand.Rand, p place) string {
+	return pick(rng, templates)(rng, pick(rng, p.cities))
+}
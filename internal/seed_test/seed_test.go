@@ -0,0 +1,132 @@
+package seed_test
+
+import (
+	"context"
+	"testing"
+
+	"go-test-assesment/internal/cat"
+	catDomain "go-test-assesment/internal/cat/domain"
+	mission "go-test-assesment/internal/mission/domain"
+	"go-test-assesment/internal/seed"
+	"go-test-assesment/pkg/country"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate_Deterministic(t *testing.T) {
+	cfg := seed.Config{Seed: 42, Cats: 10, Missions: 15}
+
+	a, b := seed.Generate(cfg), seed.Generate(cfg)
+	assert.Equal(t, a, b)
+
+	cfg.Seed = 43
+	assert.NotEqual(t, a, seed.Generate(cfg))
+}
+
+func TestGenerate_Valid(t *testing.T) {
+	ds := seed.Generate(seed.Config{Seed: 7, Cats: 50, Missions: 200})
+	require.Len(t, ds.Cats, 50)
+	require.Len(t, ds.Missions, 200)
+
+	breeds := cat.NewStaticValidator()
+	for _, c := range ds.Cats {
+		assert.NotEmpty(t, c.Name)
+		assert.GreaterOrEqual(t, c.YearsOfExperience, 0)
+		assert.Positive(t, c.Salary)
+		valid, err := breeds.ValidateBreed(context.Background(), c.Breed)
+		require.NoError(t, err)
+		assert.True(t, valid, c.Breed)
+	}
+
+	var unassigned, completed int
+	open := map[int]bool{}
+	for _, m := range ds.Missions {
+		if m.Cat >= 0 && !m.Completed {
+			assert.False(t, open[m.Cat], "cat %d has two open missions", m.Cat)
+			open[m.Cat] = true
+		}
+		require.NotEmpty(t, m.Targets)
+		assert.LessOrEqual(t, len(m.Targets), 3)
+		if m.Cat < 0 {
+			unassigned++
+			assert.False(t, m.Completed)
+		}
+		names := map[string]bool{}
+		for _, target := range m.Targets {
+			assert.False(t, names[target.Name], "duplicate target %q", target.Name)
+			names[target.Name] = true
+			_, ok := country.Lookup(target.Country)
+			assert.True(t, ok, target.Country)
+			assert.NotEmpty(t, target.Notes)
+			if m.Completed {
+				assert.True(t, target.Completed)
+			}
+			if m.Cat < 0 {
+				assert.False(t, target.Completed)
+			}
+		}
+		if m.Completed {
+			completed++
+		}
+	}
+	assert.Positive(t, unassigned)
+	assert.Positive(t, completed)
+	assert.NotEmpty(t, open)
+}
+
+func TestGenerate_NoCats(t *testing.T) {
+	ds := seed.Generate(seed.Config{Missions: 5})
+	for _, m := range ds.Missions {
+		assert.Equal(t, -1, m.Cat)
+	}
+}
+
+type fakeRepo struct {
+	catBatches     int
+	missionBatches int
+	missions       []*mission.Mission
+	nextID         int64
+}
+
+func (r *fakeRepo) StoreCats(_ context.Context, cats []*catDomain.Cat) error {
+	r.catBatches++
+	for _, c := range cats {
+		r.nextID++
+		c.ID = r.nextID * 10
+	}
+	return nil
+}
+
+func (r *fakeRepo) StoreMissions(_ context.Context, missions []*mission.Mission) error {
+	r.missionBatches++
+	r.missions = append(r.missions, missions...)
+	return nil
+}
+
+func (r *fakeRepo) ExistingCats(context.Context, []int64) (map[int64]bool, error) {
+	return nil, nil
+}
+
+func (r *fakeRepo) BusyCats(context.Context, []int64) (map[int64]bool, error) {
+	return nil, nil
+}
+
+func TestLoad(t *testing.T) {
+	ds := seed.Generate(seed.Config{Seed: 3, Cats: 5, Missions: 12})
+	repo := &fakeRepo{}
+
+	require.NoError(t, seed.Load(context.Background(), repo, ds, 5))
+
+	assert.Equal(t, 1, repo.catBatches)
+	assert.Equal(t, 3, repo.missionBatches)
+	require.Len(t, repo.missions, 12)
+	for i, m := range ds.Missions {
+		if m.Cat < 0 {
+			assert.Nil(t, repo.missions[i].CatID)
+			continue
+		}
+		require.NotNil(t, repo.missions[i].CatID)
+		assert.Equal(t, ds.Cats[m.Cat].ID, *repo.missions[i].CatID)
+	}
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-test-assesment/internal/stats/domain"
+	"go-test-assesment/pkg/country"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	usecase domain.Usecase
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func NewHandler(u domain.Usecase) *Handler {
+	return &Handler{usecase: u}
+}
+
+func (h *Handler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/stats", h.dashboard)
+}
+
+// dashboard godoc
+// @Summary Agency dashboard
+// @Description Aggregated figures across the agency: missions by status (unassigned, in_progress, completed, aborted, failed), unassigned missions, completion rate, average time to complete (from creation to completion of completed missions), targets per country, the top cats by completed missions and salary totals. All figures come from one consistent snapshot.
+// @Tags Stats
+// @Produce json
+// @Param top_cats query int false "Number of top cats (1-50)" default(5)
+// @Param lang query string false "Language of country names, e.g. fr; defaults to Accept-Language, then English"
+// @Success 200 {object} domain.Dashboard
+// @Failure 400 {object} ErrorResponse "Invalid top_cats"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /stats [get]
+func (h *Handler) dashboard(c *gin.Context) {
+	topCats := 0
+	if v, ok := c.GetQuery("top_cats"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid top_cats"})
+			c.Error(err)
+			return
+		}
+		topCats = n
+	}
+
+	d, err := h.usecase.Dashboard(c.Request.Context(), topCats)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	lang := country.Language(c.Query("lang"), c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", lang.String())
+	c.Header("Vary", "Accept-Language")
+	for i := range d.TargetsByCountry {
+		d.TargetsByCountry[i].CountryName = country.Name(d.TargetsByCountry[i].Country, lang)
+	}
+	c.JSON(http.StatusOK, d)
+}
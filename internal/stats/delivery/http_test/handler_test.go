@@ -0,0 +1,84 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	handler "go-test-assesment/internal/stats/delivery/http"
+	"go-test-assesment/internal/stats/domain"
+	"go-test-assesment/pkg/money"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUsecase struct {
+	dashboard *domain.Dashboard
+	err       error
+	topCats   int
+}
+
+func (u *fakeUsecase) Dashboard(_ context.Context, topCats int) (*domain.Dashboard, error) {
+	u.topCats = topCats
+	return u.dashboard, u.err
+}
+
+func newRouter(uc domain.Usecase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handler.NewHandler(uc).RegisterRoutes(r)
+	return r
+}
+
+func get(r http.Handler, path, acceptLanguage string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if acceptLanguage != "" {
+		req.Header.Set("Accept-Language", acceptLanguage)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestDashboard(t *testing.T) {
+	uc := &fakeUsecase{dashboard: &domain.Dashboard{
+		Missions: domain.MissionStats{
+			Total:          2,
+			ByStatus:       map[string]int{domain.StatusCompleted: 1, domain.StatusUnassigned: 1},
+			Unassigned:     1,
+			CompletionRate: 0.5,
+		},
+		TargetsByCountry: []domain.CountryCount{{Country: "DE", Targets: 3, Completed: 1}},
+		TopCats:          []domain.CatRanking{{CatID: 4, Name: "Tom", CompletedMissions: 1}},
+		Salaries:         domain.SalaryStats{Headcount: 1, Total: money.FromCents(120050)},
+	}}
+
+	w := get(newRouter(uc), "/stats?top_cats=3", "fr")
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, 3, uc.topCats)
+	assert.Equal(t, "fr", w.Header().Get("Content-Language"))
+
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	missions := resp["missions"].(map[string]any)
+	assert.Equal(t, 0.5, missions["completion_rate"])
+	assert.Nil(t, missions["avg_time_to_complete_seconds"])
+	country := resp["targets_by_country"].([]any)[0].(map[string]any)
+	assert.Equal(t, "DE", country["country"])
+	assert.Equal(t, "Allemagne", country["country_name"])
+	assert.Equal(t, 1200.5, resp["salaries"].(map[string]any)["total"])
+}
+
+func TestDashboard_Errors(t *testing.T) {
+	w := get(newRouter(&fakeUsecase{}), "/stats?top_cats=many", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = get(newRouter(&fakeUsecase{err: errors.New("db error")}), "/stats", "")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go-test-assesment/pkg/money"
+)
+
+// Mission statuses as counted on the dashboard. Unassigned are draft
+// missions and in progress are those assigned to a cat, started or not.
+const (
+	StatusUnassigned = "unassigned"
+	StatusInProgress = "in_progress"
+	StatusCompleted  = "completed"
+	StatusAborted    = "aborted"
+	StatusFailed     = "failed"
+)
+
+// Dashboard is an agency-wide summary taken from one database snapshot.
+type Dashboard struct {
+	Missions         MissionStats   `json:"missions"`
+	TargetsByCountry []CountryCount `json:"targets_by_country"`
+	TopCats          []CatRanking   `json:"top_cats"`
+	Salaries         SalaryStats    `json:"salaries"`
+	GeneratedAt      time.Time      `json:"generated_at"`
+}
+
+type MissionStats struct {
+	Total      int            `json:"total"`
+	ByStatus   map[string]int `json:"by_status"`
+	Unassigned int            `json:"unassigned"`
+	// CompletionRate is the share of all missions that are completed, 0..1.
+	CompletionRate float64 `json:"completion_rate"`
+	// AvgTimeToComplete is the mean time from creation to completion of
+	// completed missions, in seconds; nil until a mission is completed.
+	AvgTimeToComplete *float64 `json:"avg_time_to_complete_seconds"`
+}
+
+type CountryCount struct {
+	Country     string `json:"country"`
+	CountryName string `json:"country_name,omitempty"`
+	Targets     int    `json:"targets"`
+	Completed   int    `json:"completed"`
+}
+
+type CatRanking struct {
+	CatID             int64  `json:"cat_id"`
+	Name              string `json:"name"`
+	CompletedMissions int    `json:"completed_missions"`
+}
+
+type SalaryStats struct {
+	Headcount int          `json:"headcount"`
+	Total     money.Amount `json:"total" swaggertype:"number"`
+	Average   money.Amount `json:"average" swaggertype:"number"`
+	Min       money.Amount `json:"min" swaggertype:"number"`
+	Max       money.Amount `json:"max" swaggertype:"number"`
+}
+
+// Repository computes the dashboard figures with SQL aggregates.
+type Repository interface {
+	// Dashboard fills everything but the derived fields such as
+	// CompletionRate, listing at most topCats cats.
+	Dashboard(ctx context.Context, topCats int) (*Dashboard, error)
+}
+
+type Usecase interface {
+	Dashboard(ctx context.Context, topCats int) (*Dashboard, error)
+}
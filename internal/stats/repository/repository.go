@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+
+	"go-test-assesment/internal/stats/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReportingPostgres runs the read-only aggregate queries behind /stats.
+type ReportingPostgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewReportingPostgres(pool *pgxpool.Pool) *ReportingPostgres {
+	return &ReportingPostgres{pool: pool}
+}
+
+// Dashboard runs every query in one repeatable read transaction so that
+// the figures agree with each other.
+func (r *ReportingPostgres) Dashboard(ctx context.Context, topCats int) (*domain.Dashboard, error) {
+	d := &domain.Dashboard{}
+	opts := pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}
+	err := pgx.BeginTxFunc(ctx, r.pool, opts, func(tx pgx.Tx) error {
+		var err error
+		if d.Missions, err = missionStats(ctx, tx); err != nil {
+			return err
+		}
+		if d.TargetsByCountry, err = targetsByCountry(ctx, tx); err != nil {
+			return err
+		}
+		if d.TopCats, err = topCatsByCompleted(ctx, tx, topCats); err != nil {
+			return err
+		}
+		d.Salaries, err = salaryStats(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func missionStats(ctx context.Context, tx pgx.Tx) (domain.MissionStats, error) {
+	query := `
+		SELECT count(*),
+		       count(*) FILTER (WHERE state = 'draft'),
+		       count(*) FILTER (WHERE state IN ('assigned', 'in_progress')),
+		       count(*) FILTER (WHERE state = 'completed'),
+		       count(*) FILTER (WHERE state = 'aborted'),
+		       count(*) FILTER (WHERE state = 'failed'),
+		       count(*) FILTER (WHERE cat_id IS NULL),
+		       avg(extract(epoch FROM COALESCE(completed_at, updated_at) - created_at)::float8)
+		           FILTER (WHERE state = 'completed')
+		FROM missions`
+	var s domain.MissionStats
+	var unassigned, inProgress, completed, aborted, failed int
+	err := tx.QueryRow(ctx, query).Scan(&s.Total, &unassigned, &inProgress, &completed, &aborted, &failed,
+		&s.Unassigned, &s.AvgTimeToComplete)
+	if err != nil {
+		return s, err
+	}
+	s.ByStatus = map[string]int{
+		domain.StatusUnassigned: unassigned,
+		domain.StatusInProgress: inProgress,
+		domain.StatusCompleted:  completed,
+		domain.StatusAborted:    aborted,
+		domain.StatusFailed:     failed,
+	}
+	return s, nil
+}
+
+func targetsByCountry(ctx context.Context, tx pgx.Tx) ([]domain.CountryCount, error) {
+	query := `
+		SELECT country, count(*), count(*) FILTER (WHERE completed)
+		FROM targets
+		GROUP BY country
+		ORDER BY count(*) DESC, country`
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []domain.CountryCount{}
+	for rows.Next() {
+		var c domain.CountryCount
+		if err := rows.Scan(&c.Country, &c.Targets, &c.Completed); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+func topCatsByCompleted(ctx context.Context, tx pgx.Tx, limit int) ([]domain.CatRanking, error) {
+	query := `
+		SELECT c.id, c.name, count(*)
+		FROM missions m
+		JOIN cats c ON c.id = m.cat_id
+		WHERE m.completed AND c.deleted_at IS NULL
+		GROUP BY c.id, c.name
+		ORDER BY count(*) DESC, c.id
+		LIMIT $1`
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cats := []domain.CatRanking{}
+	for rows.Next() {
+		var c domain.CatRanking
+		if err := rows.Scan(&c.CatID, &c.Name, &c.CompletedMissions); err != nil {
+			return nil, err
+		}
+		cats = append(cats, c)
+	}
+	return cats, rows.Err()
+}
+
+func salaryStats(ctx context.Context, tx pgx.Tx) (domain.SalaryStats, error) {
+	query := `
+		SELECT count(*),
+		       COALESCE(sum(salary), 0),
+		       COALESCE(round(avg(salary), 2), 0),
+		       COALESCE(min(salary), 0),
+		       COALESCE(max(salary), 0)
+		FROM cats
+		WHERE deleted_at IS NULL`
+	var s domain.SalaryStats
+	err := tx.QueryRow(ctx, query).Scan(&s.Headcount, &s.Total, &s.Average, &s.Min, &s.Max)
+	return s, err
+}
@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"go-test-assesment/internal/stats/domain"
+)
+
+const (
+	defaultTopCats = 5
+	maxTopCats     = 50
+)
+
+type StatsUsecase struct {
+	repo domain.Repository
+	now  func() time.Time
+}
+
+func NewStatsUsecase(repo domain.Repository) *StatsUsecase {
+	return &StatsUsecase{repo: repo, now: time.Now}
+}
+
+// Dashboard returns the agency summary with up to topCats cats ranked by
+// completed missions. A topCats outside 1..50 falls back to 5.
+func (uc *StatsUsecase) Dashboard(ctx context.Context, topCats int) (*domain.Dashboard, error) {
+	if topCats <= 0 || topCats > maxTopCats {
+		topCats = defaultTopCats
+	}
+	d, err := uc.repo.Dashboard(ctx, topCats)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &d.Missions
+	if m.Total > 0 {
+		rate := float64(m.ByStatus[domain.StatusCompleted]) / float64(m.Total)
+		m.CompletionRate = math.Round(rate*10000) / 10000
+	}
+	if m.AvgTimeToComplete != nil {
+		avg := math.Round(*m.AvgTimeToComplete)
+		m.AvgTimeToComplete = &avg
+	}
+	d.GeneratedAt = uc.now().UTC()
+	return d, nil
+}
@@ -0,0 +1,72 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-test-assesment/internal/stats/domain"
+	"go-test-assesment/internal/stats/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	dashboard *domain.Dashboard
+	err       error
+	topCats   int
+}
+
+func (r *fakeRepo) Dashboard(_ context.Context, topCats int) (*domain.Dashboard, error) {
+	r.topCats = topCats
+	return r.dashboard, r.err
+}
+
+func TestDashboard_DerivedFigures(t *testing.T) {
+	avg := 5400.4
+	repo := &fakeRepo{dashboard: &domain.Dashboard{
+		Missions: domain.MissionStats{
+			Total: 3,
+			ByStatus: map[string]int{
+				domain.StatusUnassigned: 1,
+				domain.StatusInProgress: 1,
+				domain.StatusCompleted:  1,
+			},
+			AvgTimeToComplete: &avg,
+		},
+	}}
+
+	d, err := usecase.NewStatsUsecase(repo).Dashboard(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 10, repo.topCats)
+	assert.Equal(t, 0.3333, d.Missions.CompletionRate)
+	require.NotNil(t, d.Missions.AvgTimeToComplete)
+	assert.Equal(t, 5400.0, *d.Missions.AvgTimeToComplete)
+	assert.False(t, d.GeneratedAt.IsZero())
+}
+
+func TestDashboard_NoMissions(t *testing.T) {
+	repo := &fakeRepo{dashboard: &domain.Dashboard{Missions: domain.MissionStats{ByStatus: map[string]int{}}}}
+
+	d, err := usecase.NewStatsUsecase(repo).Dashboard(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Zero(t, d.Missions.CompletionRate)
+	assert.Nil(t, d.Missions.AvgTimeToComplete)
+}
+
+func TestDashboard_TopCatsLimit(t *testing.T) {
+	for _, tt := range []struct{ in, want int }{{0, 5}, {-1, 5}, {1, 1}, {50, 50}, {51, 5}} {
+		repo := &fakeRepo{dashboard: &domain.Dashboard{}}
+		_, err := usecase.NewStatsUsecase(repo).Dashboard(context.Background(), tt.in)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, repo.topCats, "top cats %d", tt.in)
+	}
+}
+
+func TestDashboard_RepositoryError(t *testing.T) {
+	repo := &fakeRepo{err: errors.New("db error")}
+
+	_, err := usecase.NewStatsUsecase(repo).Dashboard(context.Background(), 5)
+	assert.EqualError(t, err, "db error")
+}
@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go-test-assesment/internal/webhook/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	usecase domain.Usecase
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type SubscriptionDTO struct {
+	URL        string   `json:"url" example:"https://example.com/hooks/missions"`
+	Secret     string   `json:"secret,omitempty" example:"s3cr3t"`
+	EventTypes []string `json:"event_types" example:"mission.created,mission.completed"`
+	Active     *bool    `json:"active,omitempty" example:"true"`
+}
+
+// CreatedSubscription is returned once on creation; the secret is not
+// exposed again afterwards.
+type CreatedSubscription struct {
+	*domain.Subscription
+	Secret string `json:"secret"`
+}
+
+func NewHandler(u domain.Usecase) *Handler {
+	return &Handler{usecase: u}
+}
+
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrSubscriptionNotFound), errors.Is(err, domain.ErrDeliveryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidURL), errors.Is(err, domain.ErrInvalidEventType),
+		errors.Is(err, domain.ErrNoEventTypes):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *Handler) RegisterRoutes(r *gin.Engine) {
+	webhooks := r.Group("/webhooks")
+	{
+		webhooks.POST("", h.createSubscription)
+		webhooks.GET("", h.listSubscriptions)
+		webhooks.GET("/deliveries", h.listDeliveries)
+		webhooks.POST("/deliveries/:id/retry", h.retryDelivery)
+		webhooks.GET("/:id", h.getSubscription)
+		webhooks.PUT("/:id", h.updateSubscription)
+		webhooks.DELETE("/:id", h.deleteSubscription)
+	}
+}
+
+func parseID(c *gin.Context) (int64, error) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errors.New("invalid id")
+	}
+	return id, nil
+}
+
+func (dto SubscriptionDTO) subscription(id int64) *domain.Subscription {
+	active := true
+	if dto.Active != nil {
+		active = *dto.Active
+	}
+	return &domain.Subscription{
+		ID:         id,
+		URL:        dto.URL,
+		Secret:     dto.Secret,
+		EventTypes: dto.EventTypes,
+		Active:     active,
+	}
+}
+
+// createSubscription godoc
+// @Summary Create a webhook subscription
+// @Description Subscribe a URL to mission events. Deliveries are signed with HMAC-SHA256 in the X-Webhook-Signature header; the secret is generated when omitted and only returned in this response.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param subscription body SubscriptionDTO true "Subscription details"
+// @Success 201 {object} CreatedSubscription
+// @Failure 400 {object} ErrorResponse "Invalid URL or event types"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /webhooks [post]
+func (h *Handler) createSubscription(c *gin.Context) {
+	var dto SubscriptionDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	s := dto.subscription(0)
+	if err := h.usecase.CreateSubscription(c.Request.Context(), s); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, CreatedSubscription{Subscription: s, Secret: s.Secret})
+}
+
+// listSubscriptions godoc
+// @Summary List webhook subscriptions
+// @Tags Webhooks
+// @Produce json
+// @Success 200 {array} domain.Subscription
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /webhooks [get]
+func (h *Handler) listSubscriptions(c *gin.Context) {
+	subs, err := h.usecase.ListSubscriptions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, subs)
+}
+
+// getSubscription godoc
+// @Summary Get a webhook subscription
+// @Tags Webhooks
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {object} domain.Subscription
+// @Failure 400 {object} ErrorResponse "Invalid subscription ID"
+// @Failure 404 {object} ErrorResponse "Subscription not found"
+// @Router /webhooks/{id} [get]
+func (h *Handler) getSubscription(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription id"})
+		c.Error(err)
+		return
+	}
+	s, err := h.usecase.GetSubscription(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, s)
+}
+
+// updateSubscription godoc
+// @Summary Update a webhook subscription
+// @Description Replace the URL, event types and active flag. An omitted secret keeps the current one.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param subscription body SubscriptionDTO true "Subscription details"
+// @Success 200 {object} domain.Subscription
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 404 {object} ErrorResponse "Subscription not found"
+// @Router /webhooks/{id} [put]
+func (h *Handler) updateSubscription(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription id"})
+		c.Error(err)
+		return
+	}
+	var dto SubscriptionDTO
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	s := dto.subscription(id)
+	if err := h.usecase.UpdateSubscription(c.Request.Context(), s); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, s)
+}
+
+// deleteSubscription godoc
+// @Summary Delete a webhook subscription
+// @Description Delete a subscription along with its pending and dead deliveries.
+// @Tags Webhooks
+// @Param id path int true "Subscription ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse "Invalid subscription ID"
+// @Failure 404 {object} ErrorResponse "Subscription not found"
+// @Router /webhooks/{id} [delete]
+func (h *Handler) deleteSubscription(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription id"})
+		c.Error(err)
+		return
+	}
+	if err := h.usecase.DeleteSubscription(c.Request.Context(), id); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// listDeliveries godoc
+// @Summary List webhook deliveries
+// @Description List the most recent deliveries in a status. Defaults to dead deliveries, i.e. the dead-letter queue.
+// @Tags Webhooks
+// @Produce json
+// @Param status query string false "Delivery status" Enums(pending, delivered, dead)
+// @Success 200 {array} domain.Delivery
+// @Failure 400 {object} ErrorResponse "Invalid status"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /webhooks/deliveries [get]
+func (h *Handler) listDeliveries(c *gin.Context) {
+	status := domain.DeliveryStatus(c.Query("status"))
+	switch status {
+	case "", domain.DeliveryPending, domain.DeliveryDelivered, domain.DeliveryDead:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+
+	deliveries, err := h.usecase.ListDeliveries(c.Request.Context(), status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// retryDelivery godoc
+// @Summary Retry a dead webhook delivery
+// @Description Move a dead delivery back to the queue with a fresh set of attempts.
+// @Tags Webhooks
+// @Param id path int true "Delivery ID"
+// @Success 202 "Accepted"
+// @Failure 400 {object} ErrorResponse "Invalid delivery ID"
+// @Failure 404 {object} ErrorResponse "Dead delivery not found"
+// @Router /webhooks/deliveries/{id}/retry [post]
+func (h *Handler) retryDelivery(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delivery id"})
+		c.Error(err)
+		return
+	}
+	if err := h.usecase.RetryDelivery(c.Request.Context(), id); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
@@ -0,0 +1,102 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	handler "go-test-assesment/internal/webhook/delivery/http"
+	"go-test-assesment/internal/webhook/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUsecase struct {
+	domain.Usecase
+
+	createFn func(ctx context.Context, s *domain.Subscription) error
+	retryFn  func(ctx context.Context, id int64) error
+	listFn   func(ctx context.Context, status domain.DeliveryStatus) ([]*domain.Delivery, error)
+}
+
+func (f *fakeUsecase) CreateSubscription(ctx context.Context, s *domain.Subscription) error {
+	return f.createFn(ctx, s)
+}
+func (f *fakeUsecase) RetryDelivery(ctx context.Context, id int64) error {
+	return f.retryFn(ctx, id)
+}
+func (f *fakeUsecase) ListDeliveries(ctx context.Context, status domain.DeliveryStatus) ([]*domain.Delivery, error) {
+	return f.listFn(ctx, status)
+}
+
+func newRouter(uc domain.Usecase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handler.NewHandler(uc).RegisterRoutes(r)
+	return r
+}
+
+func doRequest(r http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCreateSubscription(t *testing.T) {
+	uc := &fakeUsecase{createFn: func(_ context.Context, s *domain.Subscription) error {
+		if s.URL == "bad" {
+			return domain.ErrInvalidURL
+		}
+		s.ID = 1
+		s.Secret = "generated"
+		return nil
+	}}
+	r := newRouter(uc)
+
+	w := doRequest(r, http.MethodPost, "/webhooks", `{"url":"https://example.com","event_types":["mission.created"]}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "generated", resp["secret"])
+	assert.Equal(t, true, resp["active"])
+
+	w = doRequest(r, http.MethodPost, "/webhooks", `{"url":"bad","event_types":["mission.created"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestListDeliveries(t *testing.T) {
+	var gotStatus domain.DeliveryStatus
+	uc := &fakeUsecase{listFn: func(_ context.Context, status domain.DeliveryStatus) ([]*domain.Delivery, error) {
+		gotStatus = status
+		return []*domain.Delivery{{ID: 5, Status: domain.DeliveryDead}}, nil
+	}}
+	r := newRouter(uc)
+
+	w := doRequest(r, http.MethodGet, "/webhooks/deliveries?status=dead", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, domain.DeliveryDead, gotStatus)
+
+	w = doRequest(r, http.MethodGet, "/webhooks/deliveries?status=lost", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRetryDelivery(t *testing.T) {
+	uc := &fakeUsecase{retryFn: func(_ context.Context, id int64) error {
+		if id == 5 {
+			return nil
+		}
+		return domain.ErrDeliveryNotFound
+	}}
+	r := newRouter(uc)
+
+	assert.Equal(t, http.StatusAccepted, doRequest(r, http.MethodPost, "/webhooks/deliveries/5/retry", "").Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(r, http.MethodPost, "/webhooks/deliveries/6/retry", "").Code)
+	assert.Equal(t, http.StatusBadRequest, doRequest(r, http.MethodPost, "/webhooks/deliveries/x/retry", "").Code)
+}
@@ -0,0 +1,162 @@
+package dispatcher
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-test-assesment/internal/webhook/domain"
+)
+
+const (
+	batchSize      = 50
+	pollInterval   = 5 * time.Second
+	requestTimeout = 10 * time.Second
+	// lease must outlast the whole batch, which is sent one request at a
+	// time, so that no delivery of it is claimed again while in flight.
+	lease = batchSize*requestTimeout + time.Minute
+
+	// MaxAttempts is how many times a delivery is tried before it is dead.
+	MaxAttempts = 8
+	baseBackoff = 10 * time.Second
+	maxBackoff  = time.Hour
+)
+
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Sign returns the signature header value for body sent at t:
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">". Receivers should
+// recompute it with the shared secret and reject stale timestamps.
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff returns the delay before the next try after attempts failed ones.
+func Backoff(attempts int) time.Duration {
+	d := baseBackoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
+
+// Dispatcher sends pending deliveries from the outbox. Deliveries are written
+// by a database trigger in the same transaction as the event, so none are
+// lost if the process stops; at-least-once delivery means receivers should
+// deduplicate on the X-Webhook-Id header.
+type Dispatcher struct {
+	repo   domain.Repository
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewDispatcher(repo domain.Repository, client *http.Client) *Dispatcher {
+	if client == nil {
+		client = &http.Client{Timeout: requestTimeout}
+	}
+	return &Dispatcher{repo: repo, client: client, now: time.Now}
+}
+
+// Run processes due deliveries until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		// Keep draining while full batches come back.
+		for {
+			n, err := d.ProcessDue(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("[ERROR] webhook dispatcher: %v", err)
+			}
+			if err != nil || n < batchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue claims one batch of due deliveries and attempts each of them,
+// returning how many were claimed.
+func (d *Dispatcher) ProcessDue(ctx context.Context) (int, error) {
+	deliveries, err := d.repo.ClaimDueDeliveries(ctx, batchSize, lease)
+	if err != nil {
+		return 0, err
+	}
+	for _, delivery := range deliveries {
+		if err := d.attempt(ctx, delivery); err != nil {
+			return len(deliveries), err
+		}
+	}
+	return len(deliveries), nil
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, delivery *domain.Delivery) error {
+	sendErr := d.send(ctx, delivery)
+	if sendErr == nil {
+		return d.repo.MarkDelivered(ctx, delivery.ID)
+	}
+	if ctx.Err() != nil {
+		// Shutting down; the lease expires and the delivery is retried.
+		return ctx.Err()
+	}
+
+	attempts := delivery.Attempts + 1
+	var next *time.Time
+	if attempts < MaxAttempts {
+		t := d.now().Add(Backoff(attempts))
+		next = &t
+	}
+	return d.repo.MarkFailed(ctx, delivery.ID, sendErr.Error(), next)
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery *domain.Delivery) error {
+	// The lease counts on requestTimeout, whatever the client's timeout.
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderID, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, d.now(), delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
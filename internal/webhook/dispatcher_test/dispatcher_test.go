@@ -0,0 +1,124 @@
+package dispatcher_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-test-assesment/internal/webhook/dispatcher"
+	"go-test-assesment/internal/webhook/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failure struct {
+	lastError   string
+	nextAttempt *time.Time
+}
+
+type fakeRepo struct {
+	domain.Repository
+
+	mu        sync.Mutex
+	due       []*domain.Delivery
+	delivered []int64
+	failed    map[int64]failure
+}
+
+func (r *fakeRepo) ClaimDueDeliveries(_ context.Context, limit int, _ time.Duration) ([]*domain.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := min(limit, len(r.due))
+	claimed := r.due[:n]
+	r.due = r.due[n:]
+	return claimed, nil
+}
+
+func (r *fakeRepo) MarkDelivered(_ context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delivered = append(r.delivered, id)
+	return nil
+}
+
+func (r *fakeRepo) MarkFailed(_ context.Context, id int64, lastError string, next *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failed == nil {
+		r.failed = map[int64]failure{}
+	}
+	r.failed[id] = failure{lastError: lastError, nextAttempt: next}
+	return nil
+}
+
+func TestDispatcher_SignsAndDelivers(t *testing.T) {
+	payload := `{"id":7,"type":"mission.completed","mission_id":3}`
+
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	repo := &fakeRepo{due: []*domain.Delivery{{
+		ID: 42, EventType: "mission.completed", Payload: []byte(payload), URL: srv.URL, Secret: "secret",
+	}}}
+	n, err := dispatcher.NewDispatcher(repo, srv.Client()).ProcessDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []int64{42}, repo.delivered)
+
+	require.NotNil(t, got)
+	assert.Equal(t, payload, string(body))
+	assert.Equal(t, "42", got.Header.Get(dispatcher.HeaderID))
+	assert.Equal(t, "mission.completed", got.Header.Get(dispatcher.HeaderEvent))
+
+	sig := got.Header.Get(dispatcher.HeaderSignature)
+	ts, _, ok := strings.Cut(strings.TrimPrefix(sig, "t="), ",")
+	require.True(t, ok)
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, dispatcher.Sign("secret", time.Unix(unix, 0), body), sig)
+	assert.NotEqual(t, dispatcher.Sign("other", time.Unix(unix, 0), body), sig)
+}
+
+func TestDispatcher_FailureSchedulesRetry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	repo := &fakeRepo{due: []*domain.Delivery{
+		{ID: 1, Payload: []byte(`{}`), URL: srv.URL, Attempts: 0},
+		{ID: 2, Payload: []byte(`{}`), URL: srv.URL, Attempts: dispatcher.MaxAttempts - 1},
+	}}
+	before := time.Now()
+	_, err := dispatcher.NewDispatcher(repo, srv.Client()).ProcessDue(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, repo.delivered)
+
+	retry := repo.failed[1]
+	assert.Contains(t, retry.lastError, "503")
+	require.NotNil(t, retry.nextAttempt)
+	assert.WithinDuration(t, before.Add(dispatcher.Backoff(1)), *retry.nextAttempt, time.Second)
+
+	dead := repo.failed[2]
+	assert.Contains(t, dead.lastError, "503")
+	assert.Nil(t, dead.nextAttempt, "last attempt should mark the delivery dead")
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 10*time.Second, dispatcher.Backoff(1))
+	assert.Equal(t, 20*time.Second, dispatcher.Backoff(2))
+	assert.Equal(t, 80*time.Second, dispatcher.Backoff(4))
+	assert.Equal(t, time.Hour, dispatcher.Backoff(20))
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound     = errors.New("webhook delivery not found")
+	ErrInvalidURL           = errors.New("webhook url must be an absolute http or https url")
+	ErrInvalidEventType     = errors.New("unknown event type")
+	ErrNoEventTypes         = errors.New("at least one event type is required")
+)
+
+type Subscription struct {
+	ID         int64     `json:"id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"-"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	// DeliveryDead marks deliveries that exhausted their retries.
+	DeliveryDead DeliveryStatus = "dead"
+)
+
+type Delivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID int64           `json:"subscription_id"`
+	EventID        int64           `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         DeliveryStatus  `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+
+	// Endpoint details, only populated on claimed deliveries.
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+type Repository interface {
+	CreateSubscription(ctx context.Context, s *Subscription) error
+	GetSubscription(ctx context.Context, id int64) (*Subscription, error)
+	ListSubscriptions(ctx context.Context) ([]*Subscription, error)
+	UpdateSubscription(ctx context.Context, s *Subscription) error
+	DeleteSubscription(ctx context.Context, id int64) error
+
+	ListDeliveries(ctx context.Context, status DeliveryStatus, limit int) ([]*Delivery, error)
+	// RetryDelivery moves a dead delivery back to pending with fresh attempts.
+	RetryDelivery(ctx context.Context, id int64) error
+
+	// ClaimDueDeliveries leases due pending deliveries of active
+	// subscriptions for lease so that concurrent dispatchers do not send
+	// them twice. Those of inactive subscriptions wait until reactivated.
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*Delivery, error)
+	MarkDelivered(ctx context.Context, id int64) error
+	// MarkFailed records a failed attempt; a nil nextAttempt marks the
+	// delivery dead.
+	MarkFailed(ctx context.Context, id int64, lastError string, nextAttempt *time.Time) error
+}
+
+type Usecase interface {
+	CreateSubscription(ctx context.Context, s *Subscription) error
+	GetSubscription(ctx context.Context, id int64) (*Subscription, error)
+	ListSubscriptions(ctx context.Context) ([]*Subscription, error)
+	UpdateSubscription(ctx context.Context, s *Subscription) error
+	DeleteSubscription(ctx context.Context, id int64) error
+
+	ListDeliveries(ctx context.Context, status DeliveryStatus) ([]*Delivery, error)
+	RetryDelivery(ctx context.Context, id int64) error
+}
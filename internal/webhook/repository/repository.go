@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-test-assesment/internal/webhook/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WebhookPostgres struct {
+	pool *pgxpool.Pool
+}
+
+func NewWebhookPostgres(pool *pgxpool.Pool) *WebhookPostgres {
+	return &WebhookPostgres{pool: pool}
+}
+
+func (r *WebhookPostgres) CreateSubscription(ctx context.Context, s *domain.Subscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (url, secret, event_types, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, now(), now())
+		RETURNING id, created_at, updated_at`
+	return r.pool.QueryRow(ctx, query, s.URL, s.Secret, s.EventTypes, s.Active).
+		Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
+}
+
+func (r *WebhookPostgres) GetSubscription(ctx context.Context, id int64) (*domain.Subscription, error) {
+	s := &domain.Subscription{}
+	query := `
+		SELECT id, url, secret, event_types, active, created_at, updated_at
+		FROM webhook_subscriptions WHERE id = $1`
+	err := r.pool.QueryRow(ctx, query, id).
+		Scan(&s.ID, &s.URL, &s.Secret, &s.EventTypes, &s.Active, &s.CreatedAt, &s.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *WebhookPostgres) ListSubscriptions(ctx context.Context) ([]*domain.Subscription, error) {
+	query := `
+		SELECT id, url, secret, event_types, active, created_at, updated_at
+		FROM webhook_subscriptions ORDER BY id`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []*domain.Subscription{}
+	for rows.Next() {
+		s := &domain.Subscription{}
+		if err := rows.Scan(&s.ID, &s.URL, &s.Secret, &s.EventTypes, &s.Active, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		subs = append(subs, s)
+	}
+	return subs, rows.Err()
+}
+
+func (r *WebhookPostgres) UpdateSubscription(ctx context.Context, s *domain.Subscription) error {
+	query := `
+		UPDATE webhook_subscriptions
+		SET url = $1, secret = $2, event_types = $3, active = $4, updated_at = now()
+		WHERE id = $5
+		RETURNING created_at, updated_at`
+	err := r.pool.QueryRow(ctx, query, s.URL, s.Secret, s.EventTypes, s.Active, s.ID).
+		Scan(&s.CreatedAt, &s.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrSubscriptionNotFound
+	}
+	return err
+}
+
+func (r *WebhookPostgres) DeleteSubscription(ctx context.Context, id int64) error {
+	res, err := r.pool.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+const deliveryColumns = `
+	d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status,
+	d.attempts, d.next_attempt_at, d.last_error, d.created_at, d.delivered_at`
+
+func scanDelivery(row pgx.Row, extra ...any) (*domain.Delivery, error) {
+	d := &domain.Delivery{}
+	dest := []any{
+		&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Payload, &d.Status,
+		&d.Attempts, &d.NextAttemptAt, &d.LastError, &d.CreatedAt, &d.DeliveredAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (r *WebhookPostgres) ListDeliveries(ctx context.Context, status domain.DeliveryStatus, limit int) ([]*domain.Delivery, error) {
+	query := `SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries d
+		WHERE d.status = $1
+		ORDER BY d.created_at DESC
+		LIMIT $2`
+	rows, err := r.pool.Query(ctx, query, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*domain.Delivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *WebhookPostgres) RetryDelivery(ctx context.Context, id int64) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = now(), last_error = ''
+		WHERE id = $1 AND status = 'dead'`
+	res, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrDeliveryNotFound
+	}
+	return nil
+}
+
+func (r *WebhookPostgres) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*domain.Delivery, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = now() + $2 * interval '1 millisecond'
+		FROM webhook_subscriptions s
+		WHERE s.id = d.subscription_id
+		  AND s.active
+		  AND d.id IN (
+			SELECT pd.id FROM webhook_deliveries pd
+			JOIN webhook_subscriptions ps ON ps.id = pd.subscription_id
+			WHERE pd.status = 'pending' AND pd.next_attempt_at <= now() AND ps.active
+			ORDER BY pd.next_attempt_at
+			LIMIT $1
+			FOR UPDATE OF pd SKIP LOCKED
+		  )
+		RETURNING ` + deliveryColumns + `, s.url, s.secret`
+	rows, err := r.pool.Query(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*domain.Delivery
+	for rows.Next() {
+		var url, secret string
+		d, err := scanDelivery(rows, &url, &secret)
+		if err != nil {
+			return nil, err
+		}
+		d.URL, d.Secret = url, secret
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *WebhookPostgres) MarkDelivered(ctx context.Context, id int64) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'delivered', attempts = attempts + 1, last_error = '', delivered_at = now()
+		WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, id)
+	return err
+}
+
+func (r *WebhookPostgres) MarkFailed(ctx context.Context, id int64, lastError string, nextAttempt *time.Time) error {
+	if nextAttempt == nil {
+		query := `
+			UPDATE webhook_deliveries
+			SET status = 'dead', attempts = attempts + 1, last_error = $1
+			WHERE id = $2`
+		_, err := r.pool.Exec(ctx, query, lastError, id)
+		return err
+	}
+	query := `
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2
+		WHERE id = $3`
+	_, err := r.pool.Exec(ctx, query, lastError, *nextAttempt, id)
+	return err
+}
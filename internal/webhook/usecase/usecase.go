@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+
+	event "go-test-assesment/internal/event/domain"
+	"go-test-assesment/internal/webhook/domain"
+)
+
+// deliveryListLimit caps the dead-letter view; older entries stay in the table.
+const deliveryListLimit = 100
+
+var eventTypes = map[string]bool{
+	string(event.MissionCreated):   true,
+	string(event.MissionAssigned):  true,
+	string(event.MissionStarted):   true,
+	string(event.MissionCompleted): true,
+	string(event.MissionAborted):   true,
+	string(event.MissionFailed):    true,
+	string(event.TargetUpdated):    true,
+	string(event.MissionOverdue):   true,
+	string(event.TargetOverdue):    true,
+}
+
+type WebhookUsecase struct {
+	repo domain.Repository
+}
+
+func NewWebhookUsecase(repo domain.Repository) *WebhookUsecase {
+	return &WebhookUsecase{repo: repo}
+}
+
+// CreateSubscription validates s and stores it. A signing secret is
+// generated when none is given.
+func (uc *WebhookUsecase) CreateSubscription(ctx context.Context, s *domain.Subscription) error {
+	if err := validateSubscription(s); err != nil {
+		return err
+	}
+	if s.Secret == "" {
+		secret, err := newSecret()
+		if err != nil {
+			return err
+		}
+		s.Secret = secret
+	}
+	return uc.repo.CreateSubscription(ctx, s)
+}
+
+func (uc *WebhookUsecase) GetSubscription(ctx context.Context, id int64) (*domain.Subscription, error) {
+	return uc.repo.GetSubscription(ctx, id)
+}
+
+func (uc *WebhookUsecase) ListSubscriptions(ctx context.Context) ([]*domain.Subscription, error) {
+	return uc.repo.ListSubscriptions(ctx)
+}
+
+// UpdateSubscription replaces the subscription's settings. An empty secret
+// keeps the current one.
+func (uc *WebhookUsecase) UpdateSubscription(ctx context.Context, s *domain.Subscription) error {
+	if err := validateSubscription(s); err != nil {
+		return err
+	}
+	if s.Secret == "" {
+		existing, err := uc.repo.GetSubscription(ctx, s.ID)
+		if err != nil {
+			return err
+		}
+		s.Secret = existing.Secret
+	}
+	return uc.repo.UpdateSubscription(ctx, s)
+}
+
+func (uc *WebhookUsecase) DeleteSubscription(ctx context.Context, id int64) error {
+	return uc.repo.DeleteSubscription(ctx, id)
+}
+
+// ListDeliveries lists deliveries in status, defaulting to dead ones.
+func (uc *WebhookUsecase) ListDeliveries(ctx context.Context, status domain.DeliveryStatus) ([]*domain.Delivery, error) {
+	if status == "" {
+		status = domain.DeliveryDead
+	}
+	return uc.repo.ListDeliveries(ctx, status, deliveryListLimit)
+}
+
+func (uc *WebhookUsecase) RetryDelivery(ctx context.Context, id int64) error {
+	return uc.repo.RetryDelivery(ctx, id)
+}
+
+func validateSubscription(s *domain.Subscription) error {
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return domain.ErrInvalidURL
+	}
+	if len(s.EventTypes) == 0 {
+		return domain.ErrNoEventTypes
+	}
+	for _, t := range s.EventTypes {
+		if !eventTypes[t] {
+			return domain.ErrInvalidEventType
+		}
+	}
+	return nil
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
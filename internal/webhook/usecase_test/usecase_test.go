@@ -0,0 +1,101 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"go-test-assesment/internal/webhook/domain"
+	"go-test-assesment/internal/webhook/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	domain.Repository
+
+	subs       map[int64]*domain.Subscription
+	listStatus domain.DeliveryStatus
+}
+
+func (r *fakeRepo) CreateSubscription(_ context.Context, s *domain.Subscription) error {
+	s.ID = int64(len(r.subs) + 1)
+	r.subs[s.ID] = s
+	return nil
+}
+
+func (r *fakeRepo) GetSubscription(_ context.Context, id int64) (*domain.Subscription, error) {
+	s, ok := r.subs[id]
+	if !ok {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+	stored := *s
+	return &stored, nil
+}
+
+func (r *fakeRepo) UpdateSubscription(_ context.Context, s *domain.Subscription) error {
+	r.subs[s.ID] = s
+	return nil
+}
+
+func (r *fakeRepo) ListDeliveries(_ context.Context, status domain.DeliveryStatus, _ int) ([]*domain.Delivery, error) {
+	r.listStatus = status
+	return nil, nil
+}
+
+func newUsecase() (*usecase.WebhookUsecase, *fakeRepo) {
+	repo := &fakeRepo{subs: map[int64]*domain.Subscription{}}
+	return usecase.NewWebhookUsecase(repo), repo
+}
+
+func TestCreateSubscription_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		sub     domain.Subscription
+		wantErr error
+	}{
+		{"relative url", domain.Subscription{URL: "/hooks", EventTypes: []string{"mission.created"}}, domain.ErrInvalidURL},
+		{"unsupported scheme", domain.Subscription{URL: "ftp://example.com", EventTypes: []string{"mission.created"}}, domain.ErrInvalidURL},
+		{"no event types", domain.Subscription{URL: "https://example.com"}, domain.ErrNoEventTypes},
+		{"unknown event type", domain.Subscription{URL: "https://example.com", EventTypes: []string{"cat.created"}}, domain.ErrInvalidEventType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, _ := newUsecase()
+			err := uc.CreateSubscription(context.Background(), &tt.sub)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCreateSubscription_GeneratesSecret(t *testing.T) {
+	uc, repo := newUsecase()
+	s := &domain.Subscription{URL: "https://example.com/hook", EventTypes: []string{"mission.completed"}}
+	require.NoError(t, uc.CreateSubscription(context.Background(), s))
+	assert.Len(t, s.Secret, 64)
+	assert.Equal(t, s, repo.subs[s.ID])
+
+	given := &domain.Subscription{URL: "https://example.com/hook", Secret: "mine", EventTypes: []string{"mission.completed"}}
+	require.NoError(t, uc.CreateSubscription(context.Background(), given))
+	assert.Equal(t, "mine", given.Secret)
+}
+
+func TestUpdateSubscription_KeepsSecret(t *testing.T) {
+	uc, repo := newUsecase()
+	repo.subs[1] = &domain.Subscription{ID: 1, URL: "https://a.example", Secret: "old", EventTypes: []string{"mission.created"}}
+
+	s := &domain.Subscription{ID: 1, URL: "https://b.example", EventTypes: []string{"target.updated"}}
+	require.NoError(t, uc.UpdateSubscription(context.Background(), s))
+	assert.Equal(t, "old", repo.subs[1].Secret)
+	assert.Equal(t, "https://b.example", repo.subs[1].URL)
+
+	missing := &domain.Subscription{ID: 9, URL: "https://b.example", EventTypes: []string{"target.updated"}}
+	assert.ErrorIs(t, uc.UpdateSubscription(context.Background(), missing), domain.ErrSubscriptionNotFound)
+}
+
+func TestListDeliveries_DefaultsToDead(t *testing.T) {
+	uc, repo := newUsecase()
+	_, err := uc.ListDeliveries(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeliveryDead, repo.listStatus)
+}
@@ -0,0 +1,48 @@
+// Package blob stores files under keys, on the local disk or in an
+// S3-compatible object store.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+type Store interface {
+	// Put stores the size bytes read from r under key, replacing the blob
+	// stored there, if any.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the blob stored under key, returning ErrNotFound if there is
+	// none. The caller must close it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob stored under key. Deleting a missing blob is
+	// not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// checkKey accepts keys made of slash-separated segments of ASCII letters,
+// digits, '.', '-' and '_', so that keys map to the same path on every store
+// and can never escape it.
+func checkKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("%w: empty key", ErrInvalidKey)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("%w %q", ErrInvalidKey, key)
+		}
+		for _, r := range segment {
+			if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || strings.ContainsRune(".-_", r)) {
+				return fmt.Errorf("%w %q", ErrInvalidKey, key)
+			}
+		}
+	}
+	return nil
+}
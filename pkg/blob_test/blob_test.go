@@ -0,0 +1,167 @@
+package blob_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-test-assesment/pkg/blob"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 is a stand-in for an S3 bucket that keeps objects in memory. It
+// rejects requests for other buckets and unsigned requests.
+type fakeS3 struct {
+	mu      sync.Mutex
+	bucket  string
+	objects map[string]object
+}
+
+type object struct {
+	data        []byte
+	contentType string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=test-key/") ||
+		!strings.Contains(auth, "/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=") ||
+		r.Header.Get("X-Amz-Date") == "" || r.Header.Get("X-Amz-Content-Sha256") != "UNSIGNED-PAYLOAD" {
+		http.Error(w, "<Error><Code>AccessDenied</Code></Error>", http.StatusForbidden)
+		return
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+f.bucket+"/")
+	if !ok {
+		http.Error(w, "<Error><Code>NoSuchBucket</Code></Error>", http.StatusNotFound)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil || int64(len(data)) != r.ContentLength {
+			http.Error(w, "<Error><Code>IncompleteBody</Code></Error>", http.StatusBadRequest)
+			return
+		}
+		f.objects[key] = object{data: data, contentType: r.Header.Get("Content-Type")}
+	case http.MethodGet:
+		obj, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", obj.contentType)
+		w.Write(obj.data)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newS3Store(t *testing.T, bucket string) (*blob.S3Store, *fakeS3) {
+	fake := &fakeS3{bucket: "attachments", objects: map[string]object{}}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	store, err := blob.NewS3Store(blob.S3Config{
+		Endpoint:        srv.URL,
+		Region:          "us-east-1",
+		Bucket:          bucket,
+		AccessKeyID:     "test-key",
+		SecretAccessKey: "test-secret",
+	}, srv.Client())
+	require.NoError(t, err)
+	return store, fake
+}
+
+func stores(t *testing.T) map[string]blob.Store {
+	disk, err := blob.NewDiskStore(t.TempDir())
+	require.NoError(t, err)
+	s3, _ := newS3Store(t, "attachments")
+	return map[string]blob.Store{"disk": disk, "s3": s3}
+}
+
+func read(t *testing.T, store blob.Store, key string) string {
+	r, err := store.Get(context.Background(), key)
+	require.NoError(t, err)
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, store.Put(ctx, "targets/1/a.txt", strings.NewReader("first"), 5, "text/plain"))
+			assert.Equal(t, "first", read(t, store, "targets/1/a.txt"))
+
+			require.NoError(t, store.Put(ctx, "targets/1/a.txt", strings.NewReader("second"), 6, "text/plain"))
+			assert.Equal(t, "second", read(t, store, "targets/1/a.txt"))
+
+			require.NoError(t, store.Put(ctx, "targets/1/empty", strings.NewReader(""), 0, ""))
+			assert.Equal(t, "", read(t, store, "targets/1/empty"))
+
+			require.NoError(t, store.Delete(ctx, "targets/1/a.txt"))
+			_, err := store.Get(ctx, "targets/1/a.txt")
+			assert.ErrorIs(t, err, blob.ErrNotFound)
+			assert.NoError(t, store.Delete(ctx, "targets/1/a.txt"), "deleting a missing blob")
+		})
+	}
+}
+
+func TestStore_InvalidKeys(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, key := range []string{"", "../escape", "a/../../b", "/abs", "a//b", "a/", "sp ace", "semi;colon"} {
+				err := store.Put(ctx, key, strings.NewReader("x"), 1, "")
+				assert.ErrorIs(t, err, blob.ErrInvalidKey, key)
+				_, err = store.Get(ctx, key)
+				assert.ErrorIs(t, err, blob.ErrInvalidKey, key)
+				assert.ErrorIs(t, store.Delete(ctx, key), blob.ErrInvalidKey, key)
+			}
+		})
+	}
+}
+
+func TestDiskStore_ShortWrite(t *testing.T) {
+	store, err := blob.NewDiskStore(t.TempDir())
+	require.NoError(t, err)
+
+	err = store.Put(context.Background(), "a", strings.NewReader("abc"), 5, "")
+	assert.Error(t, err)
+	_, err = store.Get(context.Background(), "a")
+	assert.ErrorIs(t, err, blob.ErrNotFound, "a failed write leaves nothing behind")
+}
+
+func TestS3Store_Objects(t *testing.T) {
+	store, fake := newS3Store(t, "attachments")
+
+	err := store.Put(context.Background(), "targets/1/photo", strings.NewReader("\xff\xd8\xff"), 3, "image/jpeg")
+	require.NoError(t, err)
+	assert.Equal(t, object{data: []byte("\xff\xd8\xff"), contentType: "image/jpeg"}, fake.objects["targets/1/photo"])
+}
+
+func TestS3Store_Errors(t *testing.T) {
+	store, _ := newS3Store(t, "missing")
+
+	err := store.Put(context.Background(), "a", strings.NewReader("x"), 1, "")
+	assert.ErrorContains(t, err, "NoSuchBucket")
+	assert.NotErrorIs(t, err, blob.ErrNotFound)
+
+	_, err = blob.NewS3Store(blob.S3Config{Endpoint: "localhost:9000", Region: "us-east-1", Bucket: "b"}, nil)
+	assert.Error(t, err)
+	_, err = blob.NewS3Store(blob.S3Config{Endpoint: "http://localhost:9000", Region: "us-east-1"}, nil)
+	assert.Error(t, err)
+}
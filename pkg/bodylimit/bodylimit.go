@@ -0,0 +1,50 @@
+package bodylimit
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware rejects request bodies larger than maxBytes with 413, or the
+// limit configured for the route in routes (keyed like "POST /cats"). The
+// body is read up front so that handlers never see a truncated request and
+// report it as malformed.
+func Middleware(maxBytes int64, routes map[string]int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		limit := maxBytes
+		if l, ok := routes[c.Request.Method+" "+c.FullPath()]; ok {
+			limit = l
+		}
+		if c.Request.ContentLength > limit {
+			tooLarge(c)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, limit))
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				tooLarge(c)
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+			c.Error(err)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
+
+func tooLarge(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+}
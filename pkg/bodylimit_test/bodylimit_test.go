@@ -0,0 +1,44 @@
+package bodylimit_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-test-assesment/pkg/bodylimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// chunked hides the length so that only the read limit applies.
+type chunked struct{ io.Reader }
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(bodylimit.Middleware(8, map[string]int64{"POST /upload": 16}))
+	echo := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	}
+	r.POST("/cats", echo)
+	r.POST("/upload", echo)
+
+	do := func(path string, body io.Reader) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, body)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("/cats", strings.NewReader("12345678"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "12345678", w.Body.String())
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, do("/cats", strings.NewReader("123456789")).Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, do("/cats", chunked{strings.NewReader("123456789")}).Code)
+	assert.Equal(t, http.StatusOK, do("/upload", strings.NewReader("123456789")).Code)
+}
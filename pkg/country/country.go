@@ -0,0 +1,138 @@
+// Package country validates and names countries by their ISO 3166-1 alpha-2
+// code, using a dataset embedded in the binary.
+package country
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+//go:embed countries.csv
+var dataset string
+
+type Country struct {
+	Code   string `json:"code"`
+	Alpha3 string `json:"alpha3"`
+	Name   string `json:"name"`
+}
+
+var (
+	byCode = map[string]Country{}
+	// byKey maps every accepted spelling, folded by key, to an alpha-2 code.
+	byKey = map[string]string{}
+
+	languages []language.Tag
+	matcher   language.Matcher
+)
+
+func init() {
+	records, err := csv.NewReader(strings.NewReader(dataset)).ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("country: reading dataset: %v", err))
+	}
+	for _, r := range records[1:] {
+		c := Country{Code: r[0], Alpha3: r[1], Name: r[2]}
+		byCode[c.Code] = c
+		spellings := []string{c.Code, c.Alpha3, c.Name}
+		if r[3] != "" {
+			spellings = append(spellings, strings.Split(r[3], "|")...)
+		}
+		for _, s := range spellings {
+			k := key(s)
+			if prev, ok := byKey[k]; ok && prev != c.Code {
+				panic(fmt.Sprintf("country: %q is ambiguous between %s and %s", s, prev, c.Code))
+			}
+			byKey[k] = c.Code
+		}
+	}
+
+	// English comes first so that it is the fallback for unsupported languages.
+	languages = []language.Tag{language.English}
+	for _, t := range display.Supported.Tags() {
+		if t != language.English {
+			languages = append(languages, t)
+		}
+	}
+	matcher = language.NewMatcher(languages)
+}
+
+// key folds s for lookups: case, diacritics, dots and apostrophes are
+// ignored, "&" reads as "and", and hyphens and runs of spaces are equal.
+func key(s string) string {
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn))), s)
+	if err != nil {
+		folded = s
+	}
+	folded = strings.NewReplacer(".", "", "'", "", "’", "", "&", " and ", "-", " ").Replace(folded)
+	return strings.Join(strings.Fields(strings.ToLower(folded)), " ")
+}
+
+// Normalize returns the alpha-2 code for s, which may be an alpha-2 or
+// alpha-3 code, the English name or a common alias such as "UK" or "Holland".
+func Normalize(s string) (string, bool) {
+	code, ok := byKey[key(s)]
+	return code, ok
+}
+
+// Lookup returns the country with the given alpha-2 code.
+func Lookup(code string) (Country, bool) {
+	c, ok := byCode[strings.ToUpper(code)]
+	return c, ok
+}
+
+// All returns every country ordered by code.
+func All() []Country {
+	all := make([]Country, 0, len(byCode))
+	for _, c := range byCode {
+		all = append(all, c)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Code < all[j].Code })
+	return all
+}
+
+// Name returns the name of the country with the given alpha-2 code in lang,
+// falling back to the English name. Unknown codes are returned unchanged.
+func Name(code string, lang language.Tag) string {
+	c, ok := Lookup(code)
+	if !ok {
+		return code
+	}
+	if base, _ := lang.Base(); base.String() == "en" {
+		return c.Name
+	}
+	if namer := display.Regions(lang); namer != nil {
+		if region, err := language.ParseRegion(c.Code); err == nil {
+			if name := namer.Name(region); name != "" {
+				return name
+			}
+		}
+	}
+	return c.Name
+}
+
+// Language picks the best supported language for the given preferences,
+// each either a language tag or an Accept-Language header value. Empty and
+// malformed preferences are skipped; English is the default.
+func Language(prefs ...string) language.Tag {
+	var tags []language.Tag
+	for _, p := range prefs {
+		if parsed, _, err := language.ParseAcceptLanguage(p); err == nil {
+			tags = append(tags, parsed...)
+		}
+	}
+	_, i, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return language.English
+	}
+	return languages[i]
+}
@@ -0,0 +1,82 @@
+package country_test
+
+import (
+	"testing"
+
+	"go-test-assesment/pkg/country"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"US", "US"},
+		{"us", "US"},
+		{"USA", "US"},
+		{"U.S.A.", "US"},
+		{"United States", "US"},
+		{" united   states of america ", "US"},
+		{"UK", "GB"},
+		{"Great Britain", "GB"},
+		{"GBR", "GB"},
+		{"Holland", "NL"},
+		{"Côte d’Ivoire", "CI"},
+		{"cote d'ivoire", "CI"},
+		{"Ivory Coast", "CI"},
+		{"Guinea Bissau", "GW"},
+		{"Bosnia & Herzegovina", "BA"},
+		{"Bosnia and Herzegovina", "BA"},
+		{"Türkiye", "TR"},
+		{"Turkiye", "TR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, ok := country.Normalize(tt.in)
+			require.True(t, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, in := range []string{"", "Atlantis", "XX", "EU"} {
+		_, ok := country.Normalize(in)
+		assert.False(t, ok, in)
+	}
+}
+
+func TestAll(t *testing.T) {
+	all := country.All()
+	assert.Len(t, all, 249)
+	for i, c := range all {
+		assert.Len(t, c.Code, 2)
+		assert.Len(t, c.Alpha3, 3)
+		assert.NotEmpty(t, c.Name)
+		if i > 0 {
+			assert.Less(t, all[i-1].Code, c.Code)
+		}
+		got, ok := country.Normalize(c.Name)
+		assert.True(t, ok, c.Name)
+		assert.Equal(t, c.Code, got, c.Name)
+	}
+}
+
+func TestName(t *testing.T) {
+	assert.Equal(t, "Germany", country.Name("DE", language.English))
+	assert.Equal(t, "Allemagne", country.Name("DE", language.French))
+	assert.Equal(t, "Deutschland", country.Name("de", language.German))
+	assert.Equal(t, "Côte d'Ivoire", country.Name("CI", language.BritishEnglish))
+	assert.Equal(t, "Germany", country.Name("DE", language.Make("tlh")))
+	assert.Equal(t, "XX", country.Name("XX", language.French))
+}
+
+func TestLanguage(t *testing.T) {
+	assert.Equal(t, language.English, country.Language())
+	assert.Equal(t, language.English, country.Language("", "not a tag"))
+	assert.Equal(t, language.German, country.Language("de"))
+	assert.Equal(t, language.Spanish, country.Language("", "tlh, es;q=0.8"))
+	assert.Equal(t, language.Japanese, country.Language("ja", "fr"))
+}
@@ -0,0 +1,253 @@
+// Package envelope encrypts short values such as notes with envelope
+// encryption: every value is sealed with its own random data key under
+// AES-256-GCM, and the data key is sealed with a key-encryption key (KEK)
+// from a Keyring. Sealed values are text:
+//
+//	enc:v1:<KEK id>:<sealed data key>:<sealed value>
+//
+// with both parts in unpadded base64url and their nonces in front. Any
+// plaintext can look like that, so whether a value is sealed has to be
+// stored along with it rather than guessed from the value.
+package envelope
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	prefix  = "enc:v1:"
+	keySize = 32
+)
+
+var (
+	ErrUnknownKey = errors.New("unknown key-encryption key")
+	ErrMalformed  = errors.New("malformed sealed value")
+	ErrKeyfile    = errors.New("invalid keyfile")
+)
+
+// Keyring holds the KEKs. The active one seals new values; the others only
+// open values sealed before a rotation. A nil Keyring leaves values in
+// plaintext.
+type Keyring struct {
+	keys   map[string]cipher.AEAD
+	active string
+}
+
+// LoadKeyfile reads a keyring from the file at path; see ParseKeyfile.
+func LoadKeyfile(path string) (*Keyring, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	k, err := ParseKeyfile(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return k, nil
+}
+
+// ParseKeyfile reads a keyring with one KEK per line, as an ID followed by
+// 32 random bytes in base64. Blank lines and lines starting with # are
+// ignored. The last key is the active one, so a key is rotated by appending
+// a new one.
+func ParseKeyfile(r io.Reader) (*Keyring, error) {
+	k := &Keyring{keys: map[string]cipher.AEAD{}}
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%w: line %d: want an ID and a key", ErrKeyfile, n)
+		}
+		id := fields[0]
+		if err := checkKeyID(id); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrKeyfile, n, err)
+		}
+		if _, ok := k.keys[id]; ok {
+			return nil, fmt.Errorf("%w: line %d: duplicate key %q", ErrKeyfile, n, id)
+		}
+		key, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil || len(key) != keySize {
+			return nil, fmt.Errorf("%w: line %d: key %q must be %d bytes in base64", ErrKeyfile, n, id, keySize)
+		}
+		if k.keys[id], err = newGCM(key); err != nil {
+			return nil, err
+		}
+		k.active = id
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if k.active == "" {
+		return nil, fmt.Errorf("%w: no keys", ErrKeyfile)
+	}
+	return k, nil
+}
+
+// GenerateKey returns a keyfile line with a new random key named id.
+func GenerateKey(id string) (string, error) {
+	if err := checkKeyID(id); err != nil {
+		return "", err
+	}
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return id + " " + base64.StdEncoding.EncodeToString(key), nil
+}
+
+// checkKeyID accepts IDs of ASCII letters, digits, '.', '-' and '_', which
+// cannot be confused with the separators of sealed values.
+func checkKeyID(id string) error {
+	if id == "" {
+		return errors.New("empty key ID")
+	}
+	for _, r := range id {
+		if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || strings.ContainsRune(".-_", r)) {
+			return fmt.Errorf("invalid key ID %q", id)
+		}
+	}
+	return nil
+}
+
+// ActiveKey is the ID of the KEK that seals new values.
+func (k *Keyring) ActiveKey() string {
+	if k == nil {
+		return ""
+	}
+	return k.active
+}
+
+// Seal encrypts plaintext under a new data key sealed with the active KEK,
+// reporting whether it did. Empty values are left alone, as are all values
+// without a Keyring.
+func (k *Keyring) Seal(plaintext string) (string, bool, error) {
+	if k == nil || plaintext == "" {
+		return plaintext, false, nil
+	}
+	dek := make([]byte, keySize)
+	if _, err := rand.Read(dek); err != nil {
+		return "", false, err
+	}
+	data, err := newGCM(dek)
+	if err != nil {
+		return "", false, err
+	}
+	// Both parts are bound to the KEK ID so that it cannot be swapped.
+	aad := []byte(prefix + k.active)
+	sealedDEK, err := seal(k.keys[k.active], dek, aad)
+	if err != nil {
+		return "", false, err
+	}
+	sealedValue, err := seal(data, []byte(plaintext), aad)
+	if err != nil {
+		return "", false, err
+	}
+	enc := base64.RawURLEncoding
+	return prefix + k.active + ":" + enc.EncodeToString(sealedDEK) + ":" + enc.EncodeToString(sealedValue), true, nil
+}
+
+// Open decrypts a value sealed by Seal; anything else is ErrMalformed, so it
+// must only be given values recorded as sealed. A nil Keyring opens nothing
+// and returns value as it is.
+func (k *Keyring) Open(value string) (string, error) {
+	if k == nil {
+		return value, nil
+	}
+	rest, ok := strings.CutPrefix(value, prefix)
+	if !ok {
+		return "", ErrMalformed
+	}
+	parts := strings.Split(rest, ":")
+	if len(parts) != 3 {
+		return "", ErrMalformed
+	}
+	id := parts[0]
+	kek := k.keys[id]
+	if kek == nil {
+		return "", fmt.Errorf("%w %q", ErrUnknownKey, id)
+	}
+	enc := base64.RawURLEncoding
+	sealedDEK, err := enc.DecodeString(parts[1])
+	if err != nil {
+		return "", ErrMalformed
+	}
+	sealedValue, err := enc.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	aad := []byte(prefix + id)
+	dek, err := open(kek, sealedDEK, aad)
+	if err != nil {
+		return "", err
+	}
+	data, err := newGCM(dek)
+	if err != nil {
+		return "", ErrMalformed
+	}
+	plaintext, err := open(data, sealedValue, aad)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// Rotate seals value again under the active KEK unless it already is,
+// reporting whether it changed. Plaintext values, which sealed says value
+// is not, get sealed.
+func (k *Keyring) Rotate(value string, sealed bool) (string, bool, error) {
+	if k == nil || value == "" || sealed && strings.HasPrefix(value, prefix+k.active+":") {
+		return value, false, nil
+	}
+	plaintext := value
+	if sealed {
+		var err error
+		if plaintext, err = k.Open(value); err != nil {
+			return "", false, err
+		}
+	}
+	return k.Seal(plaintext)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext with a random nonce, which it puts in front.
+func seal(aead cipher.AEAD, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(aead cipher.AEAD, sealed, aad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return plaintext, nil
+}
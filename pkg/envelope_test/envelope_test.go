@@ -0,0 +1,173 @@
+package envelope_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-test-assesment/pkg/envelope"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func keyring(t *testing.T, ids ...string) *envelope.Keyring {
+	t.Helper()
+	var lines []string
+	for _, id := range ids {
+		line, err := envelope.GenerateKey(id)
+		require.NoError(t, err)
+		lines = append(lines, line)
+	}
+	k, err := envelope.ParseKeyfile(strings.NewReader(strings.Join(lines, "\n")))
+	require.NoError(t, err)
+	return k
+}
+
+func TestKeyring_SealOpen(t *testing.T) {
+	k := keyring(t, "2024-01")
+
+	sealed, ok, err := k.Seal("meets the courier at dawn")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, strings.HasPrefix(sealed, "enc:v1:2024-01:"), sealed)
+	assert.NotContains(t, sealed, "courier")
+
+	again, _, err := k.Seal("meets the courier at dawn")
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again, "every value gets its own data key and nonce")
+
+	opened, err := k.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "meets the courier at dawn", opened)
+}
+
+func TestKeyring_Plaintext(t *testing.T) {
+	k := keyring(t, "a")
+
+	sealed, ok, err := k.Seal("")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "", sealed, "empty values stay empty")
+
+	var none *envelope.Keyring
+	sealed, ok, err = none.Seal("intel")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "intel", sealed)
+
+	// Plaintext that looks sealed is not opened without keys, and with keys
+	// it is only opened when recorded as sealed.
+	opened, err := none.Open("enc:v1:x:y:z")
+	require.NoError(t, err)
+	assert.Equal(t, "enc:v1:x:y:z", opened)
+
+	_, err = k.Open("written before encryption")
+	assert.ErrorIs(t, err, envelope.ErrMalformed)
+
+	rotated, changed, err := k.Rotate("enc:v1:x:y:z", false)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	opened, err = k.Open(rotated)
+	require.NoError(t, err)
+	assert.Equal(t, "enc:v1:x:y:z", opened)
+}
+
+func TestKeyring_Tampering(t *testing.T) {
+	k := keyring(t, "a", "b")
+	sealed, _, err := k.Seal("intel")
+	require.NoError(t, err)
+	parts := strings.Split(sealed, ":")
+
+	flip := func(s string) string {
+		c := 'A'
+		if s[len(s)-1] == 'A' {
+			c = 'B'
+		}
+		return s[:len(s)-1] + string(c)
+	}
+	for name, value := range map[string]string{
+		"value":          strings.Join(append(parts[:4:4], flip(parts[4])), ":"),
+		"data key":       strings.Join([]string{parts[0], parts[1], parts[2], flip(parts[3]), parts[4]}, ":"),
+		"swapped key ID": strings.Join([]string{parts[0], parts[1], "a", parts[3], parts[4]}, ":"),
+		"missing part":   strings.Join(parts[:4], ":"),
+		"not base64":     strings.Join([]string{parts[0], parts[1], parts[2], "!!", parts[4]}, ":"),
+	} {
+		_, err := k.Open(value)
+		assert.ErrorIs(t, err, envelope.ErrMalformed, name)
+	}
+
+	_, err = keyring(t, "c").Open(sealed)
+	assert.ErrorIs(t, err, envelope.ErrUnknownKey)
+}
+
+func TestKeyring_Rotate(t *testing.T) {
+	oldLine, err := envelope.GenerateKey("old")
+	require.NoError(t, err)
+	newLine, err := envelope.GenerateKey("new")
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "notes.keys")
+
+	require.NoError(t, os.WriteFile(path, []byte(oldLine+"\n"), 0o600))
+	old, err := envelope.LoadKeyfile(path)
+	require.NoError(t, err)
+	sealed, _, err := old.Seal("intel")
+	require.NoError(t, err)
+
+	// A key is rotated by appending the new one to the keyfile.
+	require.NoError(t, os.WriteFile(path, []byte("# notes keys\n"+oldLine+"\n\n"+newLine+"\n"), 0o600))
+	k, err := envelope.LoadKeyfile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", k.ActiveKey())
+
+	opened, err := k.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "intel", opened, "values sealed with the old key still open")
+
+	rotated, changed, err := k.Rotate(sealed, true)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.True(t, strings.HasPrefix(rotated, "enc:v1:new:"), rotated)
+	opened, err = k.Open(rotated)
+	require.NoError(t, err)
+	assert.Equal(t, "intel", opened)
+
+	_, changed, err = k.Rotate(rotated, true)
+	require.NoError(t, err)
+	assert.False(t, changed, "already sealed with the active key")
+
+	plain, changed, err := k.Rotate("written before encryption", false)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	opened, err = k.Open(plain)
+	require.NoError(t, err)
+	assert.Equal(t, "written before encryption", opened)
+
+	_, changed, err = k.Rotate("", false)
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestParseKeyfile_Errors(t *testing.T) {
+	line, err := envelope.GenerateKey("a")
+	require.NoError(t, err)
+
+	for name, content := range map[string]string{
+		"empty":        "# no keys yet\n",
+		"duplicate ID": line + "\n" + line,
+		"short key":    "a c2hvcnQ=",
+		"not base64":   "a !!!",
+		"missing key":  "a",
+		"extra field":  line + " extra",
+		"invalid ID":   "a:b " + strings.Fields(line)[1],
+	} {
+		_, err := envelope.ParseKeyfile(strings.NewReader(content))
+		assert.ErrorIs(t, err, envelope.ErrKeyfile, name)
+	}
+
+	_, err = envelope.GenerateKey("no spaces")
+	assert.Error(t, err)
+	_, err = envelope.LoadKeyfile(filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,144 @@
+// Package export streams records as CSV, JSON Lines or a JSON array without
+// holding them in memory.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+type Format string
+
+const (
+	CSV   Format = "csv"
+	JSONL Format = "jsonl"
+	JSON  Format = "json"
+)
+
+var ErrUnknownFormat = errors.New("format must be one of csv, jsonl or json")
+
+// ParseFormat parses a format name, defaulting to CSV when s is empty.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case "":
+		return CSV, nil
+	case CSV, JSONL, JSON:
+		return f, nil
+	default:
+		return "", ErrUnknownFormat
+	}
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case JSONL:
+		return "application/x-ndjson"
+	case JSON:
+		return "application/json"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// Column is a CSV column of records of type T.
+type Column[T any] struct {
+	Name  string
+	Value func(T) string
+}
+
+// Encoder writes records one at a time. JSON formats encode each record
+// with encoding/json; CSV writes a header row followed by one row of
+// columns per record.
+type Encoder[T any] struct {
+	format  Format
+	w       io.Writer
+	csv     *csv.Writer
+	json    *json.Encoder
+	columns []Column[T]
+	count   int
+}
+
+func NewEncoder[T any](w io.Writer, format Format, columns []Column[T]) *Encoder[T] {
+	e := &Encoder[T]{format: format, w: w, columns: columns}
+	if format == CSV {
+		e.csv = csv.NewWriter(w)
+	} else {
+		e.json = json.NewEncoder(w)
+	}
+	return e
+}
+
+// Count reports how many records have been encoded.
+func (e *Encoder[T]) Count() int {
+	return e.count
+}
+
+func (e *Encoder[T]) Encode(record T) error {
+	var err error
+	switch e.format {
+	case CSV:
+		err = e.encodeCSV(record)
+	case JSON:
+		sep := ","
+		if e.count == 0 {
+			sep = "["
+		}
+		if _, err = io.WriteString(e.w, sep); err == nil {
+			err = e.json.Encode(record)
+		}
+	default:
+		err = e.json.Encode(record)
+	}
+	if err != nil {
+		return err
+	}
+	e.count++
+	return nil
+}
+
+func (e *Encoder[T]) encodeCSV(record T) error {
+	if e.count == 0 {
+		if err := e.csv.Write(e.header()); err != nil {
+			return err
+		}
+	}
+	row := make([]string, len(e.columns))
+	for i, c := range e.columns {
+		row[i] = c.Value(record)
+	}
+	return e.csv.Write(row)
+}
+
+func (e *Encoder[T]) header() []string {
+	names := make([]string, len(e.columns))
+	for i, c := range e.columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// Close finishes the output. It must be called after the last record, also
+// when there were none, so that empty exports are still well formed.
+func (e *Encoder[T]) Close() error {
+	switch e.format {
+	case CSV:
+		if e.count == 0 {
+			if err := e.csv.Write(e.header()); err != nil {
+				return err
+			}
+		}
+		e.csv.Flush()
+		return e.csv.Error()
+	case JSON:
+		end := "]\n"
+		if e.count == 0 {
+			end = "[]\n"
+		}
+		_, err := io.WriteString(e.w, end)
+		return err
+	default:
+		return nil
+	}
+}
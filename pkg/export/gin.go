@@ -0,0 +1,42 @@
+package export
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Serve streams records to the client as an attachment named name, in the
+// format given by the format query parameter. each must call its argument
+// once per record and stop at the first error it returns.
+//
+// Errors that happen before the first bytes reach the client, including
+// ErrUnknownFormat, are returned with nothing written so that the caller
+// can answer them. Past that point the status is already sent, so the error
+// is only recorded and the download ends early: a truncated JSON array does
+// not parse, but a truncated CSV or JSON Lines file only lacks its last rows.
+func Serve[T any](c *gin.Context, name string, columns []Column[T], each func(func(T) error) error) error {
+	format, err := ParseFormat(c.Query("format"))
+	if err != nil {
+		return err
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", format.ContentType())
+	header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+
+	enc := NewEncoder(c.Writer, format, columns)
+	err = each(enc.Encode)
+	if err == nil {
+		err = enc.Close()
+	}
+	if err != nil && !c.Writer.Written() {
+		header.Del("Content-Type")
+		header.Del("Content-Disposition")
+		return err
+	}
+	if err != nil {
+		c.Error(err)
+	}
+	return nil
+}
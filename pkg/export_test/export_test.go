@@ -0,0 +1,116 @@
+package export_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"go-test-assesment/pkg/export"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+var columns = []export.Column[item]{
+	{Name: "id", Value: func(i item) string { return strconv.Itoa(i.ID) }},
+	{Name: "name", Value: func(i item) string { return i.Name }},
+}
+
+func encode(t *testing.T, format export.Format, items []item) string {
+	t.Helper()
+	var buf bytes.Buffer
+	enc := export.NewEncoder(&buf, format, columns)
+	for _, i := range items {
+		require.NoError(t, enc.Encode(i))
+	}
+	require.NoError(t, enc.Close())
+	assert.Equal(t, len(items), enc.Count())
+	return buf.String()
+}
+
+func TestEncoder(t *testing.T) {
+	items := []item{{1, "Tom"}, {2, "a,b"}}
+	tests := []struct {
+		format    export.Format
+		want      string
+		wantEmpty string
+	}{
+		{export.CSV, "id,name\n1,Tom\n2,\"a,b\"\n", "id,name\n"},
+		{export.JSONL, "{\"id\":1,\"name\":\"Tom\"}\n{\"id\":2,\"name\":\"a,b\"}\n", ""},
+		{export.JSON, "[{\"id\":1,\"name\":\"Tom\"}\n,{\"id\":2,\"name\":\"a,b\"}\n]\n", "[]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			assert.Equal(t, tt.want, encode(t, tt.format, items))
+			assert.Equal(t, tt.wantEmpty, encode(t, tt.format, nil))
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := export.ParseFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, export.CSV, f)
+
+	f, err = export.ParseFormat("jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, export.JSONL, f)
+
+	_, err = export.ParseFormat("xml")
+	assert.ErrorIs(t, err, export.ErrUnknownFormat)
+}
+
+func serve(path string, each func(func(item) error) error) (*httptest.ResponseRecorder, *gin.Context, error) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, path, nil)
+	err := export.Serve(c, "items", columns, each)
+	return w, c, err
+}
+
+func TestServe(t *testing.T) {
+	w, _, err := serve("/?format=jsonl", func(write func(item) error) error {
+		return write(item{1, "Tom"})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="items.jsonl"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "{\"id\":1,\"name\":\"Tom\"}\n", w.Body.String())
+}
+
+func TestServe_ErrorBeforeWriting(t *testing.T) {
+	dbErr := errors.New("db error")
+	w, _, err := serve("/", func(func(item) error) error { return dbErr })
+	assert.ErrorIs(t, err, dbErr)
+	assert.Empty(t, w.Body.String())
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+
+	_, _, err = serve("/?format=xml", nil)
+	assert.ErrorIs(t, err, export.ErrUnknownFormat)
+}
+
+func TestServe_ErrorWhileStreaming(t *testing.T) {
+	dbErr := errors.New("connection lost")
+	w, c, err := serve("/?format=json", func(write func(item) error) error {
+		if err := write(item{1, "Tom"}); err != nil {
+			return err
+		}
+		return dbErr
+	})
+	assert.NoError(t, err, "the response has started, so the error is only recorded")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "[{\"id\":1,\"name\":\"Tom\"}\n", w.Body.String())
+	require.Len(t, c.Errors, 1)
+	assert.ErrorIs(t, c.Errors[0].Err, dbErr)
+}
@@ -0,0 +1,156 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	Header         = "Idempotency-Key"
+	ReplayedHeader = "Idempotent-Replayed"
+	maxKeyLength   = 255
+)
+
+// Record is a stored request. StatusCode is zero while the first request
+// with the key is still being handled.
+type Record struct {
+	Key         string
+	Fingerprint string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+type Store interface {
+	// Reserve claims key for a new request. It returns nil when the caller
+	// now owns the key, or the live record already stored under it.
+	Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, error)
+	Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte) error
+	// Release forgets key so that the request can be retried.
+	Release(ctx context.Context, key string) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// Middleware makes POST requests carrying an Idempotency-Key header safe to
+// retry: the first response is stored for ttl and replayed for repeats with
+// the same key. Reusing a key for a different request is rejected with 422,
+// and a repeat arriving while the first is still running gets 409. Server
+// errors are not stored, so those requests can be retried with the same key.
+func Middleware(store Store, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(Header)
+		if key == "" || c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+		if len(key) > maxKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "idempotency key is too long"})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+			c.Error(err)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		fingerprint := Fingerprint(c.Request, body)
+		existing, err := store.Reserve(ctx, key, fingerprint, ttl)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(err)
+			return
+		}
+		if existing != nil {
+			replay(c, existing, fingerprint)
+			return
+		}
+
+		w := &recorder{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		// The request context may already be cancelled if the client went away;
+		// the outcome must still be recorded.
+		ctx = context.WithoutCancel(ctx)
+		if status := w.Status(); status >= http.StatusInternalServerError {
+			err = store.Release(ctx, key)
+		} else {
+			err = store.Complete(ctx, key, status, w.Header().Get("Content-Type"), w.body.Bytes())
+		}
+		if err != nil {
+			log.Printf("[ERROR] idempotency: storing key %q: %v", key, err)
+		}
+	}
+}
+
+func replay(c *gin.Context, r *Record, fingerprint string) {
+	switch {
+	case r.Fingerprint != fingerprint:
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "idempotency key was already used for a different request"})
+	case r.StatusCode == 0:
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "a request with this idempotency key is still in progress"})
+	default:
+		c.Header(ReplayedHeader, "true")
+		if r.ContentType != "" {
+			c.Header("Content-Type", r.ContentType)
+		}
+		c.Status(r.StatusCode)
+		c.Writer.Write(r.Body)
+		c.Abort()
+	}
+}
+
+// Fingerprint identifies a request by method, path and body, so a key cannot
+// be reused across endpoints either.
+func Fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method)
+	h.Write([]byte{0})
+	io.WriteString(h, r.URL.RequestURI())
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Cleanup deletes expired keys every interval until ctx is done.
+func Cleanup(ctx context.Context, store Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := store.DeleteExpired(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("[ERROR] idempotency: cleanup: %v", err)
+			}
+		}
+	}
+}
+
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,83 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lockTimeout is how long an unfinished request holds its key, in case the
+// process handling it died before storing the response.
+const lockTimeout = time.Minute
+
+type PostgresStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{pool: pool}
+}
+
+func (s *PostgresStore) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, error) {
+	reserve := `
+		INSERT INTO idempotency_keys (key, fingerprint, created_at, expires_at)
+		VALUES ($1, $2, now(), now() + $3 * interval '1 millisecond')
+		ON CONFLICT (key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint, status_code = NULL, content_type = NULL,
+		    body = NULL, created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= now()
+		   OR (idempotency_keys.status_code IS NULL
+		       AND idempotency_keys.created_at <= now() - $4 * interval '1 millisecond')
+		RETURNING key`
+	lookup := `
+		SELECT fingerprint, COALESCE(status_code, 0), COALESCE(content_type, ''), body
+		FROM idempotency_keys WHERE key = $1`
+
+	// The stored row may expire or be released between the two statements,
+	// in which case the reservation is simply tried again.
+	for range 3 {
+		var reserved string
+		err := s.pool.QueryRow(ctx, reserve, key, fingerprint, ttl.Milliseconds(), lockTimeout.Milliseconds()).Scan(&reserved)
+		if err == nil {
+			return nil, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+
+		r := &Record{Key: key}
+		err = s.pool.QueryRow(ctx, lookup, key).Scan(&r.Fingerprint, &r.StatusCode, &r.ContentType, &r.Body)
+		if err == nil {
+			return r, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+	}
+	return nil, errors.New("idempotency key is contended")
+}
+
+func (s *PostgresStore) Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $1, content_type = $2, body = $3
+		WHERE key = $4`
+	_, err := s.pool.Exec(ctx, query, statusCode, contentType, body, key)
+	return err
+}
+
+func (s *PostgresStore) Release(ctx context.Context, key string) error {
+	_, err := s.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE key = $1`, key)
+	return err
+}
+
+func (s *PostgresStore) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := s.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= now()`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
@@ -0,0 +1,147 @@
+package idempotency_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-test-assesment/pkg/idempotency"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[string]*idempotency.Record
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{records: map[string]*idempotency.Record{}}
+}
+
+func (s *memoryStore) Reserve(_ context.Context, key, fingerprint string, _ time.Duration) (*idempotency.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.records[key]; ok {
+		stored := *r
+		return &stored, nil
+	}
+	s.records[key] = &idempotency.Record{Key: key, Fingerprint: fingerprint}
+	return nil, nil
+}
+
+func (s *memoryStore) Complete(_ context.Context, key string, status int, contentType string, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.records[key]
+	r.StatusCode, r.ContentType, r.Body = status, contentType, body
+	return nil
+}
+
+func (s *memoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+func (s *memoryStore) DeleteExpired(context.Context) (int64, error) { return 0, nil }
+
+func newRouter(store idempotency.Store, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(idempotency.Middleware(store, time.Hour))
+	r.POST("/cats", handler)
+	r.POST("/missions", handler)
+	return r
+}
+
+func post(r http.Handler, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(idempotency.Header, key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddleware_ReplaysResponse(t *testing.T) {
+	calls := 0
+	r := newRouter(newMemoryStore(), func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"id": calls})
+	})
+
+	first := post(r, "/cats", "abc", `{"name":"Tom"}`)
+	require.Equal(t, http.StatusCreated, first.Code)
+
+	second := post(r, "/cats", "abc", `{"name":"Tom"}`)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "true", second.Header().Get(idempotency.ReplayedHeader))
+	assert.Equal(t, "application/json; charset=utf-8", second.Header().Get("Content-Type"))
+	assert.Equal(t, 1, calls)
+
+	// Without a key every request is handled.
+	post(r, "/cats", "", `{"name":"Tom"}`)
+	post(r, "/cats", "", `{"name":"Tom"}`)
+	assert.Equal(t, 3, calls)
+}
+
+func TestMiddleware_RejectsMismatchedRequest(t *testing.T) {
+	r := newRouter(newMemoryStore(), func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	require.Equal(t, http.StatusCreated, post(r, "/cats", "abc", `{"name":"Tom"}`).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, post(r, "/cats", "abc", `{"name":"Jerry"}`).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, post(r, "/missions", "abc", `{"name":"Tom"}`).Code)
+}
+
+func TestMiddleware_InProgress(t *testing.T) {
+	store := newMemoryStore()
+	r := newRouter(store, func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/cats", strings.NewReader(`{}`))
+	_, err := store.Reserve(context.Background(), "abc", idempotency.Fingerprint(req, []byte(`{}`)), time.Hour)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusConflict, post(r, "/cats", "abc", `{}`).Code)
+}
+
+func TestMiddleware_ServerErrorIsNotStored(t *testing.T) {
+	calls := 0
+	r := newRouter(newMemoryStore(), func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "boom"})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	assert.Equal(t, http.StatusInternalServerError, post(r, "/cats", "abc", `{}`).Code)
+	assert.Equal(t, http.StatusCreated, post(r, "/cats", "abc", `{}`).Code)
+	assert.Equal(t, 2, calls)
+}
+
+func TestMiddleware_HandlerSeesBody(t *testing.T) {
+	r := newRouter(newMemoryStore(), func(c *gin.Context) {
+		var body struct{ Name string }
+		require.NoError(t, c.ShouldBindJSON(&body))
+		c.JSON(http.StatusCreated, gin.H{"name": body.Name})
+	})
+
+	w := post(r, "/cats", "abc", `{"name":"Tom"}`)
+	assert.JSONEq(t, `{"name":"Tom"}`, w.Body.String())
+}
@@ -0,0 +1,160 @@
+// Package money represents amounts of money exactly, as a whole number of
+// cents, matching the NUMERIC(10, 2) columns they are stored in.
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrInvalid   = errors.New("invalid amount")
+	ErrPrecision = errors.New("amount has more than two decimal places")
+	ErrRange     = errors.New("amount out of range")
+)
+
+// maxCents is the largest amount a NUMERIC(10, 2) column holds,
+// 99,999,999.99.
+const maxCents = 99_999_999_99
+
+// Amount is a signed number of cents. It encodes to JSON as a number with two
+// decimals and to Postgres as NUMERIC, so it never goes through a float.
+type Amount int64
+
+func FromCents(cents int64) Amount {
+	return Amount(cents)
+}
+
+func (a Amount) Cents() int64 {
+	return int64(a)
+}
+
+// Parse reads a decimal such as "1200.5" or "-3". More than two decimal
+// places are rejected rather than rounded, and amounts a NUMERIC(10, 2)
+// column cannot hold with ErrRange.
+func Parse(s string) (Amount, error) {
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	if whole == "" && (!hasFrac || frac == "") {
+		return 0, ErrInvalid
+	}
+	if !isDigits(whole) || !isDigits(frac) {
+		return 0, ErrInvalid
+	}
+	if len(frac) > 2 {
+		return 0, ErrPrecision
+	}
+
+	var units int64
+	if whole != "" {
+		var err error
+		units, err = strconv.ParseInt(whole, 10, 64)
+		if err != nil || units > maxCents/100 {
+			return 0, ErrRange
+		}
+	}
+	cents := units * 100
+	if frac != "" {
+		f, _ := strconv.ParseInt(frac, 10, 64)
+		if len(frac) == 1 {
+			f *= 10
+		}
+		cents += f
+	}
+	if neg {
+		cents = -cents
+	}
+	return Amount(cents), nil
+}
+
+func MustParse(s string) Amount {
+	a, err := Parse(s)
+	if err != nil {
+		panic(fmt.Sprintf("money: %q: %v", s, err))
+	}
+	return a
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (a Amount) String() string {
+	cents := int64(a)
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+	}
+	abs := new(big.Int).Abs(big.NewInt(cents))
+	q, r := new(big.Int).QuoRem(abs, big.NewInt(100), new(big.Int))
+	return fmt.Sprintf("%s%s.%02d", sign, q, r.Int64())
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a string holding one.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	s := string(data)
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	} else if strings.ContainsAny(s, "eE") {
+		return ErrInvalid
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+func (a *Amount) ScanNumeric(n pgtype.Numeric) error {
+	if !n.Valid {
+		*a = 0
+		return nil
+	}
+	if n.NaN || n.InfinityModifier != pgtype.Finite {
+		return ErrRange
+	}
+
+	// value = Int * 10^Exp, so cents = Int * 10^(Exp+2).
+	cents := new(big.Int).Set(n.Int)
+	if exp := int64(n.Exp) + 2; exp >= 0 {
+		cents.Mul(cents, new(big.Int).Exp(big.NewInt(10), big.NewInt(exp), nil))
+	} else {
+		div := new(big.Int).Exp(big.NewInt(10), big.NewInt(-exp), nil)
+		if new(big.Int).Rem(cents, div).Sign() != 0 {
+			return ErrPrecision
+		}
+		cents.Quo(cents, div)
+	}
+	if !cents.IsInt64() {
+		return ErrRange
+	}
+	*a = Amount(cents.Int64())
+	return nil
+}
+
+func (a Amount) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: big.NewInt(int64(a)), Exp: -2, Valid: true}, nil
+}
@@ -0,0 +1,82 @@
+package money_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"go-test-assesment/pkg/money"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr error
+	}{
+		{"1200.50", 120050, nil},
+		{"1200.5", 120050, nil},
+		{"1200", 120000, nil},
+		{".75", 75, nil},
+		{"-3.01", -301, nil},
+		{"0.1", 10, nil},
+		{"1.005", 0, money.ErrPrecision},
+		{"", 0, money.ErrInvalid},
+		{"1.2.3", 0, money.ErrInvalid},
+		{"abc", 0, money.ErrInvalid},
+		{"99999999.99", 9999999999, nil},
+		{"-99999999.99", -9999999999, nil},
+		{"100000000", 0, money.ErrRange},
+		{"-100000000.00", 0, money.ErrRange},
+		{"99999999999999999999", 0, money.ErrRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := money.Parse(tt.in)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.Cents())
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		A money.Amount `json:"a"`
+		B money.Amount `json:"b"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"a":0.1,"b":"1200.5"}`), &v))
+	assert.Equal(t, int64(10), v.A.Cents())
+	assert.Equal(t, int64(120050), v.B.Cents())
+
+	out, err := json.Marshal(v)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a":0.10,"b":1200.50}`, string(out))
+	assert.Equal(t, "-0.05", money.FromCents(-5).String())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"a":1e3}`), &v))
+	assert.Error(t, json.Unmarshal([]byte(`{"a":0.001}`), &v))
+}
+
+func TestNumeric(t *testing.T) {
+	var a money.Amount
+	require.NoError(t, a.ScanNumeric(pgtype.Numeric{Int: big.NewInt(12005), Exp: -1, Valid: true}))
+	assert.Equal(t, int64(120050), a.Cents())
+
+	require.NoError(t, a.ScanNumeric(pgtype.Numeric{Int: big.NewInt(12), Exp: 2, Valid: true}))
+	assert.Equal(t, int64(120000), a.Cents())
+
+	assert.ErrorIs(t, a.ScanNumeric(pgtype.Numeric{Int: big.NewInt(1), Exp: -3, Valid: true}), money.ErrPrecision)
+
+	n, err := money.FromCents(120050).NumericValue()
+	require.NoError(t, err)
+	assert.Equal(t, int64(120050), n.Int.Int64())
+	assert.Equal(t, int32(-2), n.Exp)
+}
@@ -0,0 +1,157 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader identifies a client independently of its address.
+const APIKeyHeader = "X-API-Key"
+
+const (
+	sweepInterval = time.Minute
+	// idleTimeout is how long a bucket is kept without requests, even if it
+	// has not refilled.
+	idleTimeout = 10 * time.Minute
+)
+
+// Limit allows Rate requests per second on average, in bursts of up to Burst.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// LimitedError is returned when a limiter rejects a call.
+type LimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LimitedError) Error() string {
+	return fmt.Sprintf("rate limit exceeded, retry after %v", e.RetryAfter)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter is a set of token buckets sharing one Limit, one per key.
+type Limiter struct {
+	limit Limit
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func New(limit Limit) *Limiter {
+	return NewWithClock(limit, time.Now)
+}
+
+func NewWithClock(limit Limit, now func() time.Time) *Limiter {
+	return &Limiter{
+		limit:     limit,
+		now:       now,
+		buckets:   make(map[string]*bucket),
+		lastSweep: now(),
+	}
+}
+
+// Allow takes a token from key's bucket. When none is left it reports how
+// long until one is.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) > sweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(float64(l.limit.Burst), b.tokens+now.Sub(b.last).Seconds()*l.limit.Rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	if l.limit.Rate <= 0 {
+		return false, time.Duration(math.MaxInt64)
+	}
+	return false, time.Duration((1 - b.tokens) / l.limit.Rate * float64(time.Second))
+}
+
+// sweep drops buckets that have refilled completely, since a new bucket
+// starts out full anyway, and buckets that have been idle for idleTimeout,
+// so that the map only holds recent clients.
+func (l *Limiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		idle := now.Sub(b.last)
+		if idle > idleTimeout || b.tokens+idle.Seconds()*l.limit.Rate >= float64(l.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// Config holds the per-client limit for every route, with overrides keyed by
+// method and route pattern, e.g. "POST /cats". ValidKey reports whether an
+// X-API-Key header belongs to a known client.
+type Config struct {
+	Default  Limit
+	Routes   map[string]Limit
+	ValidKey func(key string) bool
+}
+
+// Middleware rate limits each client separately on every route. Clients are
+// identified by their X-API-Key header when ValidKey accepts it, and by
+// address otherwise, so that made-up keys do not get fresh buckets.
+// Rejected requests get 429 with a Retry-After header.
+func Middleware(cfg Config) gin.HandlerFunc {
+	defaultLimiter := New(cfg.Default)
+	routeLimiters := make(map[string]*Limiter, len(cfg.Routes))
+	for route, limit := range cfg.Routes {
+		routeLimiters[route] = New(limit)
+	}
+
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+		limiter, ok := routeLimiters[route]
+		if !ok {
+			limiter = defaultLimiter
+		}
+
+		client := "ip:" + c.ClientIP()
+		if key := c.GetHeader(APIKeyHeader); key != "" && cfg.ValidKey != nil && cfg.ValidKey(key) {
+			client = "key:" + key
+		}
+
+		if ok, wait := limiter.Allow(client + " " + route); !ok {
+			Reject(c, wait)
+			return
+		}
+		c.Next()
+	}
+}
+
+// Reject aborts the request with 429 and a Retry-After in whole seconds.
+func Reject(c *gin.Context, wait time.Duration) {
+	c.Header("Retry-After", strconv.FormatInt(RetryAfterSeconds(wait), 10))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+}
+
+func RetryAfterSeconds(wait time.Duration) int64 {
+	return max(1, int64(math.Ceil(wait.Seconds())))
+}
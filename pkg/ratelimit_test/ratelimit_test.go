@@ -0,0 +1,99 @@
+package ratelimit_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-test-assesment/pkg/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func TestLimiter_Allow(t *testing.T) {
+	clk := &clock{t: time.Unix(0, 0)}
+	l := ratelimit.NewWithClock(ratelimit.Limit{Rate: 2, Burst: 3}, clk.now)
+
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow("a")
+		assert.True(t, ok, "burst request %d", i)
+	}
+	ok, wait := l.Allow("a")
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	// Buckets are independent per key.
+	ok, _ = l.Allow("b")
+	assert.True(t, ok)
+
+	clk.t = clk.t.Add(500 * time.Millisecond)
+	ok, _ = l.Allow("a")
+	assert.True(t, ok)
+	ok, _ = l.Allow("a")
+	assert.False(t, ok)
+
+	// Refill never exceeds the burst.
+	clk.t = clk.t.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow("a")
+		assert.True(t, ok)
+	}
+	ok, _ = l.Allow("a")
+	assert.False(t, ok)
+}
+
+func TestLimiter_EvictsIdleBuckets(t *testing.T) {
+	clk := &clock{t: time.Unix(0, 0)}
+	l := ratelimit.NewWithClock(ratelimit.Limit{Rate: 0, Burst: 1}, clk.now)
+
+	ok, _ := l.Allow("a")
+	assert.True(t, ok)
+	ok, _ = l.Allow("a")
+	assert.False(t, ok, "a bucket that never refills stays empty while in use")
+
+	clk.t = clk.t.Add(11 * time.Minute)
+	ok, _ = l.Allow("a")
+	assert.True(t, ok, "an idle bucket is dropped")
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ratelimit.Middleware(ratelimit.Config{
+		Default:  ratelimit.Limit{Rate: 0.1, Burst: 2},
+		Routes:   map[string]ratelimit.Limit{"POST /cats": {Rate: 0.1, Burst: 1}},
+		ValidKey: func(key string) bool { return key == "key-1" },
+	}))
+	r.GET("/cats", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/cats", func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	do := func(method, apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/cats", nil)
+		if apiKey != "" {
+			req.Header.Set(ratelimit.APIKeyHeader, apiKey)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusCreated, do(http.MethodPost, "").Code)
+	w := do(http.MethodPost, "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "10", w.Header().Get("Retry-After"))
+
+	// Other routes and other clients have their own budget.
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "").Code)
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, do(http.MethodGet, "").Code)
+	assert.Equal(t, http.StatusCreated, do(http.MethodPost, "key-1").Code)
+
+	// Unknown keys count against the address.
+	assert.Equal(t, http.StatusTooManyRequests, do(http.MethodPost, "made-up").Code)
+}